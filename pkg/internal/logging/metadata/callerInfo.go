@@ -6,8 +6,13 @@ SPDX-License-Identifier: Apache-2.0
 
 package metadata
 
+// DefaultCallerSkip is the number of stack frames skipped when capturing the caller of a log function: the
+// logger wrapper and the log function itself.
+const DefaultCallerSkip = 2
+
 func newCallerInfo() *callerInfo {
 	return &callerInfo{
+		skips: make(map[callerInfoKey]int),
 		info: map[callerInfoKey]bool{
 			{"", CRITICAL}: true,
 			{"", ERROR}:    true,
@@ -25,7 +30,8 @@ type callerInfoKey struct {
 
 // callerInfo maintains module-level based information to show or hide caller info.
 type callerInfo struct {
-	info map[callerInfoKey]bool
+	info  map[callerInfoKey]bool
+	skips map[callerInfoKey]int
 }
 
 // ShowCallerInfo enables caller info for given module and level.
@@ -33,6 +39,13 @@ func (l *callerInfo) ShowCallerInfo(module string, level Level) {
 	l.info[callerInfoKey{module, level}] = true
 }
 
+// ShowCallerInfoWithSkip enables caller info for given module and level, skipping the given number of stack
+// frames when capturing the caller.
+func (l *callerInfo) ShowCallerInfoWithSkip(module string, level Level, skip int) {
+	l.info[callerInfoKey{module, level}] = true
+	l.skips[callerInfoKey{module, level}] = skip
+}
+
 // HideCallerInfo disables caller info for given module and level.
 func (l *callerInfo) HideCallerInfo(module string, level Level) {
 	l.info[callerInfoKey{module, level}] = false
@@ -48,3 +61,54 @@ func (l *callerInfo) IsCallerInfoEnabled(module string, level Level) bool {
 
 	return show
 }
+
+// GetCallerSkip returns the number of stack frames to skip when capturing the caller for given module and level.
+func (l *callerInfo) GetCallerSkip(module string, level Level) int {
+	skip, exists := l.skips[callerInfoKey{module, level}]
+	if exists {
+		return skip
+	}
+
+	// If no skip setting exists for given module, then look for default
+	skip, exists = l.skips[callerInfoKey{"", level}]
+	if exists {
+		return skip
+	}
+
+	return DefaultCallerSkip
+}
+
+// Modules returns the names of the modules with caller info settings, not including the default module.
+func (l *callerInfo) Modules() []string {
+	var modules []string
+
+	for key := range l.info {
+		if key.module != defaultModuleName {
+			modules = append(modules, key.module)
+		}
+	}
+
+	for key := range l.skips {
+		if key.module != defaultModuleName {
+			modules = append(modules, key.module)
+		}
+	}
+
+	return modules
+}
+
+// GetAllCallerInfoSettings returns a copy of the caller info settings, by module and level. The default settings
+// are those of the default module.
+func (l *callerInfo) GetAllCallerInfoSettings() map[string]map[Level]bool {
+	settings := make(map[string]map[Level]bool)
+
+	for key, show := range l.info {
+		if settings[key.module] == nil {
+			settings[key.module] = make(map[Level]bool)
+		}
+
+		settings[key.module][key.level] = show
+	}
+
+	return settings
+}
@@ -51,3 +51,20 @@ func TestCallerInfoSetting(t *testing.T) {
 		require.True(t, ci.IsCallerInfoEnabled(moduleName, DEBUG), "Callerinfo supposed to be enabled for this level")
 	}
 }
+
+func TestCallerSkipSetting(t *testing.T) {
+	ci := newCallerInfo()
+	mod := "sample-module-name"
+
+	require.Equal(t, DefaultCallerSkip, ci.GetCallerSkip(mod, INFO))
+
+	ci.HideCallerInfo(mod, INFO)
+	ci.ShowCallerInfoWithSkip(mod, INFO, 4)
+	require.True(t, ci.IsCallerInfoEnabled(mod, INFO))
+	require.Equal(t, 4, ci.GetCallerSkip(mod, INFO))
+	require.Equal(t, DefaultCallerSkip, ci.GetCallerSkip(mod, DEBUG))
+
+	ci.ShowCallerInfoWithSkip("", DEBUG, 3)
+	require.Equal(t, 3, ci.GetCallerSkip(mod, DEBUG))
+	require.Equal(t, 4, ci.GetCallerSkip(mod, INFO))
+}
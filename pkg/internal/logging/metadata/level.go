@@ -6,6 +6,11 @@ SPDX-License-Identifier: Apache-2.0
 
 package metadata
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Level defines all available log levels for logging messages.
 type Level int
 
@@ -35,20 +40,35 @@ type moduleLevels struct {
 	levels map[string]Level
 }
 
-// GetLevel returns the log level for given module and level.
+// GetLevel returns the log level for given module and level. Modules with no log level set inherit the log level
+// of their closest parent module, eg. "edv/storage/postgres" falls back to "edv/storage" and then to "edv".
+// Module names are dot- or slash-separated.
 func (l *moduleLevels) GetLevel(module string) Level {
-	level, exists := l.levels[module]
-	if !exists {
-		level, exists = l.levels[defaultModuleName]
-		// no configuration exists, default to info
-		if !exists {
-			return defaultLogLevel
+	for m := module; m != defaultModuleName; m = parentModule(m) {
+		if level, exists := l.levels[m]; exists {
+			return level
 		}
 	}
 
+	level, exists := l.levels[defaultModuleName]
+	// no configuration exists, default to info
+	if !exists {
+		return defaultLogLevel
+	}
+
 	return level
 }
 
+// parentModule returns the name of the module's parent, or the default module name if it has none.
+func parentModule(module string) string {
+	i := strings.LastIndexAny(module, "./")
+	if i < 0 {
+		return defaultModuleName
+	}
+
+	return module[:i]
+}
+
 // GetAllLevels returns all set log levels.
 func (l *moduleLevels) GetAllLevels() map[string]Level {
 	levelsCopy := make(map[string]Level)
@@ -64,7 +84,67 @@ func (l *moduleLevels) SetLevel(module string, level Level) {
 	l.levels[module] = level
 }
 
+// SetAllLevels sets the log level for all modules with a log level set as well as the default log level.
+func (l *moduleLevels) SetAllLevels(level Level) {
+	for module := range l.levels {
+		l.levels[module] = level
+	}
+
+	l.levels[defaultModuleName] = level
+}
+
+// Reset removes all set log levels, including the default.
+func (l *moduleLevels) Reset() {
+	l.levels = make(map[string]Level)
+}
+
 // IsEnabledFor will return true if logging is enabled for given module and level.
 func (l *moduleLevels) IsEnabledFor(module string, level Level) bool {
-	return level <= l.GetLevel(module)
+	return l.GetLevel(module).IsAtLeast(level)
+}
+
+// String returns the name of the log level.
+func (l Level) String() string {
+	if l < CRITICAL || int(l) >= len(Levels) {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+
+	return Levels[l]
+}
+
+// IsAtLeast returns true if the log level is at least as verbose as the other one. Levels are ordered from
+// CRITICAL, the least verbose, to DEBUG, the most verbose.
+func (l Level) IsAtLeast(other Level) bool {
+	return l >= other
+}
+
+// IsAbove returns true if the log level is more verbose than the other one.
+func (l Level) IsAbove(other Level) bool {
+	return l > other
+}
+
+// IsBelow returns true if the log level is less verbose than the other one.
+func (l Level) IsBelow(other Level) bool {
+	return l < other
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	if l < CRITICAL || int(l) >= len(Levels) {
+		return nil, fmt.Errorf("logger: invalid log level: %d", int(l))
+	}
+
+	return []byte(Levels[l]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The level name is case-insensitive.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, text)
+	}
+
+	*l = level
+
+	return nil
 }
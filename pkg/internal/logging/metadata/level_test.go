@@ -6,6 +6,8 @@ SPDX-License-Identifier: Apache-2.0
 package metadata // nolint:testpackage // references internal implementation details
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -62,3 +64,106 @@ func TestLogLevels(t *testing.T) {
 	require.True(t, mlevel.IsEnabledFor("module-xyz-random-module", INFO))
 	require.False(t, mlevel.IsEnabledFor("module-xyz-random-module", DEBUG))
 }
+
+func TestSetAllLevels(t *testing.T) {
+	mlevel := newModuledLevels()
+
+	mlevel.SetLevel("module-xyz-info", INFO)
+	mlevel.SetLevel("module-xyz-error", ERROR)
+
+	mlevel.SetAllLevels(DEBUG)
+	require.Equal(t, DEBUG, mlevel.GetLevel("module-xyz-info"))
+	require.Equal(t, DEBUG, mlevel.GetLevel("module-xyz-error"))
+	require.Equal(t, DEBUG, mlevel.GetLevel("module-xyz-random-module"))
+
+	mlevel.Reset()
+	require.Empty(t, mlevel.GetAllLevels())
+	require.Equal(t, INFO, mlevel.GetLevel("module-xyz-info"))
+	require.Equal(t, INFO, mlevel.GetLevel("module-xyz-random-module"))
+}
+
+func TestLevelString(t *testing.T) {
+	require.Equal(t, "CRITICAL", CRITICAL.String())
+	require.Equal(t, "ERROR", ERROR.String())
+	require.Equal(t, "WARNING", WARNING.String())
+	require.Equal(t, "INFO", INFO.String())
+	require.Equal(t, "DEBUG", DEBUG.String())
+	require.Equal(t, "Level(10)", Level(10).String())
+	require.Equal(t, "level=DEBUG", fmt.Sprintf("level=%v", DEBUG))
+}
+
+func TestLevelComparison(t *testing.T) {
+	require.True(t, DEBUG.IsAtLeast(INFO))
+	require.True(t, INFO.IsAtLeast(INFO))
+	require.False(t, WARNING.IsAtLeast(INFO))
+
+	require.True(t, DEBUG.IsAbove(INFO))
+	require.False(t, INFO.IsAbove(INFO))
+	require.False(t, CRITICAL.IsAbove(ERROR))
+
+	require.True(t, CRITICAL.IsBelow(ERROR))
+	require.False(t, ERROR.IsBelow(ERROR))
+	require.False(t, DEBUG.IsBelow(WARNING))
+}
+
+func TestLevelText(t *testing.T) {
+	t.Run("roundtrip through JSON", func(t *testing.T) {
+		config := map[string]Level{"module1": DEBUG, "module2": WARNING}
+
+		raw, err := json.Marshal(config)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"module1":"DEBUG","module2":"WARNING"}`, string(raw))
+
+		result := make(map[string]Level)
+
+		err = json.Unmarshal(raw, &result)
+		require.NoError(t, err)
+		require.Equal(t, config, result)
+	})
+
+	t.Run("unmarshal is case-insensitive", func(t *testing.T) {
+		var level Level
+
+		require.NoError(t, level.UnmarshalText([]byte("warning")))
+		require.Equal(t, WARNING, level)
+	})
+
+	t.Run("error: unmarshal unknown level", func(t *testing.T) {
+		var level Level
+
+		err := level.UnmarshalText([]byte("verbose"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid log level: verbose")
+	})
+
+	t.Run("error: marshal unknown level", func(t *testing.T) {
+		_, err := Level(10).MarshalText()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid log level: 10")
+	})
+}
+
+func TestLevelInheritance(t *testing.T) {
+	mlevel := newModuledLevels()
+	mlevel.SetLevel("", WARNING)
+	mlevel.SetLevel("edv", ERROR)
+	mlevel.SetLevel("edv/storage", DEBUG)
+	mlevel.SetLevel("edge.core", CRITICAL)
+
+	require.Equal(t, DEBUG, mlevel.GetLevel("edv/storage/postgres"))
+	require.Equal(t, DEBUG, mlevel.GetLevel("edv/storage"))
+	require.Equal(t, ERROR, mlevel.GetLevel("edv/rest"))
+	require.Equal(t, ERROR, mlevel.GetLevel("edv.rest/handlers"))
+	require.Equal(t, CRITICAL, mlevel.GetLevel("edge.core/zcapld"))
+	require.Equal(t, WARNING, mlevel.GetLevel("edge"))
+	require.Equal(t, WARNING, mlevel.GetLevel("edvx/storage"))
+	require.True(t, mlevel.IsEnabledFor("edv/storage/postgres", DEBUG))
+	require.False(t, mlevel.IsEnabledFor("edv/rest", WARNING))
+
+	mlevel.SetLevel("edv/storage/postgres", INFO)
+	require.Equal(t, INFO, mlevel.GetLevel("edv/storage/postgres"))
+	require.Equal(t, DEBUG, mlevel.GetLevel("edv/storage/mysql"))
+
+	mlevel.Reset()
+	require.Equal(t, INFO, mlevel.GetLevel("edv/storage/postgres"))
+}
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package metadata
 
 import (
+	"sort"
 	"sync"
 )
 
@@ -15,8 +16,24 @@ var (
 	rwmutex     = &sync.RWMutex{}
 	levels      = newModuledLevels()
 	callerInfos = newCallerInfo()
+	sinks       = make(map[string]Sink)
+
+	initMutexOnce sync.Once
 )
 
+// InitWithMutex - replacing the mutex guarding the settings of all modules with the given one, so that race-condition
+// tests can hold it. For testing only. Only the first call with a non-nil mutex takes effect, and it must happen
+// before the package is used concurrently.
+func InitWithMutex(mu *sync.RWMutex) {
+	if mu == nil {
+		return
+	}
+
+	initMutexOnce.Do(func() {
+		rwmutex = mu
+	})
+}
+
 // SetLevel - setting log level for given module.
 func SetLevel(module string, level Level) {
 	rwmutex.Lock()
@@ -24,6 +41,38 @@ func SetLevel(module string, level Level) {
 	levels.SetLevel(module, level)
 }
 
+// SetAllLevels - setting log level for all modules, including modules with no log level set yet.
+func SetAllLevels(level Level) {
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+	levels.SetAllLevels(level)
+}
+
+// ResetAllLevels - removing all set log levels. All modules fall back to the default log level (INFO).
+func ResetAllLevels() {
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+	levels.Reset()
+}
+
+// TestingT is the part of testing.TB required by ResetGlobals.
+type TestingT interface {
+	Helper()
+}
+
+// ResetGlobals - reinitializing the log levels and caller info settings of all modules, as if none had been set.
+// For test isolation only, hence the TestingT parameter: it must not be used in production code. Tests calling it
+// must not run in parallel with tests depending on these settings.
+func ResetGlobals(t TestingT) {
+	t.Helper()
+
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+
+	levels = newModuledLevels()
+	callerInfos = newCallerInfo()
+}
+
 // GetLevel - getting log level for given module.
 func GetLevel(module string) Level {
 	rwmutex.RLock()
@@ -40,6 +89,62 @@ func GetAllLevels() map[string]Level {
 	return levels.GetAllLevels()
 }
 
+// GetAllCallerInfoSettings - getting all caller info settings, by module and level. The default settings are those
+// of the default module, ie. "".
+func GetAllCallerInfoSettings() map[string]map[Level]bool {
+	rwmutex.RLock()
+	defer rwmutex.RUnlock()
+
+	return callerInfos.GetAllCallerInfoSettings()
+}
+
+// ModuleSettings is a snapshot of the logging configuration of all modules.
+type ModuleSettings struct {
+	// Levels are the log levels, by module.
+	Levels map[string]Level `json:"levels"`
+	// CallerInfos are the caller info settings, by module and level.
+	CallerInfos map[string]map[Level]bool `json:"callerInfos"`
+}
+
+// GetAllSettings - getting all log levels and caller info settings at once.
+func GetAllSettings() ModuleSettings {
+	rwmutex.RLock()
+	defer rwmutex.RUnlock()
+
+	return ModuleSettings{
+		Levels:      levels.GetAllLevels(),
+		CallerInfos: callerInfos.GetAllCallerInfoSettings(),
+	}
+}
+
+// GetAllModules - getting the sorted names of all modules with a log level or caller info setting. The default
+// module, ie. "", is not included.
+func GetAllModules() []string {
+	rwmutex.RLock()
+	defer rwmutex.RUnlock()
+
+	unique := make(map[string]struct{})
+
+	for module := range levels.GetAllLevels() {
+		if module != defaultModuleName {
+			unique[module] = struct{}{}
+		}
+	}
+
+	for _, module := range callerInfos.Modules() {
+		unique[module] = struct{}{}
+	}
+
+	modules := make([]string, 0, len(unique))
+	for module := range unique {
+		modules = append(modules, module)
+	}
+
+	sort.Strings(modules)
+
+	return modules
+}
+
 // IsEnabledFor - Check if given log level is enabled for given module.
 func IsEnabledFor(module string, level Level) bool {
 	rwmutex.RLock()
@@ -55,6 +160,15 @@ func ShowCallerInfo(module string, level Level) {
 	callerInfos.ShowCallerInfo(module, level)
 }
 
+// ShowCallerInfoWithSkip - Show caller info in log lines for given log level and module, skipping the given number
+// of stack frames when capturing the caller. Libraries that wrap the logger in extra layers set a bigger skip than
+// DefaultCallerSkip.
+func ShowCallerInfoWithSkip(module string, level Level, skip int) {
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+	callerInfos.ShowCallerInfoWithSkip(module, level, skip)
+}
+
 // HideCallerInfo - Do not show caller info in log lines for given log level and module.
 func HideCallerInfo(module string, level Level) {
 	rwmutex.Lock()
@@ -69,3 +183,40 @@ func IsCallerInfoEnabled(module string, level Level) bool {
 
 	return callerInfos.IsCallerInfoEnabled(module, level)
 }
+
+// GetCallerSkip - returns the number of stack frames to skip when capturing the caller for given log level and
+// module.
+func GetCallerSkip(module string, level Level) int {
+	rwmutex.RLock()
+	defer rwmutex.RUnlock()
+
+	return callerInfos.GetCallerSkip(module, level)
+}
+
+// SetSink - setting the sink log entries of the given module are written to. The sink of the default module,
+// ie. "", is used for modules with no sink set. A nil sink removes the module's sink.
+func SetSink(module string, sink Sink) {
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+
+	if sink == nil {
+		delete(sinks, module)
+
+		return
+	}
+
+	sinks[module] = sink
+}
+
+// GetSink - getting the sink for given module, falling back to the sink of the default module.
+// Returns nil if neither is set.
+func GetSink(module string) Sink {
+	rwmutex.RLock()
+	defer rwmutex.RUnlock()
+
+	if sink, ok := sinks[module]; ok {
+		return sink
+	}
+
+	return sinks[defaultModuleName]
+}
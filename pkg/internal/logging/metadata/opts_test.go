@@ -7,9 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package metadata_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/rand"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -75,6 +79,122 @@ func TestGetAllLevels(t *testing.T) {
 	require.Equal(t, metadata.Level(2), allLogLevels[sampleModuleWarning])
 }
 
+func TestGetAllModules(t *testing.T) {
+	metadata.SetLevel("sample-module-b", metadata.WARNING)
+	metadata.SetLevel("sample-module-a", metadata.DEBUG)
+	metadata.ShowCallerInfo("sample-module-c", metadata.ERROR)
+	metadata.HideCallerInfo("sample-module-c", metadata.DEBUG)
+	metadata.ShowCallerInfoWithSkip("sample-module-a", metadata.INFO, 3)
+	metadata.SetLevel("", metadata.INFO)
+
+	defer metadata.ResetAllLevels()
+
+	modules := metadata.GetAllModules()
+	require.NotContains(t, modules, "")
+	require.Subset(t, modules, []string{"sample-module-a", "sample-module-b", "sample-module-c"})
+	require.True(t, sort.StringsAreSorted(modules))
+
+	unique := make(map[string]struct{})
+	for _, module := range modules {
+		unique[module] = struct{}{}
+	}
+
+	require.Len(t, unique, len(modules))
+}
+
+func TestGetAllCallerInfoSettings(t *testing.T) {
+	defer metadata.ResetGlobals(t)
+
+	metadata.ShowCallerInfo("sample-module-callers", metadata.ERROR)
+	metadata.HideCallerInfo("sample-module-callers", metadata.DEBUG)
+
+	settings := metadata.GetAllCallerInfoSettings()
+	require.Equal(t, map[metadata.Level]bool{metadata.ERROR: true, metadata.DEBUG: false},
+		settings["sample-module-callers"])
+	require.True(t, settings[""][metadata.INFO])
+
+	settings["sample-module-callers"][metadata.DEBUG] = true
+	require.False(t, metadata.IsCallerInfoEnabled("sample-module-callers", metadata.DEBUG))
+}
+
+func TestGetAllSettings(t *testing.T) {
+	defer metadata.ResetGlobals(t)
+
+	metadata.SetLevel("sample-module-settings", metadata.WARNING)
+	metadata.HideCallerInfo("sample-module-settings", metadata.WARNING)
+
+	settings := metadata.GetAllSettings()
+	require.Equal(t, map[string]metadata.Level{"sample-module-settings": metadata.WARNING}, settings.Levels)
+	require.Equal(t, map[metadata.Level]bool{metadata.WARNING: false}, settings.CallerInfos["sample-module-settings"])
+
+	raw, err := json.Marshal(settings)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), `"levels":{"sample-module-settings":"WARNING"}`)
+	require.Contains(t, string(raw), `"sample-module-settings":{"WARNING":false}`)
+}
+
+func TestSetAllLevels(t *testing.T) {
+	module := "sample-module-set-all"
+	metadata.SetLevel(module, metadata.ERROR)
+
+	defer metadata.ResetAllLevels()
+
+	metadata.SetAllLevels(metadata.DEBUG)
+	require.Equal(t, metadata.DEBUG, metadata.GetLevel(module))
+	require.Equal(t, metadata.DEBUG, metadata.GetLevel("sample-module-not-set"))
+
+	metadata.ResetAllLevels()
+	require.Empty(t, metadata.GetAllLevels())
+	require.Equal(t, metadata.INFO, metadata.GetLevel(module))
+}
+
+func TestResetGlobals(t *testing.T) {
+	module := "sample-module-reset-globals"
+	metadata.SetLevel(module, metadata.DEBUG)
+	metadata.SetLevel("", metadata.ERROR)
+	metadata.HideCallerInfo(module, metadata.INFO)
+	metadata.ShowCallerInfoWithSkip(module, metadata.WARNING, 2)
+
+	metadata.ResetGlobals(t)
+
+	require.Empty(t, metadata.GetAllLevels())
+	require.Empty(t, metadata.GetAllModules())
+	require.Equal(t, metadata.INFO, metadata.GetLevel(module))
+	require.True(t, metadata.IsCallerInfoEnabled(module, metadata.INFO))
+	require.Equal(t, metadata.DefaultCallerSkip, metadata.GetCallerSkip(module, metadata.WARNING))
+}
+
+func TestInitWithMutex(t *testing.T) {
+	mu := &sync.RWMutex{}
+	metadata.InitWithMutex(nil)
+	metadata.InitWithMutex(mu)
+	metadata.InitWithMutex(&sync.RWMutex{})
+
+	module := "sample-module-mutex"
+
+	defer metadata.ResetAllLevels()
+
+	mu.Lock()
+
+	done := make(chan struct{})
+
+	go func() {
+		metadata.SetLevel(module, metadata.DEBUG)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("the level was set while the injected mutex was held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	mu.Unlock()
+	<-done
+
+	require.Equal(t, metadata.DEBUG, metadata.GetLevel(module))
+}
+
 func TestCallerInfos(t *testing.T) {
 	// nolint:gosec // use of weak random num generator is fine for these tests
 	module := fmt.Sprintf("sample-module-caller-info-%d-%d", rand.Intn(1000), rand.Intn(1000))
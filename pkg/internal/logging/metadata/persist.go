@@ -0,0 +1,79 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// SaveLevels - writing all set log levels to the file at the given path as a JSON object mapping module names to
+// level names, eg. {"":"INFO","module1":"DEBUG"}. The file is written to a temporary file first and then renamed,
+// so it is never left partially written.
+func SaveLevels(path string) error {
+	raw, err := json.Marshal(GetAllLevels())
+	if err != nil {
+		return fmt.Errorf("failed to marshal log levels: %w", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary log levels file: %w", err)
+	}
+
+	// remove the temporary file if it could not be renamed
+	defer os.Remove(tmp.Name()) // nolint:errcheck // the file no longer exists once renamed
+
+	_, err = tmp.Write(raw)
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write log levels file: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return fmt.Errorf("failed to rename log levels file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadLevels - setting the log levels found in the file at the given path, as written by SaveLevels. All of the
+// levels are set at once, and none of them are set if the file is invalid. Call it at startup before any module
+// logs.
+func LoadLevels(path string) error {
+	raw, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to read log levels file: %w", err)
+	}
+
+	levelMap := make(map[string]Level)
+
+	err = json.Unmarshal(raw, &levelMap)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal log levels: %w", err)
+	}
+
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+
+	for module, level := range levelMap {
+		levels.SetLevel(module, level)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metadata_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/internal/logging/metadata"
+)
+
+func TestSaveAndLoadLevels(t *testing.T) {
+	t.Run("roundtrip", func(t *testing.T) {
+		path := filepath.Join(tempDir(t), "levels.json")
+
+		metadata.SetLevel("persist-module-debug", metadata.DEBUG)
+		metadata.SetLevel("persist-module-error", metadata.ERROR)
+		require.NoError(t, metadata.SaveLevels(path))
+
+		saved := make(map[string]string)
+		require.NoError(t, json.Unmarshal([]byte(readFile(t, path)), &saved))
+		require.Equal(t, "DEBUG", saved["persist-module-debug"])
+		require.Equal(t, "ERROR", saved["persist-module-error"])
+
+		metadata.SetLevel("persist-module-debug", metadata.INFO)
+		metadata.SetLevel("persist-module-error", metadata.INFO)
+		require.NoError(t, metadata.LoadLevels(path))
+		require.Equal(t, metadata.DEBUG, metadata.GetLevel("persist-module-debug"))
+		require.Equal(t, metadata.ERROR, metadata.GetLevel("persist-module-error"))
+	})
+
+	t.Run("overwrites the file without leaving temporary files", func(t *testing.T) {
+		dir := tempDir(t)
+		path := filepath.Join(dir, "levels.json")
+
+		require.NoError(t, metadata.SaveLevels(path))
+		require.NoError(t, metadata.SaveLevels(path))
+
+		files, err := ioutil.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+	})
+
+	t.Run("error: invalid levels are not loaded", func(t *testing.T) {
+		path := filepath.Join(tempDir(t), "levels.json")
+		require.NoError(t, ioutil.WriteFile(path,
+			[]byte(`{"persist-module-valid":"DEBUG","persist-module-invalid":"LOUD"}`), 0o600))
+
+		err := metadata.LoadLevels(path)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal log levels")
+		require.NotContains(t, metadata.GetAllLevels(), "persist-module-valid")
+	})
+
+	t.Run("error: missing file", func(t *testing.T) {
+		err := metadata.LoadLevels(filepath.Join(tempDir(t), "levels.json"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read log levels file")
+	})
+
+	t.Run("error: missing directory", func(t *testing.T) {
+		err := metadata.SaveLevels(filepath.Join(tempDir(t), "missing", "levels.json"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create temporary log levels file")
+	})
+}
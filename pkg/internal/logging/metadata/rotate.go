@@ -0,0 +1,169 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const logFileMode = 0o600
+
+// RotatingFileSink returns a Sink that appends log entries in FormatText to the file at 'path'. Once the file
+// exceeds maxSizeBytes it is rotated: renamed to path.1, with older backups shifted to path.2, path.3, etc.,
+// and a fresh file is opened. Backups beyond maxBackups are deleted. The returned Sink implements io.Closer.
+func RotatingFileSink(path string, maxSizeBytes int64, maxBackups int) (Sink, error) {
+	if maxSizeBytes <= 0 {
+		return nil, errors.New("max size must be greater than zero")
+	}
+
+	if maxBackups < 0 {
+		return nil, errors.New("max backups must not be negative")
+	}
+
+	s := &rotatingFileSink{path: path, maxSize: maxSizeBytes, maxBackups: maxBackups}
+
+	err := s.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+type rotatingFileSink struct {
+	mutex      sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func (s *rotatingFileSink) Write(module string, level Level, msg string, fields map[string]interface{}) error {
+	line := textEntry(module, level, msg, fields)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		return errors.New("failed to write log entry: sink is closed")
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+
+	if err != nil {
+		return fmt.Errorf("failed to write log entry: %w", err)
+	}
+
+	if s.size > s.maxSize {
+		return s.rotate()
+	}
+
+	return nil
+}
+
+// Close closes the current log file.
+func (s *rotatingFileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+
+	if err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *rotatingFileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, logFileMode)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close() // nolint:errcheck // the stat error is more relevant
+
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+
+	return nil
+}
+
+// rotate shifts the backups, moves the current file to the first backup, and opens a fresh file.
+func (s *rotatingFileSink) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+
+	if err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+
+	err = s.shiftBackups()
+	if err != nil {
+		return err
+	}
+
+	return s.open()
+}
+
+// shiftBackups deletes the oldest backup and moves the current file and the other backups up by one.
+func (s *rotatingFileSink) shiftBackups() error {
+	if s.maxBackups == 0 {
+		return removeIfExists(s.path)
+	}
+
+	err := removeIfExists(s.backup(s.maxBackups))
+	if err != nil {
+		return err
+	}
+
+	for i := s.maxBackups - 1; i > 0; i-- {
+		err = renameIfExists(s.backup(i), s.backup(i+1))
+		if err != nil {
+			return err
+		}
+	}
+
+	return renameIfExists(s.path, s.backup(1))
+}
+
+func (s *rotatingFileSink) backup(i int) string {
+	return fmt.Sprintf("%s.%d", s.path, i)
+}
+
+func removeIfExists(path string) error {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove log file: %w", err)
+	}
+
+	return nil
+}
+
+func renameIfExists(from, to string) error {
+	err := os.Rename(from, to)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	return nil
+}
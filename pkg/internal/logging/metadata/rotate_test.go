@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metadata_test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/internal/logging/metadata"
+)
+
+func TestRotatingFileSink(t *testing.T) {
+	const entry = "[module] INFO 0123456789\n" // 25 bytes
+
+	t.Run("rotates and deletes the oldest backups", func(t *testing.T) {
+		path := filepath.Join(tempDir(t), "test.log")
+
+		sink, err := metadata.RotatingFileSink(path, 30, 2)
+		require.NoError(t, err)
+
+		defer closeSink(t, sink)
+
+		for i := 0; i < 4; i++ {
+			require.NoError(t, sink.Write("module", metadata.INFO, "0123456789", nil))
+		}
+
+		// every second entry exceeds the max size: 2 rotations leave an empty file and 2 full backups
+		require.Equal(t, "", readFile(t, path))
+		require.Equal(t, entry+entry, readFile(t, path+".1"))
+		require.Equal(t, entry+entry, readFile(t, path+".2"))
+
+		for i := 0; i < 2; i++ {
+			require.NoError(t, sink.Write("module", metadata.INFO, "0123456789", nil))
+		}
+
+		require.Equal(t, entry+entry, readFile(t, path+".2"))
+		require.NoFileExists(t, path+".3")
+	})
+
+	t.Run("appends to an existing file", func(t *testing.T) {
+		path := filepath.Join(tempDir(t), "test.log")
+		require.NoError(t, ioutil.WriteFile(path, []byte(entry), 0o600))
+
+		sink, err := metadata.RotatingFileSink(path, 30, 1)
+		require.NoError(t, err)
+
+		defer closeSink(t, sink)
+
+		require.NoError(t, sink.Write("module", metadata.INFO, "0123456789", nil))
+		require.Equal(t, "", readFile(t, path))
+		require.Equal(t, entry+entry, readFile(t, path+".1"))
+	})
+
+	t.Run("truncates without backups", func(t *testing.T) {
+		path := filepath.Join(tempDir(t), "test.log")
+
+		sink, err := metadata.RotatingFileSink(path, 10, 0)
+		require.NoError(t, err)
+
+		defer closeSink(t, sink)
+
+		require.NoError(t, sink.Write("module", metadata.INFO, "0123456789", nil))
+		require.Equal(t, "", readFile(t, path))
+		require.NoFileExists(t, path+".1")
+	})
+
+	t.Run("error: invalid arguments", func(t *testing.T) {
+		_, err := metadata.RotatingFileSink("test.log", 0, 1)
+		require.EqualError(t, err, "max size must be greater than zero")
+
+		_, err = metadata.RotatingFileSink("test.log", 1, -1)
+		require.EqualError(t, err, "max backups must not be negative")
+	})
+
+	t.Run("error: cannot open file", func(t *testing.T) {
+		_, err := metadata.RotatingFileSink(filepath.Join(tempDir(t), "missing", "test.log"), 1, 1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open log file")
+	})
+
+	t.Run("error: write after close", func(t *testing.T) {
+		sink, err := metadata.RotatingFileSink(filepath.Join(tempDir(t), "test.log"), 1, 1)
+		require.NoError(t, err)
+		closeSink(t, sink)
+
+		err = sink.Write("module", metadata.INFO, "msg", nil)
+		require.EqualError(t, err, "failed to write log entry: sink is closed")
+	})
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+
+	return dir
+}
+
+func readFile(t *testing.T, path string) string {
+	b, err := ioutil.ReadFile(path) // nolint:gosec // test file
+	require.NoError(t, err)
+
+	return string(b)
+}
+
+func closeSink(t *testing.T, sink metadata.Sink) {
+	closer, ok := sink.(io.Closer)
+	require.True(t, ok)
+	require.NoError(t, closer.Close())
+}
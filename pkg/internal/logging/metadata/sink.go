@@ -0,0 +1,197 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxSampledKeys bounds the sampling state kept by SamplingSink.
+const maxSampledKeys = 4096
+
+// Formats supported by WriterSink.
+const (
+	// FormatText writes entries as "[module] LEVEL msg key=value ...", with fields sorted by key.
+	FormatText = "text"
+	// FormatJSON writes entries as JSON objects, one per line.
+	FormatJSON = "json"
+)
+
+// Sink is a destination for log entries.
+type Sink interface {
+	Write(module string, level Level, msg string, fields map[string]interface{}) error
+}
+
+// DiscardSink is a Sink that discards all log entries.
+type DiscardSink struct{}
+
+// Write does nothing.
+func (DiscardSink) Write(string, Level, string, map[string]interface{}) error {
+	return nil
+}
+
+// WriterSink returns a Sink that writes log entries to 'w' in the given format, one entry per line.
+// Entries are written in FormatText if the format is not supported.
+func WriterSink(w io.Writer, format string) Sink {
+	return &writerSink{w: w, json: format == FormatJSON}
+}
+
+type writerSink struct {
+	mutex sync.Mutex
+	w     io.Writer
+	json  bool
+}
+
+func (s *writerSink) Write(module string, level Level, msg string, fields map[string]interface{}) error {
+	var (
+		line []byte
+		err  error
+	)
+
+	if s.json {
+		line, err = jsonEntry(module, level, msg, fields)
+		if err != nil {
+			return err
+		}
+	} else {
+		line = textEntry(module, level, msg, fields)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.w.Write(line)
+	if err != nil {
+		return fmt.Errorf("failed to write log entry: %w", err)
+	}
+
+	return nil
+}
+
+func textEntry(module string, level Level, msg string, fields map[string]interface{}) []byte {
+	keys := make([]string, 0, len(fields))
+
+	for k := range fields {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "[%s] %s %s", module, level, msg)
+
+	for _, k := range keys {
+		fmt.Fprintf(b, " %s=%v", k, fields[k])
+	}
+
+	b.WriteString("\n")
+
+	return []byte(b.String())
+}
+
+// jsonEntry encodes the entry as a JSON object. The module, level, and msg keys take precedence over fields
+// of the same name.
+func jsonEntry(module string, level Level, msg string, fields map[string]interface{}) ([]byte, error) {
+	entry := make(map[string]interface{}, len(fields)+3) // nolint:gomnd // module, level, and msg
+
+	for k, v := range fields {
+		entry[k] = v
+	}
+
+	entry["module"] = module
+	entry["level"] = level.String()
+	entry["msg"] = msg
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal log entry: %w", err)
+	}
+
+	return append(line, '\n'), nil
+}
+
+// MultiSink returns a Sink that writes log entries to all of the sinks. All sinks are written to even if some
+// of them fail; the first error is returned.
+func MultiSink(sinks ...Sink) Sink {
+	return multiSink(append([]Sink{}, sinks...))
+}
+
+type multiSink []Sink
+
+func (m multiSink) Write(module string, level Level, msg string, fields map[string]interface{}) error {
+	var first error
+
+	for _, s := range m {
+		err := s.Write(module, level, msg, fields)
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// SamplingSink returns a Sink that passes approximately 'rate' (0.0 to 1.0) of the log entries below WARNING on to
+// the inner sink; entries of WARNING and above are always passed on. Each (module, message) pair is sampled with
+// its own pseudo-random sequence, seeded by the pair, so the decisions are reproducible and every message keeps
+// appearing at the sampled rate instead of some messages dominating the output of their module.
+func SamplingSink(inner Sink, rate float64) Sink {
+	return &samplingSink{inner: inner, rate: rate, keys: make(map[string]*rand.Rand)}
+}
+
+type samplingSink struct {
+	mutex sync.Mutex
+	inner Sink
+	rate  float64
+	keys  map[string]*rand.Rand
+}
+
+func (s *samplingSink) Write(module string, level Level, msg string, fields map[string]interface{}) error {
+	if level.IsAbove(WARNING) && !s.sample(module, msg) {
+		return nil
+	}
+
+	return s.inner.Write(module, level, msg, fields)
+}
+
+func (s *samplingSink) sample(module, msg string) bool {
+	if s.rate >= 1 {
+		return true
+	}
+
+	if s.rate <= 0 {
+		return false
+	}
+
+	key := module + "\x00" + msg
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	r, ok := s.keys[key]
+	if !ok {
+		if len(s.keys) >= maxSampledKeys {
+			s.keys = make(map[string]*rand.Rand)
+		}
+
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key)) // nolint:errcheck // hash writes never fail
+
+		r = rand.New(rand.NewSource(int64(h.Sum64()))) // nolint:gosec // sampling needs no secure randomness
+		s.keys[key] = r
+	}
+
+	return r.Float64() < s.rate
+}
@@ -0,0 +1,184 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metadata_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/internal/logging/metadata"
+)
+
+func TestDiscardSink(t *testing.T) {
+	require.NoError(t, metadata.DiscardSink{}.Write("module", metadata.INFO, "msg", nil))
+}
+
+func TestWriterSink(t *testing.T) {
+	t.Run("text", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		sink := metadata.WriterSink(buf, metadata.FormatText)
+
+		require.NoError(t, sink.Write("module", metadata.WARNING, "first", map[string]interface{}{"b": 2, "a": "x"}))
+		require.NoError(t, sink.Write("module", metadata.DEBUG, "second", nil))
+		require.Equal(t, "[module] WARNING first a=x b=2\n[module] DEBUG second\n", buf.String())
+	})
+
+	t.Run("unsupported format defaults to text", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		require.NoError(t, metadata.WriterSink(buf, "yaml").Write("module", metadata.INFO, "msg", nil))
+		require.Equal(t, "[module] INFO msg\n", buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		sink := metadata.WriterSink(buf, metadata.FormatJSON)
+
+		require.NoError(t, sink.Write("module", metadata.ERROR, "msg", map[string]interface{}{"key": "value", "msg": "x"}))
+
+		entry := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		require.Equal(t, map[string]interface{}{
+			"module": "module",
+			"level":  "ERROR",
+			"msg":    "msg",
+			"key":    "value",
+		}, entry)
+	})
+
+	t.Run("error: fields cannot be marshalled", func(t *testing.T) {
+		err := metadata.WriterSink(&bytes.Buffer{}, metadata.FormatJSON).Write(
+			"module", metadata.INFO, "msg", map[string]interface{}{"key": make(chan int)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to marshal log entry")
+	})
+
+	t.Run("error: writer fails", func(t *testing.T) {
+		err := metadata.WriterSink(&failingWriter{}, metadata.FormatText).Write("module", metadata.INFO, "msg", nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to write log entry")
+	})
+}
+
+func TestMultiSink(t *testing.T) {
+	t.Run("writes to all sinks", func(t *testing.T) {
+		first, second := &bytes.Buffer{}, &bytes.Buffer{}
+		sink := metadata.MultiSink(
+			metadata.WriterSink(first, metadata.FormatText),
+			metadata.WriterSink(second, metadata.FormatText),
+		)
+
+		require.NoError(t, sink.Write("module", metadata.INFO, "msg", nil))
+		require.Equal(t, "[module] INFO msg\n", first.String())
+		require.Equal(t, "[module] INFO msg\n", second.String())
+	})
+
+	t.Run("error: returns the first error after writing to all sinks", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		sink := metadata.MultiSink(
+			metadata.WriterSink(&failingWriter{}, metadata.FormatText),
+			metadata.WriterSink(buf, metadata.FormatText),
+		)
+
+		err := sink.Write("module", metadata.INFO, "msg", nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to write log entry")
+		require.Equal(t, "[module] INFO msg\n", buf.String())
+	})
+}
+
+func TestSetSink(t *testing.T) {
+	module := "sample-module-sink"
+	defaultSink := metadata.WriterSink(&bytes.Buffer{}, metadata.FormatText)
+	moduleSink := metadata.WriterSink(&bytes.Buffer{}, metadata.FormatJSON)
+
+	require.Nil(t, metadata.GetSink(module))
+
+	metadata.SetSink("", defaultSink)
+	defer metadata.SetSink("", nil)
+
+	require.Equal(t, defaultSink, metadata.GetSink(module))
+
+	metadata.SetSink(module, moduleSink)
+	require.Equal(t, moduleSink, metadata.GetSink(module))
+
+	metadata.SetSink(module, nil)
+	require.Equal(t, defaultSink, metadata.GetSink(module))
+}
+
+type failingWriter struct{}
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("test")
+}
+
+func TestSamplingSink(t *testing.T) {
+	t.Run("samples entries below warning", func(t *testing.T) {
+		inner := &countingSink{}
+		sink := metadata.SamplingSink(inner, 0.25)
+
+		for i := 0; i < 4000; i++ {
+			require.NoError(t, sink.Write("module", metadata.DEBUG, "first", nil))
+			require.NoError(t, sink.Write("module", metadata.INFO, "second", nil))
+		}
+
+		require.InDelta(t, 1000, inner.counts["first"], 150)
+		require.InDelta(t, 1000, inner.counts["second"], 150)
+	})
+
+	t.Run("sampling is reproducible per module and message", func(t *testing.T) {
+		first, second := &countingSink{}, &countingSink{}
+
+		for i := 0; i < 100; i++ {
+			require.NoError(t, metadata.SamplingSink(first, 0.5).Write("module", metadata.DEBUG, "msg", nil))
+			require.NoError(t, metadata.SamplingSink(second, 0.5).Write("module", metadata.DEBUG, "msg", nil))
+		}
+
+		require.Equal(t, first.counts, second.counts)
+	})
+
+	t.Run("passes warning and above", func(t *testing.T) {
+		inner := &countingSink{}
+		sink := metadata.SamplingSink(inner, 0)
+
+		for _, level := range []metadata.Level{metadata.CRITICAL, metadata.ERROR, metadata.WARNING} {
+			require.NoError(t, sink.Write("module", level, level.String(), nil))
+		}
+
+		require.NoError(t, sink.Write("module", metadata.INFO, "INFO", nil))
+		require.Equal(t, map[string]int{"CRITICAL": 1, "ERROR": 1, "WARNING": 1}, inner.counts)
+	})
+
+	t.Run("rate of 1 passes all entries", func(t *testing.T) {
+		inner := &countingSink{}
+		sink := metadata.SamplingSink(inner, 1)
+
+		for i := 0; i < 10; i++ {
+			require.NoError(t, sink.Write("module", metadata.DEBUG, "msg", nil))
+		}
+
+		require.Equal(t, 10, inner.counts["msg"])
+	})
+}
+
+type countingSink struct {
+	counts map[string]int
+}
+
+func (s *countingSink) Write(_ string, _ metadata.Level, msg string, _ map[string]interface{}) error {
+	if s.counts == nil {
+		s.counts = make(map[string]int)
+	}
+
+	s.counts[msg]++
+
+	return nil
+}
@@ -41,6 +41,30 @@ func TestParseLevelError(t *testing.T) {
 	verifyLevelError("", "D", "DE BUG", ".")
 }
 
+func TestParseLevelMap(t *testing.T) {
+	t.Run("parses levels of modules", func(t *testing.T) {
+		levels, err := metadata.ParseLevelMap("debug, module1=INFO,module2 = error,,")
+		require.NoError(t, err)
+		require.Equal(t, map[string]metadata.Level{
+			"":        metadata.DEBUG,
+			"module1": metadata.INFO,
+			"module2": metadata.ERROR,
+		}, levels)
+	})
+
+	t.Run("parses empty string", func(t *testing.T) {
+		levels, err := metadata.ParseLevelMap("")
+		require.NoError(t, err)
+		require.Empty(t, levels)
+	})
+
+	t.Run("fails on invalid level", func(t *testing.T) {
+		_, err := metadata.ParseLevelMap("module1=INFO,module2=DE BUG")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid log level for module [module2]")
+	})
+}
+
 func TestParseString(t *testing.T) {
 	require.Equal(t, "CRITICAL", metadata.ParseString(metadata.CRITICAL))
 	require.Equal(t, "ERROR", metadata.ParseString(metadata.ERROR))
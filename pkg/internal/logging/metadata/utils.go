@@ -8,6 +8,7 @@ package metadata
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -31,6 +32,41 @@ func ParseLevel(level string) (Level, error) {
 	return ERROR, errors.New("logger: invalid log level")
 }
 
+// ParseLevelMap returns the log levels of modules from a comma-separated "module=level" list.
+// An entry without a module, ie. "level", sets the default log level.
+// Example: "DEBUG,module1=INFO,module2=ERROR".
+func ParseLevelMap(s string) (map[string]Level, error) {
+	const (
+		delim      = ","
+		equalityOp = "="
+		numParts   = 2
+	)
+
+	levelMap := make(map[string]Level)
+
+	for _, entry := range strings.Split(s, delim) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		module, levelStr := defaultModuleName, entry
+
+		if kv := strings.SplitN(entry, equalityOp, numParts); len(kv) == numParts {
+			module, levelStr = strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		}
+
+		level, err := ParseLevel(levelStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level for module [%s]: %w", module, err)
+		}
+
+		levelMap[module] = level
+	}
+
+	return levelMap, nil
+}
+
 // ParseString returns string representation of given log level.
 func ParseString(level Level) string {
 	return Levels[level]
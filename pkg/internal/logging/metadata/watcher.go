@@ -0,0 +1,65 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// StartEnvWatcher - polling the given environment variable on the given interval and setting the log levels found
+// in it, using the format of ParseLevelMap. The levels are only set when the value of the variable changes and
+// all of them are set at once. Invalid values are ignored. The watcher exits once the context is done. An error is
+// returned, and no watcher is started, if the interval is not positive.
+func StartEnvWatcher(ctx context.Context, envVar string, pollInterval time.Duration) error {
+	if pollInterval <= 0 {
+		return fmt.Errorf("logger: invalid poll interval: %s", pollInterval)
+	}
+
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		last := applyEnvLevels(envVar, "")
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				last = applyEnvLevels(envVar, last)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// applyEnvLevels sets the log levels found in the environment variable if its value differs from 'last'
+// and returns the value.
+func applyEnvLevels(envVar, last string) string {
+	value := os.Getenv(envVar)
+	if value == last {
+		return last
+	}
+
+	levelMap, err := ParseLevelMap(value)
+	if err != nil {
+		return value
+	}
+
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+
+	for module, level := range levelMap {
+		levels.SetLevel(module, level)
+	}
+
+	return value
+}
@@ -0,0 +1,66 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metadata_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/internal/logging/metadata"
+)
+
+func TestStartEnvWatcher(t *testing.T) {
+	const (
+		envVar  = "METADATA_TEST_LOG_LEVELS"
+		module1 = "sample-module-env-1"
+		module2 = "sample-module-env-2"
+	)
+
+	require.NoError(t, os.Setenv(envVar, module1+"=DEBUG"))
+
+	defer func() {
+		require.NoError(t, os.Unsetenv(envVar))
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, metadata.StartEnvWatcher(ctx, envVar, time.Millisecond))
+
+	require.Eventually(t, func() bool {
+		return metadata.GetLevel(module1) == metadata.DEBUG
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, os.Setenv(envVar, module1+"=ERROR, "+module2+"=WARNING"))
+
+	require.Eventually(t, func() bool {
+		return metadata.GetLevel(module1) == metadata.ERROR && metadata.GetLevel(module2) == metadata.WARNING
+	}, time.Second, time.Millisecond)
+
+	// invalid values are ignored
+	require.NoError(t, os.Setenv(envVar, module1+"=INVALID"))
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, metadata.ERROR, metadata.GetLevel(module1))
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+
+	require.NoError(t, os.Setenv(envVar, module1+"=CRITICAL"))
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, metadata.ERROR, metadata.GetLevel(module1))
+}
+
+func TestStartEnvWatcher_InvalidInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		err := metadata.StartEnvWatcher(context.Background(), "METADATA_TEST_LOG_LEVELS", interval)
+		require.EqualError(t, err, "logger: invalid poll interval: "+interval.String())
+	}
+}
@@ -115,7 +115,10 @@ func (l *DefLog) getCallerInfo(level metadata.Level) string {
 
 	fpcs := make([]uintptr, MAXCALLERS)
 
-	n := runtime.Callers(SKIPCALLERS, fpcs)
+	// libraries wrapping the logger skip more frames than the default
+	skip := SKIPCALLERS + metadata.GetCallerSkip(l.module, level) - metadata.DefaultCallerSkip
+
+	n := runtime.Callers(skip, fpcs)
 	if n == 0 {
 		return fmt.Sprintf(callerInfoFormatter, NOTFOUND)
 	}
@@ -71,7 +71,7 @@ func VerifyDefaultLogging(t *testing.T,
 }
 
 func matchDefLogOutput(t *testing.T, module string, currentLevel, levelEnabled metadata.Level, infoEnabled bool) {
-	if currentLevel > levelEnabled {
+	if currentLevel.IsAbove(levelEnabled) {
 		require.Empty(t, buf.String())
 
 		return
@@ -0,0 +1,138 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package structured
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/edge-core/pkg/internal/logging/metadata"
+)
+
+const (
+	timeKey   = "time"
+	levelKey  = "level"
+	moduleKey = "module"
+	msgKey    = "msg"
+	callerKey = "caller"
+)
+
+// Logger - Structured logger interface.
+// Fields are key-value pairs, ie. "key1", value1, "key2", value2.
+type Logger interface {
+
+	// Debug is for logging verbose messages
+	Debug(module, msg string, fields ...interface{})
+
+	// Info for logging general logging messages
+	Info(module, msg string, fields ...interface{})
+
+	// Warn is for logging messages about possible issues
+	Warn(module, msg string, fields ...interface{})
+
+	// Error is for logging errors
+	Error(module, msg string, fields ...interface{})
+}
+
+// NewJSONLogger returns a new JSONLogger writing to the given writer.
+func NewJSONLogger(w io.Writer) *JSONLogger {
+	return &JSONLogger{writer: w}
+}
+
+// JSONLogger is a Logger writing log lines as JSON objects, one per line.
+// Log Format : {"caller":"<FILE:LINE>","level":"<LOG LEVEL>","module":"<MODULE NAME>","msg":"<LOG TEXT>",
+// "time":"<TIME IN UTC>", <FIELDS>}.
+type JSONLogger struct {
+	writer io.Writer
+	mutex  sync.Mutex
+}
+
+// Debug writes the message and fields if DEBUG level is enabled for the module.
+func (l *JSONLogger) Debug(module, msg string, fields ...interface{}) {
+	l.log(module, metadata.DEBUG, msg, fields)
+}
+
+// Info writes the message and fields if INFO level is enabled for the module.
+func (l *JSONLogger) Info(module, msg string, fields ...interface{}) {
+	l.log(module, metadata.INFO, msg, fields)
+}
+
+// Warn writes the message and fields if WARNING level is enabled for the module.
+func (l *JSONLogger) Warn(module, msg string, fields ...interface{}) {
+	l.log(module, metadata.WARNING, msg, fields)
+}
+
+// Error writes the message and fields if ERROR level is enabled for the module.
+func (l *JSONLogger) Error(module, msg string, fields ...interface{}) {
+	l.log(module, metadata.ERROR, msg, fields)
+}
+
+func (l *JSONLogger) log(module string, level metadata.Level, msg string, fields []interface{}) {
+	if !metadata.IsEnabledFor(module, level) {
+		return
+	}
+
+	entry := toMap(fields)
+	entry[timeKey] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry[levelKey] = metadata.ParseString(level)
+	entry[moduleKey] = module
+	entry[msgKey] = msg
+
+	if metadata.IsCallerInfoEnabled(module, level) {
+		entry[callerKey] = callerInfo(metadata.GetCallerSkip(module, level))
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		fmt.Printf("error marshalling structured log entry %v\n", err)
+
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	_, err = l.writer.Write(append(line, '\n'))
+	if err != nil {
+		fmt.Printf("error from structured logger write %v\n", err)
+	}
+}
+
+// toMap converts key-value pairs into a map. A key without a value is set to nil.
+func toMap(fields []interface{}) map[string]interface{} {
+	const pair = 2
+
+	m := make(map[string]interface{}, len(fields)/pair)
+
+	for i := 0; i < len(fields); i += pair {
+		key := fmt.Sprint(fields[i])
+
+		if i+1 < len(fields) {
+			m[key] = fields[i+1]
+		} else {
+			m[key] = nil
+		}
+	}
+
+	return m
+}
+
+// callerInfo returns the file:line of the caller of the Logger function, skipping 'skip' frames above callerInfo:
+// by default log and the Logger function itself.
+func callerInfo(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "n/a"
+	}
+
+	return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package structured_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/internal/logging/metadata"
+	"github.com/trustbloc/edge-core/pkg/internal/logging/structured"
+)
+
+func TestJSONLogger(t *testing.T) {
+	t.Run("writes JSON log lines with fields", func(t *testing.T) {
+		const module = "structured-json-fields"
+
+		buf := &bytes.Buffer{}
+		logger := structured.NewJSONLogger(buf)
+		metadata.SetLevel(module, metadata.DEBUG)
+
+		logger.Debug(module, "debug msg", "key", "value", "count", 1)
+		entry := parse(t, buf)
+		require.Equal(t, "DEBUG", entry["level"])
+		require.Equal(t, module, entry["module"])
+		require.Equal(t, "debug msg", entry["msg"])
+		require.Equal(t, "value", entry["key"])
+		require.Equal(t, float64(1), entry["count"])
+		require.NotEmpty(t, entry["time"])
+		require.Contains(t, entry["caller"], "jsonlog_test.go:")
+
+		logger.Info(module, "info msg")
+		require.Equal(t, "INFO", parse(t, buf)["level"])
+
+		logger.Warn(module, "warn msg")
+		require.Equal(t, "WARNING", parse(t, buf)["level"])
+
+		logger.Error(module, "error msg", "dangling")
+		entry = parse(t, buf)
+		require.Equal(t, "ERROR", entry["level"])
+		require.Contains(t, entry, "dangling")
+		require.Nil(t, entry["dangling"])
+	})
+
+	t.Run("respects the module's log level", func(t *testing.T) {
+		const module = "structured-json-levels"
+
+		buf := &bytes.Buffer{}
+		logger := structured.NewJSONLogger(buf)
+		metadata.SetLevel(module, metadata.WARNING)
+
+		logger.Debug(module, "msg")
+		logger.Info(module, "msg")
+		require.Empty(t, buf.String())
+
+		logger.Warn(module, "msg")
+		require.Equal(t, "WARNING", parse(t, buf)["level"])
+	})
+
+	t.Run("skips the configured caller frames", func(t *testing.T) {
+		const module = "structured-json-caller-skip"
+
+		buf := &bytes.Buffer{}
+		logger := structured.NewJSONLogger(buf)
+		metadata.ShowCallerInfoWithSkip(module, metadata.INFO, metadata.DefaultCallerSkip+1)
+
+		wrapper := func() {
+			logger.Info(module, "msg")
+		}
+
+		_, _, line, _ := runtime.Caller(0)
+		wrapper() // the caller of the wrapper is logged
+
+		require.Equal(t, fmt.Sprintf("jsonlog_test.go:%d", line+1), parse(t, buf)["caller"])
+	})
+
+	t.Run("omits caller info if disabled", func(t *testing.T) {
+		const module = "structured-json-no-caller"
+
+		buf := &bytes.Buffer{}
+		logger := structured.NewJSONLogger(buf)
+		metadata.HideCallerInfo(module, metadata.INFO)
+
+		logger.Info(module, "msg")
+		require.NotContains(t, parse(t, buf), "caller")
+	})
+}
+
+func parse(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+
+	defer buf.Reset()
+
+	entry := make(map[string]interface{})
+
+	err := json.Unmarshal(buf.Bytes(), &entry)
+	require.NoError(t, err)
+
+	return entry
+}
@@ -18,6 +18,22 @@ const (
 	DEBUG
 )
 
+// IsAtLeast returns true if the log level is at least as verbose as the other one. Levels are ordered from
+// CRITICAL, the least verbose, to DEBUG, the most verbose.
+func (l Level) IsAtLeast(other Level) bool {
+	return l >= other
+}
+
+// IsAbove returns true if the log level is more verbose than the other one.
+func (l Level) IsAbove(other Level) bool {
+	return l > other
+}
+
+// IsBelow returns true if the log level is less verbose than the other one.
+func (l Level) IsBelow(other Level) bool {
+	return l < other
+}
+
 // Logger - Standard logger interface.
 type Logger interface {
 
@@ -19,6 +19,9 @@ const (
 	loggerModule            = "edge-core/pkg/log"
 )
 
+// DefaultCallerSkip is the number of stack frames skipped when capturing the caller of a log function.
+const DefaultCallerSkip = metadata.DefaultCallerSkip
+
 // Log is an implementation of Logger interface.
 // It encapsulates default or custom logger to provide module and level based logging.
 type Log struct {
@@ -92,6 +95,8 @@ func SetLevel(module string, level Level) {
 //  Returns:
 //  logging level
 //
+// Modules with no logging level set inherit the level of their closest parent module, where module names are
+// dot- or slash-separated (eg. "edv/storage" is the parent of "edv/storage/postgres").
 // If not set default logging level is info.
 func GetLevel(module string) Level {
 	return Level(metadata.GetLevel(module))
@@ -114,6 +119,13 @@ func GetAllLevels() map[string]Level {
 	return levels
 }
 
+// GetAllModules - getting the names of all modules with a log level or caller info set
+//  Returns:
+//  sorted module names
+func GetAllModules() []string {
+	return metadata.GetAllModules()
+}
+
 // IsEnabledFor - Check if given log level is enabled for given module
 //  Parameters:
 //  module is module name
@@ -159,6 +171,18 @@ func ShowCallerInfo(module string, level Level) {
 	metadata.ShowCallerInfo(module, metadata.Level(level))
 }
 
+// ShowCallerInfoWithSkip - Show caller info in log lines for given log level and module, skipping the given number
+// of stack frames when capturing the caller.
+//  Parameters:
+//  module is module name
+//  level is logging level
+//  skip is the number of stack frames to skip, DefaultCallerSkip unless the logger is wrapped in extra layers
+//
+// note: based on implementation of custom logger, callerinfo info may not be available for custom logging provider
+func ShowCallerInfoWithSkip(module string, level Level, skip int) {
+	metadata.ShowCallerInfoWithSkip(module, metadata.Level(level), skip)
+}
+
 // HideCallerInfo - Do not show caller info in log lines for given log level and module
 //  Parameters:
 //  module is module name
@@ -181,3 +205,14 @@ func HideCallerInfo(module string, level Level) {
 func IsCallerInfoEnabled(module string, level Level) bool {
 	return metadata.IsCallerInfoEnabled(module, metadata.Level(level))
 }
+
+// GetCallerSkip - returns the number of stack frames skipped when capturing the caller for given log level and module
+//  Parameters:
+//  module is module name
+//  level is logging level
+//
+//  Returns:
+//  the number of stack frames to skip
+func GetCallerSkip(module string, level Level) int {
+	return metadata.GetCallerSkip(module, metadata.Level(level))
+}
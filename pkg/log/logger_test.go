@@ -74,6 +74,13 @@ func TestGetAllLevels(t *testing.T) {
 	require.Equal(t, Level(2), allLogLevels[sampleModuleWarning])
 }
 
+func TestGetAllModules(t *testing.T) {
+	SetLevel("sample-module-modules", DEBUG)
+	ShowCallerInfo("sample-module-modules-caller-info", ERROR)
+
+	require.Subset(t, GetAllModules(), []string{"sample-module-modules", "sample-module-modules-caller-info"})
+}
+
 // TestCallerInfos callerinfo behavior which displays caller function details in log lines
 // CallerInfo is available in default logger.
 // Based on implementation it may not be available for custom logger.
@@ -91,6 +98,12 @@ func TestCallerInfos(t *testing.T) {
 	require.False(t, IsCallerInfoEnabled(module, INFO))
 	require.False(t, IsCallerInfoEnabled(module, ERROR))
 	require.False(t, IsCallerInfoEnabled(module, WARNING))
+
+	require.Equal(t, DefaultCallerSkip, GetCallerSkip(module, INFO))
+
+	ShowCallerInfoWithSkip(module, INFO, DefaultCallerSkip+1)
+	require.True(t, IsCallerInfoEnabled(module, INFO))
+	require.Equal(t, DefaultCallerSkip+1, GetCallerSkip(module, INFO))
 }
 
 // TestLogLevel testing 'LogLevel()' used for parsing log levels from strings.
@@ -122,6 +135,19 @@ func TestParseLevelError(t *testing.T) {
 	verifyLevelError("", "D", "DE BUG", ".")
 }
 
+// TestLevelComparison testing 'IsAtLeast()', 'IsAbove()' and 'IsBelow()' used for comparing log levels.
+func TestLevelComparison(t *testing.T) {
+	require.True(t, DEBUG.IsAtLeast(INFO))
+	require.True(t, INFO.IsAtLeast(INFO))
+	require.False(t, ERROR.IsAtLeast(WARNING))
+
+	require.True(t, INFO.IsAbove(WARNING))
+	require.False(t, WARNING.IsAbove(WARNING))
+
+	require.True(t, CRITICAL.IsBelow(DEBUG))
+	require.False(t, DEBUG.IsBelow(DEBUG))
+}
+
 func TestParseString(t *testing.T) {
 	criticalLogLevel := ParseString(CRITICAL)
 	require.Equal(t, "CRITICAL", criticalLogLevel)
@@ -0,0 +1,96 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// CaveatTypeAttestation is the type of the AttestationCaveat.
+	CaveatTypeAttestation = "sec:AttestationCaveat"
+	// AttestationTypeTPM is the attestation type of TPM 2.0 attestation structures, eg. verified by the
+	// TPMAttestationVerifier.
+	AttestationTypeTPM = "tpm"
+)
+
+// AttestationVerifier verifies attestation tokens of one type, eg. TPM quotes, against an invocation.
+type AttestationVerifier interface {
+	Verify(data []byte, invocation *CapabilityInvocation) error
+}
+
+// AttestationVerifiers maps attestation types to their AttestationVerifiers.
+type AttestationVerifiers map[string]AttestationVerifier
+
+// AttestationCaveat binds a capability to attested hardware: its invocations are verified with the
+// AttestationVerifier of the attestation type. Verifiers only support the attestation types added with
+// WithAttestationVerifier; caveats of other attestation types are never met.
+type AttestationCaveat struct {
+	Type            string `json:"type"`
+	AttestationType string `json:"attestationType"`
+	// AttestationData is the attestation token, base64-encoded in JSON.
+	AttestationData []byte `json:"attestationData"`
+
+	verifiers AttestationVerifiers
+}
+
+// Verify the attestation data with the AttestationVerifier of the attestation type.
+func (c *AttestationCaveat) Verify(invocation *CapabilityInvocation) error {
+	verifier, ok := c.verifiers[c.AttestationType]
+	if !ok {
+		return fmt.Errorf("unsupported attestation type: %s", c.AttestationType)
+	}
+
+	err := verifier.Verify(c.AttestationData, invocation)
+	if err != nil {
+		return fmt.Errorf("failed to verify %s attestation: %w", c.AttestationType, err)
+	}
+
+	return nil
+}
+
+// newAttestationCaveat returns a constructor of AttestationCaveats verified with the verifiers.
+func newAttestationCaveat(verifiers AttestationVerifiers) func() Caveat {
+	return func() Caveat {
+		return &AttestationCaveat{verifiers: verifiers}
+	}
+}
+
+const (
+	// tpmGeneratedValue is the magic number starting the attestation structures generated by a TPM.
+	tpmGeneratedValue = 0xff544347
+	// tpmAttestHeaderSize is the size of the magic number and type of a TPMS_ATTEST structure.
+	tpmAttestHeaderSize = 6
+	// tpmAttestTypeFirst and tpmAttestTypeLast bound the TPM_ST types of attestation structures, from
+	// TPM_ST_ATTEST_NV (0x8014) to TPM_ST_ATTEST_CREATION (0x801a).
+	tpmAttestTypeFirst = 0x8014
+	tpmAttestTypeLast  = 0x801a
+)
+
+// TPMAttestationVerifier verifies TPM 2.0 attestation structures (TPMS_ATTEST). It is a stub: it only checks the
+// data is structured as generated by a TPM, ie. starts with the TPM_GENERATED_VALUE magic number followed by an
+// attestation type. Neither the signature of the structure nor its contents are verified, so it proves nothing about
+// the invoker's hardware. It is not registered by default; add it with WithAttestationVerifier, eg. for tests.
+type TPMAttestationVerifier struct{}
+
+// Verify the data is a TPM attestation structure.
+func (t *TPMAttestationVerifier) Verify(data []byte, _ *CapabilityInvocation) error {
+	if len(data) < tpmAttestHeaderSize {
+		return fmt.Errorf("attestation data too short: %d bytes", len(data))
+	}
+
+	if magic := binary.BigEndian.Uint32(data); magic != tpmGeneratedValue {
+		return fmt.Errorf("attestation data was not generated by a TPM: magic number is %#x", magic)
+	}
+
+	if st := binary.BigEndian.Uint16(data[4:]); st < tpmAttestTypeFirst || st > tpmAttestTypeLast {
+		return fmt.Errorf("invalid TPM attestation type: %#x", st)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+// tpmQuote is the header of a TPMS_ATTEST structure of type TPM_ST_ATTEST_QUOTE.
+var tpmQuote = []byte{0xff, 'T', 'C', 'G', 0x80, 0x18, 0x00} // nolint:gochecknoglobals // test globals
+
+func TestTPMAttestationVerifier_Verify(t *testing.T) {
+	v := &zcapld.TPMAttestationVerifier{}
+
+	t.Run("success", func(t *testing.T) {
+		require.NoError(t, v.Verify(tpmQuote, &zcapld.CapabilityInvocation{}))
+	})
+
+	t.Run("error: data too short", func(t *testing.T) {
+		err := v.Verify(tpmQuote[:5], &zcapld.CapabilityInvocation{})
+		require.EqualError(t, err, "attestation data too short: 5 bytes")
+	})
+
+	t.Run("error: not generated by a TPM", func(t *testing.T) {
+		err := v.Verify([]byte{0xff, 'T', 'C', 'X', 0x80, 0x18}, &zcapld.CapabilityInvocation{})
+		require.EqualError(t, err, "attestation data was not generated by a TPM: magic number is 0xff544358")
+	})
+
+	t.Run("error: invalid attestation type", func(t *testing.T) {
+		err := v.Verify([]byte{0xff, 'T', 'C', 'G', 0x80, 0x01}, &zcapld.CapabilityInvocation{})
+		require.EqualError(t, err, "invalid TPM attestation type: 0x8001")
+	})
+}
+
+func TestVerifier_AttestationCaveat(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	invokerSigner := testSigner(t, kms.ED25519)
+	newCapability := func(attestationType string, data []byte) *zcapld.Capability {
+		return capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(invokerSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}),
+			withCaveats(caveat(t, &zcapld.AttestationCaveat{
+				Type:            zcapld.CaveatTypeAttestation,
+				AttestationType: attestationType,
+				AttestationData: data,
+			})))
+	}
+	verify := func(capability *zcapld.Capability, options ...zcapld.VerificationOption) error {
+		return verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			options...,
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("success: TPM attestation", func(t *testing.T) {
+		err := verify(newCapability(zcapld.AttestationTypeTPM, tpmQuote),
+			zcapld.WithAttestationVerifier(zcapld.AttestationTypeTPM, &zcapld.TPMAttestationVerifier{}))
+		require.NoError(t, err)
+	})
+
+	t.Run("success: custom attestation verifier", func(t *testing.T) {
+		v := &mockAttestationVerifier{}
+
+		err := verify(newCapability("urn:test:attestation", []byte("token")),
+			zcapld.WithAttestationVerifier("urn:test:attestation", v))
+		require.NoError(t, err)
+		require.Equal(t, []byte("token"), v.data)
+		require.NotNil(t, v.invocation)
+	})
+
+	t.Run("error: TPM attestation without an attestation verifier", func(t *testing.T) {
+		err := verify(newCapability(zcapld.AttestationTypeTPM, tpmQuote),
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()), zcapld.WithAllowUnknownCaveatTypes(true))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported attestation type: tpm")
+
+		err = verify(newCapability(zcapld.AttestationTypeTPM, tpmQuote),
+			zcapld.WithAttestationVerifier("urn:test:attestation", &mockAttestationVerifier{}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported attestation type: tpm")
+	})
+
+	t.Run("error: invalid TPM attestation", func(t *testing.T) {
+		capability := newCapability(zcapld.AttestationTypeTPM, []byte("not a quote"))
+
+		err := verify(capability,
+			zcapld.WithAttestationVerifier(zcapld.AttestationTypeTPM, &zcapld.TPMAttestationVerifier{}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "caveat sec:AttestationCaveat not met on capability "+capability.ID)
+		require.Contains(t, err.Error(), "failed to verify tpm attestation: attestation data was not generated by a TPM")
+	})
+
+	t.Run("error: custom attestation verifier fails", func(t *testing.T) {
+		err := verify(newCapability("urn:test:attestation", []byte("token")),
+			zcapld.WithAttestationVerifier("urn:test:attestation", &mockAttestationVerifier{err: errors.New("test")}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to verify urn:test:attestation attestation: test")
+	})
+
+	t.Run("error: unsupported attestation type", func(t *testing.T) {
+		err := verify(newCapability("urn:test:attestation", []byte("token")),
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported attestation type: urn:test:attestation")
+	})
+}
+
+type mockAttestationVerifier struct {
+	err        error
+	data       []byte
+	invocation *zcapld.CapabilityInvocation
+}
+
+func (m *mockAttestationVerifier) Verify(data []byte, invocation *zcapld.CapabilityInvocation) error {
+	m.data, m.invocation = data, invocation
+
+	return m.err
+}
@@ -0,0 +1,85 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"context"
+	"time"
+)
+
+// Outcomes of audited verifications.
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEvent describes an attempt to verify a capability invocation.
+type AuditEvent struct {
+	// CapabilityID is the ID of the invoked capability, if the proof has one.
+	CapabilityID string `json:"capabilityId,omitempty"`
+	// VerificationMethod is the ID of the verification method of the invoker.
+	VerificationMethod string `json:"verificationMethod,omitempty"`
+	// Action is the capability action invoked.
+	Action string `json:"action,omitempty"`
+	// Target is the invocation target.
+	Target string `json:"target,omitempty"`
+	// Outcome is either AuditOutcomeSuccess or AuditOutcomeFailure.
+	Outcome string `json:"outcome"`
+	// Error is the reason for a failure.
+	Error string `json:"error,omitempty"`
+	// Timestamp is the time of the verification, as given by the Verifier's clock.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Auditor records an audit trail of the verification of capability invocations.
+type Auditor interface {
+	Record(ctx context.Context, event AuditEvent) error
+}
+
+// WithAuditor sets the Auditor used to record every verification attempt. Failures to record an event are logged
+// and do not change the outcome of the verification.
+func WithAuditor(a Auditor) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.Auditor = a
+	}
+}
+
+func (v *Verifier) audit(ctx context.Context, proof *Proof, invocation *CapabilityInvocation, err error) {
+	if v.auditor == nil {
+		return
+	}
+
+	event := AuditEvent{
+		VerificationMethod: proof.VerificationMethod,
+		Action:             proof.CapabilityAction,
+		Target:             invocation.ExpectedTarget,
+		Outcome:            AuditOutcomeSuccess,
+		Timestamp:          v.clock(),
+	}
+
+	if invocation.VerificationMethod != nil {
+		event.VerificationMethod = invocation.VerificationMethod.ID
+	}
+
+	if proof.Capability != nil {
+		event.CapabilityID = proof.Capability.ID
+
+		if event.Target == "" {
+			event.Target = proof.Capability.InvocationTarget.ID
+		}
+	}
+
+	if err != nil {
+		event.Outcome = AuditOutcomeFailure
+		event.Error = err.Error()
+	}
+
+	recordErr := v.auditor.Record(ctx, event)
+	if recordErr != nil {
+		logger.Warnf("failed to record audit event for capability %s: %s", event.CapabilityID, recordErr)
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package audit provides implementations of zcapld.Auditor.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+// LoggingAuditor records audit events as JSON lines written to an io.Writer.
+type LoggingAuditor struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewLoggingAuditor returns a new LoggingAuditor that writes to w.
+func NewLoggingAuditor(w io.Writer) *LoggingAuditor {
+	return &LoggingAuditor{encoder: json.NewEncoder(w)}
+}
+
+// Record writes the event as a single line of JSON.
+func (a *LoggingAuditor) Record(_ context.Context, event zcapld.AuditEvent) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	err := a.encoder.Encode(&event)
+	if err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+
+	return nil
+}
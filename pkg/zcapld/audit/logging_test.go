@@ -0,0 +1,75 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/audit"
+)
+
+var _ zcapld.Auditor = (*audit.LoggingAuditor)(nil)
+
+func TestLoggingAuditor(t *testing.T) {
+	t.Run("writes events as JSON lines", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		a := audit.NewLoggingAuditor(buf)
+		timestamp := time.Date(2020, time.November, 5, 12, 0, 0, 0, time.UTC)
+
+		require.NoError(t, a.Record(context.Background(), zcapld.AuditEvent{
+			CapabilityID:       "urn:zcap:1",
+			VerificationMethod: "did:example:123#key1",
+			Action:             "read",
+			Target:             "urn:target",
+			Outcome:            zcapld.AuditOutcomeSuccess,
+			Timestamp:          timestamp,
+		}))
+		require.NoError(t, a.Record(context.Background(), zcapld.AuditEvent{
+			CapabilityID: "urn:zcap:2",
+			Outcome:      zcapld.AuditOutcomeFailure,
+			Error:        "test",
+			Timestamp:    timestamp,
+		}))
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		require.Len(t, lines, 2)
+		require.JSONEq(t, `{
+			"capabilityId": "urn:zcap:1",
+			"verificationMethod": "did:example:123#key1",
+			"action": "read",
+			"target": "urn:target",
+			"outcome": "success",
+			"timestamp": "2020-11-05T12:00:00Z"
+		}`, lines[0])
+		require.JSONEq(t, `{
+			"capabilityId": "urn:zcap:2",
+			"outcome": "failure",
+			"error": "test",
+			"timestamp": "2020-11-05T12:00:00Z"
+		}`, lines[1])
+	})
+
+	t.Run("error: failed to write", func(t *testing.T) {
+		err := audit.NewLoggingAuditor(failingWriter{}).Record(context.Background(), zcapld.AuditEvent{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to write audit event")
+	})
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("test")
+}
@@ -0,0 +1,84 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestWithAuditor(t *testing.T) {
+	now := time.Now()
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	chain := delegationChain(t, root, rootSigner, 1)
+	parent := chain[len(chain)-1]
+	capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+		withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(parent.zcap.ID),
+		withVerMethod(keyID(parent.signer)), withCapabilityChain([]interface{}{root.ID, parent.zcap.ID}))
+
+	verify := func(a zcapld.Auditor, action string) error {
+		return verifier(t, chainResolver(root, chain), chainKeyResolver(t, rootSigner, chain),
+			zcapld.WithAuditor(a), zcapld.WithClock(func() time.Time { return now }),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   action,
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("records successful verifications", func(t *testing.T) {
+		a := &mockAuditor{}
+
+		require.NoError(t, verify(a, "read"))
+		require.Equal(t, []zcapld.AuditEvent{{
+			CapabilityID:       capability.ID,
+			VerificationMethod: capability.Invoker,
+			Action:             "read",
+			Target:             capability.InvocationTarget.ID,
+			Outcome:            zcapld.AuditOutcomeSuccess,
+			Timestamp:          now,
+		}}, a.events)
+	})
+
+	t.Run("records failed verifications", func(t *testing.T) {
+		a := &mockAuditor{}
+
+		err := verify(a, "write")
+		require.Error(t, err)
+		require.Len(t, a.events, 1)
+		require.Equal(t, zcapld.AuditOutcomeFailure, a.events[0].Outcome)
+		require.Equal(t, "write", a.events[0].Action)
+		require.Equal(t, err.Error(), a.events[0].Error)
+	})
+
+	t.Run("failure to record does not change the outcome", func(t *testing.T) {
+		require.NoError(t, verify(&mockAuditor{err: errors.New("test")}, "read"))
+	})
+}
+
+type mockAuditor struct {
+	events []zcapld.AuditEvent
+	err    error
+}
+
+func (m *mockAuditor) Record(_ context.Context, event zcapld.AuditEvent) error {
+	m.events = append(m.events, event)
+
+	return m.err
+}
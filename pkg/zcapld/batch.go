@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BatchVerifyRequest is a proof to verify against an invocation with Verifier.VerifyBatch.
+type BatchVerifyRequest struct {
+	Proof      *Proof
+	Invocation *CapabilityInvocation
+}
+
+// BatchVerifyResult is the result of verifying a BatchVerifyRequest.
+type BatchVerifyResult struct {
+	Request BatchVerifyRequest
+	// Err is the error returned by Verifier.Verify for the request, or nil if the proof was verified.
+	Err error
+}
+
+// VerifyBatch verifies the proofs of the requests in parallel, each as Verify does, and returns their results in
+// the order of the requests. At most as many requests as set with WithMaxConcurrency are verified at once.
+// Requests not yet started when the context is done fail with the context's error.
+func (v *Verifier) VerifyBatch(ctx context.Context, requests []BatchVerifyRequest) []BatchVerifyResult {
+	results := make([]BatchVerifyResult, len(requests))
+
+	var (
+		wg  sync.WaitGroup
+		sem chan struct{}
+	)
+
+	if v.maxConcurrency > 0 {
+		sem = make(chan struct{}, v.maxConcurrency)
+	}
+
+	for i := range requests {
+		results[i].Request = requests[i]
+
+		if !acquire(ctx, sem) {
+			results[i].Err = fmt.Errorf("batch verification stopped: %w", ctx.Err())
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+			defer release(sem)
+
+			results[i].Err = v.Verify(ctx, requests[i].Proof, requests[i].Invocation)
+		}(i)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// acquire a slot in the semaphore, if any, reporting false if the context is done first.
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	if sem == nil {
+		return ctx.Err() == nil
+	}
+
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+
+	if ctx.Err() != nil {
+		release(sem)
+
+		return false
+	}
+
+	return true
+}
+
+func release(sem chan struct{}) {
+	if sem != nil {
+		<-sem
+	}
+}
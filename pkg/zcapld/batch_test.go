@@ -0,0 +1,163 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestVerifier_VerifyBatch(t *testing.T) {
+	root, rootSigner := selfSignedSelfInvokingRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	keys := zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)}
+
+	request := func(action string) zcapld.BatchVerifyRequest {
+		return zcapld.BatchVerifyRequest{
+			Proof: &zcapld.Proof{
+				Capability:         root,
+				CapabilityAction:   action,
+				VerificationMethod: root.Invoker,
+			},
+			Invocation: invocation(root.Invoker, expectAction(action), expectRootCapability(root.ID)),
+		}
+	}
+
+	t.Run("success: results are in the order of the requests", func(t *testing.T) {
+		requests := []zcapld.BatchVerifyRequest{request("read"), request("delete"), request("write")}
+
+		results := verifier(t, zcapld.SimpleCapabilityResolver{root.ID: root}, keys).VerifyBatch(
+			context.Background(), requests)
+		require.Len(t, results, len(requests))
+
+		for i := range results {
+			require.Equal(t, requests[i], results[i].Request)
+		}
+
+		require.NoError(t, results[0].Err)
+		require.True(t, errors.Is(results[1].Err, zcapld.ErrActionNotAllowed))
+		require.NoError(t, results[2].Err)
+	})
+
+	t.Run("success: concurrency is limited", func(t *testing.T) {
+		resolver := &concurrencyResolver{resolver: zcapld.SimpleCapabilityResolver{root.ID: root}}
+		requests := []zcapld.BatchVerifyRequest{request("read"), request("read"), request("read"), request("read")}
+
+		results := verifier(t, resolver, keys, zcapld.WithMaxConcurrency(2)).VerifyBatch(
+			context.Background(), requests)
+
+		for i := range results {
+			require.NoError(t, results[i].Err)
+		}
+
+		require.Equal(t, 2, resolver.max)
+	})
+
+	t.Run("error: context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results := verifier(t, zcapld.SimpleCapabilityResolver{root.ID: root}, keys, zcapld.WithMaxConcurrency(1)).
+			VerifyBatch(ctx, []zcapld.BatchVerifyRequest{request("read"), request("read")})
+
+		for i := range results {
+			require.True(t, errors.Is(results[i].Err, context.Canceled))
+		}
+	})
+}
+
+func BenchmarkVerifier_Verify_Sequential(b *testing.B) {
+	v, requests := benchmarkBatch(b)
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		for i := range requests {
+			if err := v.Verify(context.Background(), requests[i].Proof, requests[i].Invocation); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkVerifier_VerifyBatch verifies the same batch as BenchmarkVerifier_Verify_Sequential. Verification is
+// CPU-bound, so the speedup over sequential verification is bounded by GOMAXPROCS; there is none with a single CPU.
+func BenchmarkVerifier_VerifyBatch(b *testing.B) {
+	v, requests := benchmarkBatch(b)
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		for _, result := range v.VerifyBatch(context.Background(), requests) {
+			if result.Err != nil {
+				b.Fatal(result.Err)
+			}
+		}
+	}
+}
+
+func benchmarkBatch(b *testing.B) (*zcapld.Verifier, []zcapld.BatchVerifyRequest) {
+	b.Helper()
+
+	root, rootSigner := selfSignedSelfInvokingRootCapability(b, kms.ED25519, ed25519signature2018.SignatureType)
+	v := verifier(b,
+		zcapld.SimpleCapabilityResolver{root.ID: root},
+		zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(b, rootSigner)},
+	)
+
+	const batchSize = 16
+
+	requests := make([]zcapld.BatchVerifyRequest, batchSize)
+
+	for i := range requests {
+		requests[i] = zcapld.BatchVerifyRequest{
+			Proof: &zcapld.Proof{
+				Capability:         root,
+				CapabilityAction:   "read",
+				VerificationMethod: root.Invoker,
+			},
+			Invocation: invocation(root.Invoker, expectRootCapability(root.ID)),
+		}
+	}
+
+	return v, requests
+}
+
+// concurrencyResolver records the maximum number of capabilities resolved at once.
+type concurrencyResolver struct {
+	resolver zcapld.CapabilityResolver
+	mutex    sync.Mutex
+	active   int
+	max      int
+}
+
+func (c *concurrencyResolver) Resolve(ctx context.Context, uri string) (*zcapld.Capability, error) {
+	c.mutex.Lock()
+	c.active++
+
+	if c.active > c.max {
+		c.max = c.active
+	}
+
+	c.mutex.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	c.mutex.Lock()
+	c.active--
+	c.mutex.Unlock()
+
+	return c.resolver.Resolve(ctx, uri)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bench_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util/signature"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	zcapldtesting "github.com/trustbloc/edge-core/pkg/zcapld/testing"
+)
+
+func BenchmarkVerify_RootCapability(b *testing.B) {
+	benchmarkVerify(b, 0)
+}
+
+func BenchmarkVerify_OneHopChain(b *testing.B) {
+	benchmarkVerify(b, 1)
+}
+
+func BenchmarkVerify_FiveHopChain(b *testing.B) {
+	benchmarkVerify(b, 5)
+}
+
+func BenchmarkIsInvoker(b *testing.B) {
+	c := zcapldtesting.NewTestCapability("urn:zcap:root", "urn:zcap:root",
+		zcapldtesting.WithInvoker("did:example:123"))
+	vm := zcapldtesting.NewTestVerificationMethod("did:example:123#key-1", "did:example:123")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ok, err := zcapld.IsInvoker(c, vm)
+		if err != nil || !ok {
+			b.Fatalf("unexpected result: %t %v", ok, err)
+		}
+	}
+}
+
+func BenchmarkCapabilityChainParse(b *testing.B) {
+	raw, err := json.Marshal(newChain(b, 5).leaf)
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c, err := zcapld.ParseCapability(raw)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		depth, err := c.Depth()
+		if err != nil || depth != 5 {
+			b.Fatalf("unexpected depth: %d %v", depth, err)
+		}
+	}
+}
+
+func benchmarkVerify(b *testing.B, hops int) {
+	chain := newChain(b, hops)
+	v := verifier(b, chain.resolver)
+	proof := zcapldtesting.NewTestProof(chain.leaf, "read", chain.invoker)
+	invocation := zcapldtesting.NewTestInvocation(
+		zcapldtesting.WithExpectedRootCapability(chain.root.ID),
+		zcapldtesting.WithExpectedTarget(chain.root.ID),
+		zcapldtesting.WithExpectedAction("read"),
+		zcapldtesting.WithVerificationMethod(chain.invoker),
+	)
+
+	require.NoError(b, v.Verify(context.Background(), proof, invocation))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := v.Verify(context.Background(), proof, invocation)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type chain struct {
+	root     *zcapld.Capability
+	leaf     *zcapld.Capability
+	invoker  *zcapld.VerificationMethod
+	resolver zcapld.SimpleCapabilityResolver
+}
+
+// newChain returns a root capability delegated 'hops' times, each time to a new did:key.
+func newChain(b *testing.B, hops int) *chain {
+	b.Helper()
+
+	signer, didKeyURL := newSigner(b)
+
+	id := "urn:zcap:" + uuid.New().String()
+
+	root, err := zcapld.NewCapability(signer,
+		zcapld.WithID(id), zcapld.WithInvocationTarget(id, ""),
+		zcapld.WithInvoker(didKeyURL), zcapld.WithAllowedActions("read"))
+	require.NoError(b, err)
+
+	c := &chain{root: root, leaf: root, resolver: zcapld.SimpleCapabilityResolver{root.ID: root}}
+
+	for i := 0; i < hops; i++ {
+		next, delegatee := newSigner(b)
+
+		c.leaf, err = zcapld.DelegateCapability(c.leaf, delegatee)
+		require.NoError(b, err)
+		require.NoError(b, zcapld.SignCapability(c.leaf, signer))
+
+		c.resolver[c.leaf.ID] = c.leaf
+		signer, didKeyURL = next, delegatee
+	}
+
+	c.invoker = zcapldtesting.NewTestVerificationMethod(didKeyURL, didKeyURL)
+
+	return c
+}
+
+// newSigner returns the signer of a new did:key, along with its DID URL.
+func newSigner(b *testing.B) (*zcapld.Signer, string) {
+	b.Helper()
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(b, err)
+
+	_, didKeyURL := fingerprint.CreateDIDKey(pubKey)
+
+	return &zcapld.Signer{
+		SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signature.GetEd25519Signer(privKey, pubKey))),
+		SuiteType:          ed25519signature2018.SignatureType,
+		VerificationMethod: didKeyURL,
+	}, didKeyURL
+}
+
+func verifier(b *testing.B, resolver zcapld.CapabilityResolver) *zcapld.Verifier {
+	b.Helper()
+
+	loader := verifiable.CachingJSONLDLoader()
+
+	for vocab, filename := range map[string]string{
+		"https://w3id.org/security/v1": "w3id.org.security.v1.json",
+		zcapld.SecurityContextV2:       "w3id.org.security.v2.json",
+	} {
+		raw, err := ioutil.ReadFile(filepath.Join("..", "testdata", "context", filename)) // nolint:gosec // test data
+		require.NoError(b, err)
+
+		doc, err := ld.DocumentFromReader(bytes.NewReader(raw))
+		require.NoError(b, err)
+
+		loader.AddDocument(vocab, doc)
+	}
+
+	v, err := zcapld.NewVerifier(
+		resolver,
+		&zcapld.DIDKeyResolver{},
+		zcapld.WithLDDocumentLoaders(loader),
+		zcapld.WithSignatureSuites(
+			ed25519signature2018.New(suite.WithVerifier(ed25519signature2018.NewPublicKeyVerifier())),
+		),
+	)
+	require.NoError(b, err)
+
+	return v
+}
@@ -0,0 +1,9 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package bench benchmarks the hot paths of capability verification with in-memory resolvers. It has no API: run
+// its benchmarks with `go test -run=^$ -bench=. -benchmem ./pkg/zcapld/bench`.
+package bench
@@ -0,0 +1,124 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CapabilityBuilder builds a capability delegated from a parent capability.
+type CapabilityBuilder struct {
+	parent    *Capability
+	delegatee string
+	opts      *CapabilityOptions
+}
+
+// NewDelegation returns a CapabilityBuilder of a capability delegated from 'parent' to 'delegatee', its invoker.
+// The capability chain is built from the parent's: the URI of the root capability and of any intermediate
+// capabilities, followed by the parent itself, embedded unless it is the root capability.
+// The capability gets the defaults of DelegateCapability.
+func NewDelegation(parent *Capability, delegatee string) *CapabilityBuilder {
+	b := &CapabilityBuilder{parent: parent, delegatee: delegatee}
+
+	if parent != nil {
+		b.opts = delegationOptions(parent, delegatee)
+	}
+
+	return b
+}
+
+// SetID sets the ID of the capability. Defaults to a new urn:uuid.
+func (b *CapabilityBuilder) SetID(id string) *CapabilityBuilder {
+	return b.set(WithID(id))
+}
+
+// SetController sets the controller of the capability.
+func (b *CapabilityBuilder) SetController(controller string) *CapabilityBuilder {
+	return b.set(WithController(controller))
+}
+
+// SetDelegator sets the delegator of the capability.
+func (b *CapabilityBuilder) SetDelegator(delegator string) *CapabilityBuilder {
+	return b.set(WithDelegator(delegator))
+}
+
+// SetAllowedActions sets the actions allowed by the capability. Defaults to the parent's.
+func (b *CapabilityBuilder) SetAllowedActions(actions ...string) *CapabilityBuilder {
+	return b.set(WithAllowedActions(actions...))
+}
+
+// SetAudience restricts the capability to the audiences.
+func (b *CapabilityBuilder) SetAudience(audience ...string) *CapabilityBuilder {
+	return b.set(WithAudience(audience...))
+}
+
+// SetExpiresAt sets the time after which the capability is no longer valid.
+func (b *CapabilityBuilder) SetExpiresAt(expires time.Time) *CapabilityBuilder {
+	return b.set(WithExpiresAt(expires))
+}
+
+// AddCaveat adds a caveat to the capability. The caveat must marshal to a JSON object with a "type".
+func (b *CapabilityBuilder) AddCaveat(caveat interface{}) *CapabilityBuilder {
+	return b.set(WithCaveat(caveat))
+}
+
+func (b *CapabilityBuilder) set(option CapabilityOption) *CapabilityBuilder {
+	if b.opts != nil {
+		option(b.opts)
+	}
+
+	return b
+}
+
+// Build returns the unsigned capability, checked with ValidateSemantics. Sign it with SignCapability.
+func (b *CapabilityBuilder) Build() (*Capability, error) {
+	if b.parent == nil {
+		return nil, errors.New("parent capability is required")
+	}
+
+	if b.delegatee == "" {
+		return nil, errors.New("delegatee is required")
+	}
+
+	chain, err := b.parent.delegationChain()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build capability chain from parent %s: %w", b.parent.ID, err)
+	}
+
+	if !b.parent.IsRoot() {
+		embedded, err := embed(b.parent)
+		if err != nil {
+			return nil, err
+		}
+
+		chain[len(chain)-1] = embedded
+	}
+
+	opts := *b.opts
+
+	return delegate(b.parent, &opts, chain)
+}
+
+// embed returns the capability as an object to embed in a capability chain.
+func embed(c *Capability) (map[string]interface{}, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal capability %s: %w", c.ID, err)
+	}
+
+	embedded := make(map[string]interface{})
+
+	err = json.Unmarshal(raw, &embedded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal capability %s: %w", c.ID, err)
+	}
+
+	return embedded, nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util/signature"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestNewDelegation(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	delegatee := testSigner(t, kms.ED25519)
+	invoker := keyID(testSigner(t, kms.ED25519))
+
+	t.Run("success: builds a chain that verifies", func(t *testing.T) {
+		expires := time.Now().Add(time.Hour)
+
+		parent, err := zcapld.NewDelegation(root, keyID(delegatee)).
+			SetID("urn:zcap:parent").
+			SetAllowedActions("read", "write").
+			Build()
+		require.NoError(t, err)
+		require.Equal(t, "urn:zcap:parent", parent.ID)
+		require.Equal(t, []interface{}{root.ID}, parent.Proof[0]["capabilityChain"])
+		require.NoError(t, zcapld.SignCapability(parent, zcapSigner(rootSigner)))
+
+		zcap, err := zcapld.NewDelegation(parent, invoker).
+			SetAllowedActions("read").
+			SetAudience("https://example.com").
+			SetExpiresAt(expires).
+			SetController(invoker).
+			SetDelegator(invoker).
+			AddCaveat(&zcapld.AllowedActionCaveat{
+				Type:          zcapld.CaveatTypeAllowedAction,
+				AllowedAction: []string{"read"},
+			}).
+			Build()
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(zcap.ID, "urn:uuid:"))
+		require.Equal(t, parent.ID, zcap.Parent)
+		require.Equal(t, invoker, zcap.Invoker)
+		require.Equal(t, invoker, zcap.Controller)
+		require.Equal(t, invoker, zcap.Delegator)
+		require.Equal(t, root.InvocationTarget, zcap.InvocationTarget)
+		require.Equal(t, []string{"read"}, zcap.AllowedAction)
+		require.Equal(t, []string{"https://example.com"}, zcap.Audience)
+		require.Equal(t, expires.UTC().Format(time.RFC3339), zcap.ExpiresAt)
+		require.Len(t, zcap.Caveats, 1)
+
+		chain, ok := zcap.Proof[0]["capabilityChain"].([]interface{})
+		require.True(t, ok)
+		require.Len(t, chain, 2)
+		require.Equal(t, root.ID, chain[0])
+		require.IsType(t, map[string]interface{}{}, chain[1])
+
+		id, isURI, err := zcap.ParentCapabilityID()
+		require.NoError(t, err)
+		require.False(t, isURI)
+		require.Equal(t, parent.ID, id)
+
+		require.NoError(t, zcapld.SignCapability(zcap, zcapSigner(delegatee)))
+
+		err = verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{
+				keyID(rootSigner): keyValue(t, rootSigner),
+				keyID(delegatee):  keyValue(t, delegatee),
+			},
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         zcap,
+				CapabilityAction:   "read",
+				VerificationMethod: invoker,
+			},
+			invocation(invoker, expectRootCapability(root.ID), expectAudience("https://example.com")),
+		)
+		require.NoError(t, err)
+
+		// capabilities delegated further refer to the embedded parent by its URI
+		child, err := zcapld.DelegateCapability(zcap, keyID(testSigner(t, kms.ED25519)))
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{root.ID, parent.ID, zcap.ID}, child.Proof[0]["capabilityChain"])
+	})
+
+	t.Run("error: actions not allowed by the parent", func(t *testing.T) {
+		_, err := zcapld.NewDelegation(root, invoker).SetAllowedActions("read", "delete").Build()
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrActionNotAllowed))
+	})
+
+	t.Run("error: invalid semantics", func(t *testing.T) {
+		_, err := zcapld.NewDelegation(root, invoker).SetID("relative").Build()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "capability ID is not an absolute URI: relative")
+	})
+
+	t.Run("error: missing arguments", func(t *testing.T) {
+		_, err := zcapld.NewDelegation(nil, invoker).SetID("urn:zcap:1").Build()
+		require.EqualError(t, err, "parent capability is required")
+
+		_, err = zcapld.NewDelegation(root, "").Build()
+		require.EqualError(t, err, "delegatee is required")
+	})
+
+	t.Run("error: invalid parent chain", func(t *testing.T) {
+		_, err := zcapld.NewDelegation(&zcapld.Capability{ID: "urn:zcap:1", Parent: "urn:zcap:0"}, invoker).Build()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to build capability chain from parent urn:zcap:1")
+	})
+}
+
+func zcapSigner(s signature.Signer) *zcapld.Signer {
+	return &zcapld.Signer{
+		SignatureSuite:     ed25519signature2018.New(suite.WithSigner(s)),
+		SuiteType:          ed25519signature2018.SignatureType,
+		VerificationMethod: keyID(s),
+	}
+}
@@ -0,0 +1,350 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	// CaveatTypeAllowedAction is the type of the AllowedActionCaveat.
+	CaveatTypeAllowedAction = "sec:AllowedActionCaveat"
+	// CaveatTypeExpiryDate is the type of the ExpiryDateCaveat.
+	CaveatTypeExpiryDate = "sec:ExpirationCaveat"
+	// CaveatTypeMaxInvocations is the type of the MaxInvocationsCaveat.
+	CaveatTypeMaxInvocations = "sec:MaxInvocationsCaveat"
+	// CaveatTypeCIDR is the type of the CIDRCaveat.
+	CaveatTypeCIDR = "sec:CIDRCaveat"
+	// CaveatTypeTimeWindow is the type of the TimeWindowCaveat.
+	CaveatTypeTimeWindow = "sec:TimeWindowCaveat"
+	// CaveatTypeAdditionalTargets is the type of the AdditionalTargetsCaveat.
+	CaveatTypeAdditionalTargets = "sec:AdditionalTargetsCaveat"
+)
+
+// Caveat is a restriction placed on the invocation of a capability.
+type Caveat interface {
+	Verify(invocation *CapabilityInvocation) error
+}
+
+// CaveatRegistry maps caveat types to constructors of their Caveat implementations.
+// The caveat found on the capability is unmarshalled into the Caveat returned by the constructor
+// before it is verified.
+type CaveatRegistry map[string]func() Caveat
+
+// DefaultCaveatRegistry returns a CaveatRegistry with all built-in caveat types.
+func DefaultCaveatRegistry() CaveatRegistry {
+	return CaveatRegistry{
+		CaveatTypeAllowedAction: func() Caveat { return &AllowedActionCaveat{} },
+		CaveatTypeExpiryDate:    func() Caveat { return &ExpiryDateCaveat{} },
+		CaveatTypeCIDR:          func() Caveat { return &CIDRCaveat{} },
+		CaveatTypeTimeWindow:    func() Caveat { return &TimeWindowCaveat{} },
+		CaveatTypeAttestation:   newAttestationCaveat(nil),
+
+		CaveatTypeAdditionalTargets: func() Caveat { return &AdditionalTargetsCaveat{} },
+	}
+}
+
+// with returns a copy of the registry with the caveat type registered with the constructor.
+func (r CaveatRegistry) with(caveatType string, newCaveat func() Caveat) CaveatRegistry {
+	registry := CaveatRegistry{caveatType: newCaveat}
+
+	for name, caveat := range r {
+		if name != caveatType {
+			registry[name] = caveat
+		}
+	}
+
+	return registry
+}
+
+// AllowedActionCaveat restricts the actions that can be invoked.
+type AllowedActionCaveat struct {
+	Type          string   `json:"type"`
+	AllowedAction []string `json:"allowedAction"`
+}
+
+// Verify the invocation's expected action is allowed by the caveat.
+func (c *AllowedActionCaveat) Verify(invocation *CapabilityInvocation) error {
+	if !stringsContain(c.AllowedAction, invocation.ExpectedAction) {
+		return fmt.Errorf(
+			`%w: action "%s" is not allowed by the caveat; allowed actions are: %+v`,
+			ErrActionNotAllowed, invocation.ExpectedAction, c.AllowedAction)
+	}
+
+	return nil
+}
+
+// ExpiryDateCaveat restricts the invocation of a capability up to an expiry date.
+type ExpiryDateCaveat struct {
+	Type    string `json:"type"`
+	Expires string `json:"expires"`
+	// Clock returns the current time. Verifiers set it to their clock. Defaults to time.Now.
+	Clock func() time.Time `json:"-"`
+}
+
+// Verify the caveat has not expired.
+func (c *ExpiryDateCaveat) Verify(_ *CapabilityInvocation) error {
+	expires, err := time.Parse(time.RFC3339, c.Expires)
+	if err != nil {
+		return fmt.Errorf("invalid expiry format: %w", err)
+	}
+
+	clock := c.Clock
+	if clock == nil {
+		clock = time.Now
+	}
+
+	if clock().After(expires) {
+		return fmt.Errorf("%w: caveat expired at %s", ErrCapabilityExpired, c.Expires)
+	}
+
+	return nil
+}
+
+func (c *ExpiryDateCaveat) setClock(clock func() time.Time) {
+	c.Clock = clock
+}
+
+// CIDRCaveat restricts the invocation of a capability to callers from the allowed network ranges.
+type CIDRCaveat struct {
+	Type         string   `json:"type"`
+	AllowedCIDRs []string `json:"allowedCIDRs"`
+}
+
+// Verify the invocation's caller IP is in one of the allowed CIDR ranges.
+func (c *CIDRCaveat) Verify(invocation *CapabilityInvocation) error {
+	if invocation.CallerIP == nil {
+		return errors.New("caller IP is required by the caveat")
+	}
+
+	for _, cidr := range c.AllowedCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid CIDR format: %w", err)
+		}
+
+		if network.Contains(invocation.CallerIP) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("caller IP %s is not in the ranges allowed by the caveat: %+v",
+		invocation.CallerIP, c.AllowedCIDRs)
+}
+
+// TimeWindowCaveat restricts the invocation of a capability to a daily window of time, eg. business hours.
+// DailyStart and DailyEnd are offsets from midnight UTC, from 0 up to 24 hours; the window includes its start but not
+// its end. A window with its start after its end spans midnight.
+type TimeWindowCaveat struct {
+	Type       string        `json:"type"`
+	DailyStart time.Duration `json:"dailyStart"`
+	DailyEnd   time.Duration `json:"dailyEnd"`
+	// Clock returns the current time. Verifiers set it to their clock. Defaults to time.Now.
+	Clock func() time.Time `json:"-"`
+}
+
+// Verify the current time is within the daily window of the caveat.
+func (c *TimeWindowCaveat) Verify(_ *CapabilityInvocation) error {
+	const day = 24 * time.Hour
+
+	if c.DailyStart < 0 || c.DailyStart > day || c.DailyEnd < 0 || c.DailyEnd > day {
+		return fmt.Errorf("invalid time window: [%s, %s)", c.DailyStart, c.DailyEnd)
+	}
+
+	clock := c.Clock
+	if clock == nil {
+		clock = time.Now
+	}
+
+	now := clock().UTC()
+	offset := now.Sub(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+
+	within := offset >= c.DailyStart && offset < c.DailyEnd
+	if c.DailyStart > c.DailyEnd {
+		within = offset >= c.DailyStart || offset < c.DailyEnd
+	}
+
+	if !within {
+		return fmt.Errorf("invocation at %s is outside the daily time window of the caveat: [%s, %s) UTC",
+			now.Format(time.RFC3339Nano), c.DailyStart, c.DailyEnd)
+	}
+
+	return nil
+}
+
+func (c *TimeWindowCaveat) setClock(clock func() time.Time) {
+	c.Clock = clock
+}
+
+// MaxInvocationsCaveat restricts the number of times a capability can be invoked. It is only supported by Verifiers
+// created with WithMaxInvocationsCounter, which count the invocations of each capability carrying the caveat.
+// An invocation is only counted once it has been verified successfully, so invalid invocations do not use up the
+// capability's invocations.
+type MaxInvocationsCaveat struct {
+	Type  string `json:"type"`
+	Limit int64  `json:"limit"`
+
+	counter      MaxInvocationsCounter
+	capabilityID string
+}
+
+// Verify the invocations of the capability can be counted. The Verifier counts the invocation once verified.
+func (c *MaxInvocationsCaveat) Verify(_ *CapabilityInvocation) error {
+	if c.counter == nil {
+		return errors.New("no counter of invocations to verify the caveat")
+	}
+
+	return nil
+}
+
+// count the invocation, ensuring the capability has not been invoked more than the caveat's limit.
+func (c *MaxInvocationsCaveat) count() error {
+	n, err := c.counter.Increment(c.capabilityID)
+	if err != nil {
+		return fmt.Errorf("failed to count invocation of capability %s: %w", c.capabilityID, err)
+	}
+
+	if n > c.Limit {
+		return fmt.Errorf("%w: capability %s invoked %d times; limit is %d",
+			ErrMaxInvocationsExceeded, c.capabilityID, n, c.Limit)
+	}
+
+	return nil
+}
+
+func (c *MaxInvocationsCaveat) setCapability(capability *Capability) {
+	c.capabilityID = capability.ID
+}
+
+// countInvocation counts the invocation against each MaxInvocationsCaveat of the capability, constructed with
+// 'newCaveat'.
+func countInvocation(capability *Capability, newCaveat func() Caveat) error {
+	for i := range capability.Caveats {
+		discriminator := &struct {
+			Type string `json:"type"`
+		}{}
+
+		if json.Unmarshal(capability.Caveats[i], discriminator) != nil ||
+			discriminator.Type != CaveatTypeMaxInvocations {
+			continue
+		}
+
+		caveat, ok := newCaveat().(*MaxInvocationsCaveat)
+		if !ok {
+			continue
+		}
+
+		err := json.Unmarshal(capability.Caveats[i], caveat)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal caveat of type %s: %w", CaveatTypeMaxInvocations, err)
+		}
+
+		if caveat.counter == nil {
+			continue
+		}
+
+		caveat.setCapability(capability)
+
+		err = caveat.count()
+		if err != nil {
+			return fmt.Errorf("caveat %s not met on capability %s: %w", CaveatTypeMaxInvocations, capability.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// AdditionalTargetsCaveat lists the targets that invocations of a capability may include besides its invocation
+// target, in CapabilityInvocation.AdditionalTargets. Without the caveat, a root capability only covers its invocation
+// target. The caveat on a root capability grants the targets; on a delegated capability, it further restricts them.
+type AdditionalTargetsCaveat struct {
+	Type    string   `json:"type"`
+	Targets []string `json:"targets"`
+
+	capabilityID string
+	target       string
+}
+
+// Verify every additional target of the invocation is the invocation target of the capability or one of the
+// caveat's targets.
+func (c *AdditionalTargetsCaveat) Verify(invocation *CapabilityInvocation) error {
+	for _, target := range invocation.AdditionalTargets {
+		if target != c.target && !stringsContain(c.Targets, target) {
+			return &UnauthorizedTargetError{CapabilityID: c.capabilityID, Target: target}
+		}
+	}
+
+	return nil
+}
+
+func (c *AdditionalTargetsCaveat) setCapability(capability *Capability) {
+	c.capabilityID = capability.ID
+	c.target = capability.InvocationTarget.ID
+}
+
+// capabilityCaveat is implemented by caveats that verify the invocation of the capability they are found on.
+type capabilityCaveat interface {
+	setCapability(capability *Capability)
+}
+
+// clockCaveat is implemented by caveats that verify the invocation against the current time.
+type clockCaveat interface {
+	setClock(clock func() time.Time)
+}
+
+// verify the caveats on the capability at the time of the clock. Caveats of unsupported types are skipped if
+// 'allowUnknown' is true.
+func (r CaveatRegistry) verify(
+	capability *Capability, invocation *CapabilityInvocation, clock func() time.Time, allowUnknown bool) error {
+	for i := range capability.Caveats {
+		discriminator := &struct {
+			Type string `json:"type"`
+		}{}
+
+		err := json.Unmarshal(capability.Caveats[i], discriminator)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal caveat on capability %s: %w", capability.ID, err)
+		}
+
+		newCaveat, ok := r[discriminator.Type]
+		if !ok && allowUnknown {
+			logger.Warnf("ignoring unsupported caveat type on capability %s: %s", capability.ID, discriminator.Type)
+
+			continue
+		}
+
+		if !ok {
+			return fmt.Errorf("unsupported caveat type on capability %s: %s", capability.ID, discriminator.Type)
+		}
+
+		caveat := newCaveat()
+
+		err = json.Unmarshal(capability.Caveats[i], caveat)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal caveat of type %s: %w", discriminator.Type, err)
+		}
+
+		if c, ok := caveat.(capabilityCaveat); ok {
+			c.setCapability(capability)
+		}
+
+		if c, ok := caveat.(clockCaveat); ok {
+			c.setClock(clock)
+		}
+
+		err = caveat.Verify(invocation)
+		if err != nil {
+			return fmt.Errorf("caveat %s not met on capability %s: %w", discriminator.Type, capability.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,622 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestAllowedActionCaveat_Verify(t *testing.T) {
+	t.Run("success: action is allowed", func(t *testing.T) {
+		c := &zcapld.AllowedActionCaveat{AllowedAction: []string{"read", "write"}}
+		require.NoError(t, c.Verify(&zcapld.CapabilityInvocation{ExpectedAction: "write"}))
+	})
+
+	t.Run("error: action is not allowed", func(t *testing.T) {
+		c := &zcapld.AllowedActionCaveat{AllowedAction: []string{"read"}}
+		err := c.Verify(&zcapld.CapabilityInvocation{ExpectedAction: "write"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `action "write" is not allowed by the caveat`)
+		require.True(t, errors.Is(err, zcapld.ErrActionNotAllowed))
+	})
+}
+
+func TestExpiryDateCaveat_Verify(t *testing.T) {
+	t.Run("success: caveat has not expired", func(t *testing.T) {
+		c := &zcapld.ExpiryDateCaveat{Expires: time.Now().Add(time.Hour).Format(time.RFC3339)}
+		require.NoError(t, c.Verify(&zcapld.CapabilityInvocation{}))
+	})
+
+	t.Run("error: caveat has expired", func(t *testing.T) {
+		c := &zcapld.ExpiryDateCaveat{Expires: time.Now().Add(-time.Hour).Format(time.RFC3339)}
+		err := c.Verify(&zcapld.CapabilityInvocation{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "caveat expired at")
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityExpired))
+	})
+
+	t.Run("expiry is verified against the clock", func(t *testing.T) {
+		expires := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+		c := &zcapld.ExpiryDateCaveat{Expires: expires.Format(time.RFC3339), Clock: func() time.Time {
+			return expires.Add(-time.Minute)
+		}}
+		require.NoError(t, c.Verify(&zcapld.CapabilityInvocation{}))
+
+		c.Clock = func() time.Time { return expires.Add(time.Minute) }
+		require.True(t, errors.Is(c.Verify(&zcapld.CapabilityInvocation{}), zcapld.ErrCapabilityExpired))
+	})
+
+	t.Run("error: invalid expiry format", func(t *testing.T) {
+		c := &zcapld.ExpiryDateCaveat{Expires: "tomorrow"}
+		err := c.Verify(&zcapld.CapabilityInvocation{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid expiry format")
+	})
+}
+
+func TestCIDRCaveat_Verify(t *testing.T) {
+	c := &zcapld.CIDRCaveat{AllowedCIDRs: []string{"10.0.0.0/8", "2001:db8::/32"}}
+
+	t.Run("success: caller IP is in an allowed range", func(t *testing.T) {
+		require.NoError(t, c.Verify(&zcapld.CapabilityInvocation{CallerIP: net.ParseIP("10.1.2.3")}))
+		require.NoError(t, c.Verify(&zcapld.CapabilityInvocation{CallerIP: net.ParseIP("2001:db8::1")}))
+	})
+
+	t.Run("error: caller IP is not in an allowed range", func(t *testing.T) {
+		err := c.Verify(&zcapld.CapabilityInvocation{CallerIP: net.ParseIP("192.168.1.1")})
+		require.EqualError(t, err,
+			"caller IP 192.168.1.1 is not in the ranges allowed by the caveat: [10.0.0.0/8 2001:db8::/32]")
+	})
+
+	t.Run("error: no caller IP", func(t *testing.T) {
+		err := c.Verify(&zcapld.CapabilityInvocation{})
+		require.EqualError(t, err, "caller IP is required by the caveat")
+	})
+
+	t.Run("error: invalid CIDR format", func(t *testing.T) {
+		err := (&zcapld.CIDRCaveat{AllowedCIDRs: []string{"10.0.0.0"}}).Verify(
+			&zcapld.CapabilityInvocation{CallerIP: net.ParseIP("10.1.2.3")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid CIDR format")
+	})
+}
+
+func TestTimeWindowCaveat_Verify(t *testing.T) {
+	day := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	at := func(offset time.Duration) func() time.Time {
+		return func() time.Time { return day.Add(offset) }
+	}
+
+	t.Run("business hours", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			offset time.Duration
+			within bool
+		}{
+			{name: "exactly at start", offset: 9 * time.Hour, within: true},
+			{name: "one nanosecond before start", offset: 9*time.Hour - time.Nanosecond},
+			{name: "one nanosecond before end", offset: 17*time.Hour - time.Nanosecond, within: true},
+			{name: "exactly at end", offset: 17 * time.Hour},
+			{name: "midnight", offset: 0},
+		}
+
+		for _, tc := range tests {
+			tc := tc
+
+			t.Run(tc.name, func(t *testing.T) {
+				c := &zcapld.TimeWindowCaveat{DailyStart: 9 * time.Hour, DailyEnd: 17 * time.Hour, Clock: at(tc.offset)}
+
+				err := c.Verify(&zcapld.CapabilityInvocation{})
+				if tc.within {
+					require.NoError(t, err)
+
+					return
+				}
+
+				require.Error(t, err)
+				require.Contains(t, err.Error(),
+					"is outside the daily time window of the caveat: [9h0m0s, 17h0m0s) UTC")
+			})
+		}
+	})
+
+	t.Run("window spanning midnight", func(t *testing.T) {
+		c := &zcapld.TimeWindowCaveat{DailyStart: 22 * time.Hour, DailyEnd: 6 * time.Hour}
+
+		for _, offset := range []time.Duration{22 * time.Hour, 0, 6*time.Hour - time.Nanosecond} {
+			c.Clock = at(offset)
+			require.NoError(t, c.Verify(&zcapld.CapabilityInvocation{}))
+		}
+
+		for _, offset := range []time.Duration{6 * time.Hour, 12 * time.Hour, 22*time.Hour - time.Nanosecond} {
+			c.Clock = at(offset)
+			require.Error(t, c.Verify(&zcapld.CapabilityInvocation{}))
+		}
+	})
+
+	t.Run("clock in another time zone", func(t *testing.T) {
+		c := &zcapld.TimeWindowCaveat{DailyStart: 9 * time.Hour, DailyEnd: 17 * time.Hour, Clock: func() time.Time {
+			return day.Add(10 * time.Hour).In(time.FixedZone("UTC-10", -10*60*60))
+		}}
+		require.NoError(t, c.Verify(&zcapld.CapabilityInvocation{}))
+	})
+
+	t.Run("error: invalid time window", func(t *testing.T) {
+		c := &zcapld.TimeWindowCaveat{DailyStart: 9 * time.Hour, DailyEnd: 25 * time.Hour}
+		err := c.Verify(&zcapld.CapabilityInvocation{})
+		require.EqualError(t, err, "invalid time window: [9h0m0s, 25h0m0s)")
+	})
+}
+
+func TestVerifier_VerifyCaveats(t *testing.T) {
+	t.Run("success: caveats are met", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}),
+			withCaveats(
+				caveat(t, &zcapld.AllowedActionCaveat{
+					Type:          zcapld.CaveatTypeAllowedAction,
+					AllowedAction: []string{"read"},
+				}),
+				caveat(t, &zcapld.ExpiryDateCaveat{
+					Type:    zcapld.CaveatTypeExpiryDate,
+					Expires: time.Now().Add(time.Hour).Format(time.RFC3339),
+				}),
+				caveat(t, &zcapld.CIDRCaveat{
+					Type:         zcapld.CaveatTypeCIDR,
+					AllowedCIDRs: []string{"127.0.0.0/8"},
+				}),
+			))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{
+				keyID(rootSigner): keyValue(t, rootSigner),
+			},
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+		)
+		inv := invocation(capability.Invoker, expectRootCapability(root.ID))
+		inv.CallerIP = net.ParseIP("127.0.0.1")
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			inv,
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("success: custom caveat", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}),
+			withCaveats(caveat(t, &mockCaveat{Type: "urn:test:custom"})))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{
+				keyID(rootSigner): keyValue(t, rootSigner),
+			},
+			zcapld.WithCaveats(zcapld.CaveatRegistry{
+				"urn:test:custom": func() zcapld.Caveat { return &mockCaveat{} },
+			}),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: caveat is not met", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withParent(root.ID), withVerMethod(keyID(rootSigner)), withCapabilityChain([]interface{}{root.ID}),
+			withCaveats(caveat(t, &zcapld.AllowedActionCaveat{
+				Type:          zcapld.CaveatTypeAllowedAction,
+				AllowedAction: []string{"write"},
+			})))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{},
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "caveat sec:AllowedActionCaveat not met on capability "+capability.ID)
+	})
+
+	t.Run("time window caveat is verified with the verifier's clock", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}),
+			withCaveats(caveat(t, &zcapld.TimeWindowCaveat{
+				Type:       zcapld.CaveatTypeTimeWindow,
+				DailyStart: 9 * time.Hour,
+				DailyEnd:   17 * time.Hour,
+			})))
+		verify := func(now time.Time) error {
+			return verifier(t,
+				zcapld.SimpleCapabilityResolver{root.ID: root},
+				zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+				zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+				zcapld.WithClock(func() time.Time { return now }),
+			).Verify(
+				context.Background(),
+				&zcapld.Proof{
+					Capability:         capability,
+					CapabilityAction:   "read",
+					VerificationMethod: capability.Invoker,
+				},
+				invocation(capability.Invoker, expectRootCapability(root.ID)),
+			)
+		}
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+
+		require.NoError(t, verify(today.Add(12*time.Hour)))
+
+		err := verify(today.Add(18 * time.Hour))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "caveat sec:TimeWindowCaveat not met on capability "+capability.ID)
+	})
+
+	t.Run("expiry date caveat is verified with the verifier's clock", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		expires := time.Now().Add(-time.Hour)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}),
+			withCaveats(caveat(t, &zcapld.ExpiryDateCaveat{
+				Type:    zcapld.CaveatTypeExpiryDate,
+				Expires: expires.Format(time.RFC3339),
+			})))
+		verify := func(now time.Time) error {
+			return verifier(t,
+				zcapld.SimpleCapabilityResolver{root.ID: root},
+				zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+				zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+				zcapld.WithClock(func() time.Time { return now }),
+			).Verify(
+				context.Background(),
+				&zcapld.Proof{
+					Capability:         capability,
+					CapabilityAction:   "read",
+					VerificationMethod: capability.Invoker,
+				},
+				invocation(capability.Invoker, expectRootCapability(root.ID)),
+			)
+		}
+
+		require.NoError(t, verify(expires.Add(-time.Minute)))
+
+		err := verify(expires.Add(time.Minute))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityExpired))
+	})
+
+	t.Run("error: caveat on root capability is not met", func(t *testing.T) {
+		rootSigner := testSigner(t, kms.ED25519)
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withVerMethod(keyID(rootSigner)),
+			withCaveats(caveat(t, &zcapld.ExpiryDateCaveat{
+				Type:    zcapld.CaveatTypeExpiryDate,
+				Expires: time.Now().Add(-time.Hour).Format(time.RFC3339),
+			})))
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withParent(root.ID), withVerMethod(keyID(rootSigner)), withCapabilityChain([]interface{}{root.ID}))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{},
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "caveat sec:ExpirationCaveat not met on capability "+root.ID)
+	})
+
+	t.Run("error: unsupported caveat type", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withParent(root.ID), withVerMethod(keyID(rootSigner)), withCapabilityChain([]interface{}{root.ID}),
+			withCaveats(caveat(t, &mockCaveat{Type: "urn:test:unsupported"})))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{},
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported caveat type on capability")
+	})
+
+	t.Run("success: unsupported caveat type allowed", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(rootSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}),
+			withCaveats(caveat(t, &mockCaveat{Type: "urn:test:unsupported"})))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+			zcapld.WithAllowUnknownCaveatTypes(true),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: supported caveats are verified along with unsupported caveat types", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(rootSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}),
+			withCaveats(
+				caveat(t, &mockCaveat{Type: "urn:test:unsupported"}),
+				caveat(t, &zcapld.AllowedActionCaveat{
+					Type:          zcapld.CaveatTypeAllowedAction,
+					AllowedAction: []string{"write"},
+				}),
+			))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+			zcapld.WithAllowUnknownCaveatTypes(true),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrActionNotAllowed))
+		require.Contains(t, err.Error(), "caveat "+zcapld.CaveatTypeAllowedAction+" not met")
+	})
+
+	t.Run("error: malformed caveat", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withParent(root.ID), withVerMethod(keyID(rootSigner)), withCapabilityChain([]interface{}{root.ID}))
+		capability.Caveats = []json.RawMessage{json.RawMessage(`"not an object"`)}
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{},
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal caveat")
+	})
+
+	t.Run("error: custom caveat fails", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withParent(root.ID), withVerMethod(keyID(rootSigner)), withCapabilityChain([]interface{}{root.ID}),
+			withCaveats(caveat(t, &mockCaveat{Type: "urn:test:custom"})))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{},
+			zcapld.WithCaveats(zcapld.CaveatRegistry{
+				"urn:test:custom": func() zcapld.Caveat { return &mockCaveat{err: errors.New("test")} },
+			}),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "caveat urn:test:custom not met on capability")
+	})
+}
+
+type mockCaveat struct {
+	Type string `json:"type"`
+	err  error
+}
+
+func (m *mockCaveat) Verify(_ *zcapld.CapabilityInvocation) error {
+	return m.err
+}
+
+func caveat(t *testing.T, c interface{}) json.RawMessage {
+	t.Helper()
+
+	return marshal(t, c)
+}
+
+func TestVerifier_AdditionalTargets(t *testing.T) {
+	const (
+		target = "https://foo.com/edvs/z19rnXA8d4TPLPHoSFwnQk256/documents/z19pj5XguLxKdXjxj38o7mDj3"
+		listed = "https://foo.com/edvs/z19rnXA8d4TPLPHoSFwnQk256/documents/z19uMCiPNET4YbcPpBcab5mEE"
+		other  = "https://foo.com/edvs/z19rnXA8d4TPLPHoSFwnQk256/documents/z1A3W5eMvxrXga4LLwUTFvAwy"
+	)
+
+	rootSigner := testSigner(t, kms.ED25519)
+	root := capability(t,
+		rootSigner, ed25519signature2018.SignatureType,
+		withController(keyID(rootSigner)),
+		withVerMethod(keyID(rootSigner)),
+		withCaveats(caveat(t, &zcapld.AdditionalTargetsCaveat{
+			Type:    zcapld.CaveatTypeAdditionalTargets,
+			Targets: []string{listed},
+		})))
+
+	verify := func(t *testing.T, c *zcapld.Capability, additionalTargets ...string) error {
+		t.Helper()
+
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{
+				keyID(rootSigner): keyValue(t, rootSigner),
+			},
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+		)
+		inv := invocation(c.Invoker, expectRootCapability(root.ID))
+		inv.AdditionalTargets = additionalTargets
+
+		return verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         c,
+				CapabilityAction:   "read",
+				VerificationMethod: c.Invoker,
+			},
+			inv,
+		)
+	}
+
+	delegated := func(t *testing.T, options ...zcapOption) *zcapld.Capability {
+		t.Helper()
+
+		return capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			append([]zcapOption{
+				withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+				withCapabilityChain([]interface{}{root.ID}),
+			}, options...)...)
+	}
+
+	t.Run("success: targets covered by the root capability", func(t *testing.T) {
+		require.NoError(t, verify(t, delegated(t), target, listed))
+	})
+
+	t.Run("success: targets covered by the delegated capability", func(t *testing.T) {
+		c := delegated(t, withCaveats(caveat(t, &zcapld.AdditionalTargetsCaveat{
+			Type:    zcapld.CaveatTypeAdditionalTargets,
+			Targets: []string{listed},
+		})))
+
+		require.NoError(t, verify(t, c, listed))
+	})
+
+	t.Run("error: target not covered by the root capability", func(t *testing.T) {
+		err := verify(t, delegated(t), listed, other)
+		require.True(t, errors.Is(err, zcapld.ErrUnauthorizedTarget))
+
+		unauthorized := &zcapld.UnauthorizedTargetError{}
+		require.True(t, errors.As(err, &unauthorized))
+		require.Equal(t, other, unauthorized.Target)
+		require.Equal(t, root.ID, unauthorized.CapabilityID)
+	})
+
+	t.Run("error: target not covered by the delegated capability", func(t *testing.T) {
+		c := delegated(t, withCaveats(caveat(t, &zcapld.AdditionalTargetsCaveat{
+			Type: zcapld.CaveatTypeAdditionalTargets,
+		})))
+
+		err := verify(t, c, listed)
+
+		unauthorized := &zcapld.UnauthorizedTargetError{}
+		require.True(t, errors.As(err, &unauthorized))
+		require.Equal(t, c.ID, unauthorized.CapabilityID)
+	})
+
+	t.Run("error: target not covered by a root capability without the caveat", func(t *testing.T) {
+		c, sig := selfSignedSelfInvokingRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{c.ID: c},
+			zcapld.SimpleKeyResolver{
+				keyID(sig): keyValue(t, sig),
+			},
+			zcapld.WithCaveats(zcapld.CaveatRegistry{}),
+		)
+		inv := invocation(c.Invoker, expectRootCapability(c.ID))
+		inv.AdditionalTargets = []string{listed}
+
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         c,
+				CapabilityAction:   "read",
+				VerificationMethod: c.Invoker,
+			},
+			inv,
+		)
+		require.True(t, errors.Is(err, zcapld.ErrUnauthorizedTarget))
+	})
+}
@@ -0,0 +1,134 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+// cborCapability is the compact form of a Capability. Its fields have the names of the JSON-LD terms, except for
+// the @context: it is dropped if it is the SecurityContextV2, which is restored when decoding. Caveats are kept as
+// their JSON encoding so they roundtrip byte for byte.
+type cborCapability struct {
+	Context          string                   `cbor:"@context,omitempty"`
+	ID               string                   `cbor:"id"`
+	Invoker          string                   `cbor:"invoker,omitempty"`
+	Controller       string                   `cbor:"controller,omitempty"`
+	Delegator        string                   `cbor:"delegator,omitempty"`
+	Parent           string                   `cbor:"parentCapability,omitempty"`
+	AllowedAction    []string                 `cbor:"allowedAction,omitempty"`
+	Audience         []string                 `cbor:"audience,omitempty"`
+	InvocationTarget cborInvocationTarget     `cbor:"invocationTarget"`
+	ExpiresAt        string                   `cbor:"expires,omitempty"`
+	Caveats          [][]byte                 `cbor:"caveat,omitempty"`
+	Proof            []map[string]interface{} `cbor:"proof,omitempty"`
+}
+
+type cborInvocationTarget struct {
+	ID   string `cbor:"id"`
+	Type string `cbor:"type,omitempty"`
+}
+
+// MarshalCBOR encodes the capability in the compact CBOR form, with the same field names as its JSON-LD form.
+// The @context is dropped if it is the SecurityContextV2.
+func MarshalCBOR(c *Capability) ([]byte, error) {
+	if c == nil {
+		return nil, errors.New("no capability to marshal to cbor")
+	}
+
+	compact := &cborCapability{
+		ID:            c.ID,
+		Invoker:       c.Invoker,
+		Controller:    c.Controller,
+		Delegator:     c.Delegator,
+		Parent:        c.Parent,
+		AllowedAction: c.AllowedAction,
+		Audience:      c.Audience,
+		InvocationTarget: cborInvocationTarget{
+			ID:   c.InvocationTarget.ID,
+			Type: c.InvocationTarget.Type,
+		},
+		ExpiresAt: c.ExpiresAt,
+	}
+
+	if c.Context != SecurityContextV2 {
+		compact.Context = c.Context
+	}
+
+	for i := range c.Caveats {
+		compact.Caveats = append(compact.Caveats, c.Caveats[i])
+	}
+
+	for i := range c.Proof {
+		compact.Proof = append(compact.Proof, c.Proof[i])
+	}
+
+	mode, err := cbor.CanonicalEncOptions().EncMode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to init cbor encoder: %w", err)
+	}
+
+	data, err := mode.Marshal(compact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal capability to cbor: %w", err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalCBOR decodes a capability encoded by MarshalCBOR. The @context defaults to the SecurityContextV2.
+func UnmarshalCBOR(data []byte) (*Capability, error) {
+	mode, err := cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+	}.DecMode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to init cbor decoder: %w", err)
+	}
+
+	compact := &cborCapability{}
+
+	err = mode.Unmarshal(data, compact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal capability from cbor: %w", err)
+	}
+
+	c := &Capability{
+		Context:       compact.Context,
+		ID:            compact.ID,
+		Invoker:       compact.Invoker,
+		Controller:    compact.Controller,
+		Delegator:     compact.Delegator,
+		Parent:        compact.Parent,
+		AllowedAction: compact.AllowedAction,
+		Audience:      compact.Audience,
+		InvocationTarget: InvocationTarget{
+			ID:   compact.InvocationTarget.ID,
+			Type: compact.InvocationTarget.Type,
+		},
+		ExpiresAt: compact.ExpiresAt,
+	}
+
+	if c.Context == "" {
+		c.Context = SecurityContextV2
+	}
+
+	for i := range compact.Caveats {
+		c.Caveats = append(c.Caveats, json.RawMessage(compact.Caveats[i]))
+	}
+
+	for i := range compact.Proof {
+		c.Proof = append(c.Proof, verifiable.Proof(compact.Proof[i]))
+	}
+
+	return c, nil
+}
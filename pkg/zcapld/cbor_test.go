@@ -0,0 +1,93 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestCBOR(t *testing.T) {
+	t.Run("success: roundtrip", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		zcap := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(rootSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}), withAllowedActions("read", "write"),
+			withAudience("https://example.com"), withExpiresAt(time.Now().Add(time.Hour).UTC()),
+			withCaveats(caveat(t, &zcapld.AllowedActionCaveat{
+				Type:          zcapld.CaveatTypeAllowedAction,
+				AllowedAction: []string{"read"},
+			})))
+
+		data, err := zcapld.MarshalCBOR(zcap)
+		require.NoError(t, err)
+		require.Less(t, len(data), len(marshal(t, zcap)))
+
+		result, err := zcapld.UnmarshalCBOR(data)
+		require.NoError(t, err)
+		require.True(t, zcapld.Equal(zcap, result))
+
+		err = verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         result,
+				CapabilityAction:   "read",
+				VerificationMethod: result.Invoker,
+			},
+			invocation(result.Invoker, expectRootCapability(root.ID), expectAudience("https://example.com")),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("success: drops the default context", func(t *testing.T) {
+		data, err := zcapld.MarshalCBOR(&zcapld.Capability{Context: zcapld.SecurityContextV2, ID: "urn:zcap:1"})
+		require.NoError(t, err)
+
+		fields := make(map[string]interface{})
+		require.NoError(t, cbor.Unmarshal(data, &fields))
+		require.NotContains(t, fields, "@context")
+		require.Equal(t, "urn:zcap:1", fields["id"])
+	})
+
+	t.Run("success: keeps other contexts", func(t *testing.T) {
+		zcap := &zcapld.Capability{Context: "https://example.com/context", ID: "urn:zcap:1"}
+
+		data, err := zcapld.MarshalCBOR(zcap)
+		require.NoError(t, err)
+
+		result, err := zcapld.UnmarshalCBOR(data)
+		require.NoError(t, err)
+		require.Equal(t, zcap, result)
+	})
+
+	t.Run("error: nil capability", func(t *testing.T) {
+		_, err := zcapld.MarshalCBOR(nil)
+		require.EqualError(t, err, "no capability to marshal to cbor")
+	})
+
+	t.Run("error: invalid cbor", func(t *testing.T) {
+		data, err := json.Marshal("not a capability")
+		require.NoError(t, err)
+
+		_, err = zcapld.UnmarshalCBOR(data)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal capability from cbor")
+	})
+}
@@ -0,0 +1,48 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// challengeSize is the number of random bytes in a challenge.
+const challengeSize = 32
+
+// Challenge returns a new challenge for an invoker to prove possession of its key with, read from 'rand'
+// (eg. crypto/rand.Reader) and base64url-encoded.
+func Challenge(rand io.Reader) (string, error) {
+	b := make([]byte, challengeSize)
+
+	_, err := io.ReadFull(rand, b)
+	if err != nil {
+		return "", fmt.Errorf("failed to read challenge: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// VerifyChallenge ensures the proof carries the expected challenge.
+func VerifyChallenge(proof *Proof, expectedChallenge string) error {
+	if expectedChallenge == "" {
+		return errors.New("expected challenge is required")
+	}
+
+	if proof == nil || proof.Challenge == "" {
+		return errors.New("the proof has no challenge")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(proof.Challenge), []byte(expectedChallenge)) != 1 {
+		return errors.New("the proof's challenge does not match the expected challenge")
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestChallenge(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		first, err := zcapld.Challenge(rand.Reader)
+		require.NoError(t, err)
+
+		second, err := zcapld.Challenge(rand.Reader)
+		require.NoError(t, err)
+		require.NotEqual(t, first, second)
+
+		raw, err := base64.RawURLEncoding.DecodeString(first)
+		require.NoError(t, err)
+		require.Len(t, raw, 32)
+	})
+
+	t.Run("error: not enough randomness", func(t *testing.T) {
+		_, err := zcapld.Challenge(bytes.NewReader([]byte("short")))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read challenge")
+	})
+}
+
+func TestVerifyChallenge(t *testing.T) {
+	challenge, err := zcapld.Challenge(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		require.NoError(t, zcapld.VerifyChallenge(&zcapld.Proof{Challenge: challenge}, challenge))
+	})
+
+	t.Run("error: challenge does not match", func(t *testing.T) {
+		err := zcapld.VerifyChallenge(&zcapld.Proof{Challenge: challenge + "x"}, challenge)
+		require.EqualError(t, err, "the proof's challenge does not match the expected challenge")
+	})
+
+	t.Run("error: proof has no challenge", func(t *testing.T) {
+		require.EqualError(t, zcapld.VerifyChallenge(&zcapld.Proof{}, challenge), "the proof has no challenge")
+		require.EqualError(t, zcapld.VerifyChallenge(nil, challenge), "the proof has no challenge")
+	})
+
+	t.Run("error: no expected challenge", func(t *testing.T) {
+		err := zcapld.VerifyChallenge(&zcapld.Proof{Challenge: challenge}, "")
+		require.EqualError(t, err, "expected challenge is required")
+	})
+}
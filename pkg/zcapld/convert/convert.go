@@ -0,0 +1,101 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package convert converts capability documents between the versions of the ZCAP-LD schema.
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Versions of the ZCAP-LD schema. Version 1 names the fields as the zcapld package serializes them.
+const (
+	V1 = 1
+	V2 = 2
+)
+
+// renames maps the fields of the capabilities of one schema version to their names in the other.
+type renames map[string]string
+
+// v1ToV2 are the fields renamed from version 1 to version 2 of the schema.
+var v1ToV2 = renames{ // nolint:gochecknoglobals // read-only table
+	"caveat":  "caveats",
+	"invoker": "invokers",
+}
+
+// converters of the capabilities between schema versions, keyed by [fromVersion, toVersion].
+var converters = map[[2]int]renames{ // nolint:gochecknoglobals // read-only table
+	{V1, V2}: v1ToV2,
+	{V2, V1}: v1ToV2.inverse(),
+}
+
+// Convert the capability document from one schema version to the other by renaming its fields. The values of the
+// fields are left as they are. A document is returned as is if both versions are the same.
+func Convert(doc []byte, fromVersion, toVersion int) ([]byte, error) {
+	if fromVersion == toVersion && (fromVersion == V1 || fromVersion == V2) {
+		return doc, nil
+	}
+
+	fields, ok := converters[[2]int{fromVersion, toVersion}]
+	if !ok {
+		return nil, fmt.Errorf("unsupported conversion from schema version %d to %d", fromVersion, toVersion)
+	}
+
+	capability := make(map[string]json.RawMessage)
+
+	err := json.Unmarshal(doc, &capability)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal capability: %w", err)
+	}
+
+	err = fields.apply(capability)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert from schema version %d to %d: %w", fromVersion, toVersion, err)
+	}
+
+	result, err := json.Marshal(capability)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal capability: %w", err)
+	}
+
+	return result, nil
+}
+
+// apply renames the fields of the capability. A field is not renamed over another field of the capability.
+func (r renames) apply(capability map[string]json.RawMessage) error {
+	for from, to := range r {
+		if _, ok := capability[from]; !ok {
+			continue
+		}
+
+		if _, ok := capability[to]; ok {
+			return fmt.Errorf("capability has both the %s and %s fields", from, to)
+		}
+	}
+
+	for from, to := range r {
+		value, ok := capability[from]
+		if !ok {
+			continue
+		}
+
+		delete(capability, from)
+		capability[to] = value
+	}
+
+	return nil
+}
+
+func (r renames) inverse() renames {
+	inverse := make(renames, len(r))
+
+	for from, to := range r {
+		inverse[to] = from
+	}
+
+	return inverse
+}
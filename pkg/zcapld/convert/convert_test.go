@@ -0,0 +1,110 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package convert_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/convert"
+)
+
+const v1Doc = `{
+	"@context": "https://w3id.org/security/v2",
+	"id": "urn:zcap:123",
+	"invoker": "did:example:123",
+	"allowedAction": ["read"],
+	"invocationTarget": {"ID": "urn:target", "Type": "urn:edv:document"},
+	"caveat": [{"type": "zcap:ExpiryCaveat", "expires": "2030-01-01T00:00:00Z"}]
+}`
+
+const v2Doc = `{
+	"@context": "https://w3id.org/security/v2",
+	"id": "urn:zcap:123",
+	"invokers": "did:example:123",
+	"allowedAction": ["read"],
+	"invocationTarget": {"ID": "urn:target", "Type": "urn:edv:document"},
+	"caveats": [{"type": "zcap:ExpiryCaveat", "expires": "2030-01-01T00:00:00Z"}]
+}`
+
+func TestConvert(t *testing.T) {
+	t.Run("v1 to v2", func(t *testing.T) {
+		result, err := convert.Convert([]byte(v1Doc), convert.V1, convert.V2)
+		require.NoError(t, err)
+		require.JSONEq(t, v2Doc, string(result))
+	})
+
+	t.Run("v2 to v1", func(t *testing.T) {
+		result, err := convert.Convert([]byte(v2Doc), convert.V2, convert.V1)
+		require.NoError(t, err)
+		require.JSONEq(t, v1Doc, string(result))
+	})
+
+	t.Run("roundtrip", func(t *testing.T) {
+		v2, err := convert.Convert([]byte(v1Doc), convert.V1, convert.V2)
+		require.NoError(t, err)
+
+		v1, err := convert.Convert(v2, convert.V2, convert.V1)
+		require.NoError(t, err)
+		require.JSONEq(t, v1Doc, string(v1))
+
+		back, err := convert.Convert(v1, convert.V1, convert.V2)
+		require.NoError(t, err)
+		require.JSONEq(t, string(v2), string(back))
+	})
+
+	t.Run("roundtrip: capability of the zcapld package", func(t *testing.T) {
+		doc, err := json.Marshal(&zcapld.Capability{
+			Context:          zcapld.SecurityContextV2,
+			ID:               "urn:zcap:123",
+			Invoker:          "did:example:123",
+			InvocationTarget: zcapld.InvocationTarget{ID: "urn:target"},
+			Caveats:          []json.RawMessage{json.RawMessage(`{"type":"zcap:ExpiryCaveat"}`)},
+		})
+		require.NoError(t, err)
+
+		v2, err := convert.Convert(doc, convert.V1, convert.V2)
+		require.NoError(t, err)
+
+		v1, err := convert.Convert(v2, convert.V2, convert.V1)
+		require.NoError(t, err)
+
+		result := &zcapld.Capability{}
+		require.NoError(t, json.Unmarshal(v1, result))
+		require.Equal(t, "did:example:123", result.Invoker)
+		require.Len(t, result.Caveats, 1)
+	})
+
+	t.Run("same version", func(t *testing.T) {
+		result, err := convert.Convert([]byte(v1Doc), convert.V1, convert.V1)
+		require.NoError(t, err)
+		require.Equal(t, v1Doc, string(result))
+	})
+
+	t.Run("error: unsupported versions", func(t *testing.T) {
+		_, err := convert.Convert([]byte(v1Doc), convert.V1, 3)
+		require.EqualError(t, err, "unsupported conversion from schema version 1 to 3")
+
+		_, err = convert.Convert([]byte(v1Doc), 3, 3)
+		require.EqualError(t, err, "unsupported conversion from schema version 3 to 3")
+	})
+
+	t.Run("error: invalid document", func(t *testing.T) {
+		_, err := convert.Convert([]byte("{"), convert.V1, convert.V2)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal capability")
+	})
+
+	t.Run("error: both field names", func(t *testing.T) {
+		_, err := convert.Convert([]byte(`{"caveat":[],"caveats":[]}`), convert.V1, convert.V2)
+		require.EqualError(t, err,
+			"failed to convert from schema version 1 to 2: capability has both the caveat and caveats fields")
+	})
+}
@@ -0,0 +1,42 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import "sync"
+
+// MaxInvocationsCounter counts the invocations of capabilities with a MaxInvocationsCaveat.
+//
+// Increment must be atomic: concurrent calls for the same capability must each return a distinct count, or the
+// capability may be invoked more times than its caveat allows. Implementations shared by several instances of a
+// service should keep the counts in a common store, eg. with Redis INCR on a key derived from the capability ID,
+// setting its expiry to the capability's if it has one.
+type MaxInvocationsCounter interface {
+	// Increment the number of invocations of the capability, returning the new count.
+	Increment(capabilityID string) (int64, error)
+}
+
+// AtomicInMemoryCounter is an in-memory MaxInvocationsCounter that is safe for concurrent use. It is meant for tests
+// and single instance deployments: its counts are lost on restart and are never reclaimed.
+type AtomicInMemoryCounter struct {
+	mutex  sync.Mutex
+	counts map[string]int64
+}
+
+// NewAtomicInMemoryCounter returns a new AtomicInMemoryCounter with no invocations counted.
+func NewAtomicInMemoryCounter() *AtomicInMemoryCounter {
+	return &AtomicInMemoryCounter{counts: make(map[string]int64)}
+}
+
+// Increment the number of invocations of the capability, returning the new count.
+func (a *AtomicInMemoryCounter) Increment(capabilityID string) (int64, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.counts[capabilityID]++
+
+	return a.counts[capabilityID], nil
+}
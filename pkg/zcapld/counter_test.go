@@ -0,0 +1,181 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestAtomicInMemoryCounter(t *testing.T) {
+	t.Run("counts invocations per capability", func(t *testing.T) {
+		counter := zcapld.NewAtomicInMemoryCounter()
+
+		for i := int64(1); i <= 3; i++ {
+			n, err := counter.Increment("urn:zcap:1")
+			require.NoError(t, err)
+			require.Equal(t, i, n)
+		}
+
+		n, err := counter.Increment("urn:zcap:2")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+	})
+
+	t.Run("concurrent increments return distinct counts", func(t *testing.T) {
+		const n = 100
+
+		counter := zcapld.NewAtomicInMemoryCounter()
+		counts := make(chan int64, n)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				count, err := counter.Increment("urn:zcap:1")
+				require.NoError(t, err)
+
+				counts <- count
+			}()
+		}
+
+		wg.Wait()
+		close(counts)
+
+		seen := make(map[int64]bool)
+		for count := range counts {
+			seen[count] = true
+		}
+
+		require.Len(t, seen, n)
+	})
+}
+
+func TestMaxInvocationsCaveat_Verify(t *testing.T) {
+	t.Run("error: no counter", func(t *testing.T) {
+		c := &zcapld.MaxInvocationsCaveat{Type: zcapld.CaveatTypeMaxInvocations, Limit: 1}
+		err := c.Verify(&zcapld.CapabilityInvocation{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no counter of invocations to verify the caveat")
+	})
+}
+
+func TestVerifier_MaxInvocations(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	invokerSigner := testSigner(t, kms.ED25519)
+	capability := capability(t,
+		rootSigner, ed25519signature2018.SignatureType,
+		withInvoker(keyID(invokerSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+		withCapabilityChain([]interface{}{root.ID}),
+		withCaveats(caveat(t, &zcapld.MaxInvocationsCaveat{
+			Type:  zcapld.CaveatTypeMaxInvocations,
+			Limit: 2,
+		})))
+	verify := func(v *zcapld.Verifier) error {
+		return v.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("error: capability invoked more times than the limit", func(t *testing.T) {
+		counter := zcapld.NewAtomicInMemoryCounter()
+		v := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithMaxInvocationsCounter(counter),
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+		)
+
+		require.NoError(t, verify(v))
+		require.NoError(t, verify(v))
+
+		err := verify(v)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrMaxInvocationsExceeded))
+		require.Contains(t, err.Error(), "caveat sec:MaxInvocationsCaveat not met on capability "+capability.ID)
+		require.Contains(t, err.Error(), "invoked 3 times; limit is 2")
+	})
+
+	t.Run("success: invalid invocations are not counted", func(t *testing.T) {
+		counter := zcapld.NewAtomicInMemoryCounter()
+		v := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithMaxInvocationsCounter(counter),
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+		)
+		intruder := keyID(testSigner(t, kms.ED25519))
+
+		for i := 0; i < 3; i++ {
+			err := v.Verify(
+				context.Background(),
+				&zcapld.Proof{
+					Capability:         capability,
+					CapabilityAction:   "read",
+					VerificationMethod: intruder,
+				},
+				invocation(intruder, expectRootCapability(root.ID)),
+			)
+			require.Error(t, err)
+			require.False(t, errors.Is(err, zcapld.ErrMaxInvocationsExceeded))
+		}
+
+		require.NoError(t, verify(v))
+		require.NoError(t, verify(v))
+		require.True(t, errors.Is(verify(v), zcapld.ErrMaxInvocationsExceeded))
+	})
+
+	t.Run("error: counter fails", func(t *testing.T) {
+		v := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithMaxInvocationsCounter(&mockCounter{err: errors.New("test")}),
+		)
+
+		err := verify(v)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to count invocation of capability "+capability.ID+": test")
+	})
+
+	t.Run("error: no counter", func(t *testing.T) {
+		v := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+		)
+
+		err := verify(v)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported caveat type on capability "+capability.ID)
+	})
+}
+
+type mockCounter struct {
+	err error
+}
+
+func (m *mockCounter) Increment(string) (int64, error) {
+	return 0, m.err
+}
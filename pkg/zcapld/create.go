@@ -39,7 +39,10 @@ type CapabilityOptions struct {
 	Controller       string
 	Delegator        string
 	AllowedAction    []string
+	Audience         []string
 	InvocationTarget InvocationTarget
+	ExpiresAt        string
+	Caveats          []interface{}
 	Challenge        string
 	Domain           string
 	CapabilityChain  []interface{}
@@ -90,6 +93,13 @@ func WithAllowedActions(actions ...string) CapabilityOption {
 	}
 }
 
+// WithAudience restricts the capability to the audiences, eg. the services that may accept its invocations.
+func WithAudience(audience ...string) CapabilityOption {
+	return func(o *CapabilityOptions) {
+		o.Audience = audience
+	}
+}
+
 // WithInvocationTarget sets the invocation target on the Capability.
 func WithInvocationTarget(targetID, targetType string) CapabilityOption {
 	return func(o *CapabilityOptions) {
@@ -100,6 +110,20 @@ func WithInvocationTarget(targetID, targetType string) CapabilityOption {
 	}
 }
 
+// WithExpiresAt sets the time after which the Capability is no longer valid.
+func WithExpiresAt(expires time.Time) CapabilityOption {
+	return func(o *CapabilityOptions) {
+		o.ExpiresAt = expires.UTC().Format(time.RFC3339)
+	}
+}
+
+// WithCaveat adds a caveat to the Capability. The caveat must marshal to a JSON object with a "type".
+func WithCaveat(caveat interface{}) CapabilityOption {
+	return func(o *CapabilityOptions) {
+		o.Caveats = append(o.Caveats, caveat)
+	}
+}
+
 // WithChallenge sets the challenge to include in the proof.
 func WithChallenge(c string) CapabilityOption {
 	return func(o *CapabilityOptions) {
@@ -129,6 +153,8 @@ type Signer struct {
 }
 
 // NewCapability constructs a new, signed Capability with the options provided.
+// The Capability's context is set to the security v2 context. An error is returned if the options do not
+// describe a valid Capability, eg. no invocation target is set with WithInvocationTarget.
 func NewCapability(signer *Signer, options ...CapabilityOption) (*Capability, error) {
 	if signer == nil {
 		return nil, errors.New("must provide a signer")
@@ -142,6 +168,16 @@ func NewCapability(signer *Signer, options ...CapabilityOption) (*Capability, er
 		options[i](opts)
 	}
 
+	err := validateCapabilityOptions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid capability options: %w", err)
+	}
+
+	caveats, err := marshalCaveats(opts.Caveats)
+	if err != nil {
+		return nil, err
+	}
+
 	zcap := &Capability{
 		Context:          SecurityContextV2,
 		ID:               opts.ID,
@@ -150,17 +186,64 @@ func NewCapability(signer *Signer, options ...CapabilityOption) (*Capability, er
 		Delegator:        opts.Delegator,
 		Parent:           opts.Parent,
 		AllowedAction:    opts.AllowedAction,
+		Audience:         opts.Audience,
 		InvocationTarget: opts.InvocationTarget,
+		ExpiresAt:        opts.ExpiresAt,
+		Caveats:          caveats,
 	}
 
-	err := signZCAP(zcap, signer, opts)
+	err = signZCAP(zcap, signer, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign zcap: %w", err)
 	}
 
+	err = zcap.validateCapabilityChain()
+	if err != nil {
+		return nil, fmt.Errorf("invalid capability chain: %w", err)
+	}
+
 	return zcap, nil
 }
 
+func validateCapabilityOptions(opts *CapabilityOptions) error {
+	if opts.ID == "" {
+		return errors.New("capability ID is required")
+	}
+
+	if opts.InvocationTarget.ID == "" {
+		return errors.New("invocation target ID is required")
+	}
+
+	if opts.Parent == "" && len(opts.CapabilityChain) > 0 {
+		return errors.New("a root capability must not have a capabilityChain")
+	}
+
+	if opts.Parent != "" && len(opts.CapabilityChain) == 0 {
+		return errors.New("a delegated capability must have a capabilityChain")
+	}
+
+	return nil
+}
+
+func marshalCaveats(caveats []interface{}) ([]json.RawMessage, error) {
+	if len(caveats) == 0 {
+		return nil, nil
+	}
+
+	raw := make([]json.RawMessage, len(caveats))
+
+	for i := range caveats {
+		bits, err := json.Marshal(caveats[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal caveat: %w", err)
+		}
+
+		raw[i] = bits
+	}
+
+	return raw, nil
+}
+
 func signZCAP(zcap *Capability, signer *Signer, options *CapabilityOptions) error {
 	raw, err := json.Marshal(zcap)
 	if err != nil {
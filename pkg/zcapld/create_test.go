@@ -9,6 +9,7 @@ package zcapld_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
@@ -58,20 +59,22 @@ func TestParseCapability(t *testing.T) {
 }
 
 func TestNewCapability(t *testing.T) {
+	target := zcapld.WithInvocationTarget("urn:edv:document:123", "urn:edv:document")
+
 	t.Run("creates new capability with proof", func(t *testing.T) {
 		expected := &zcapld.Capability{
-			ID:            uuid.New().String(),
-			Invoker:       uuid.New().String(),
-			Controller:    uuid.New().String(),
-			Delegator:     uuid.New().String(),
-			Parent:        uuid.New().String(),
+			ID:            fmt.Sprintf("urn:uuid:%s", uuid.New().String()),
+			Invoker:       fmt.Sprintf("urn:uuid:%s", uuid.New().String()),
+			Controller:    fmt.Sprintf("urn:uuid:%s", uuid.New().String()),
+			Delegator:     fmt.Sprintf("urn:uuid:%s", uuid.New().String()),
+			Parent:        fmt.Sprintf("urn:uuid:%s", uuid.New().String()),
 			AllowedAction: []string{uuid.New().String()},
 			InvocationTarget: zcapld.InvocationTarget{
-				ID:   uuid.New().String(),
+				ID:   fmt.Sprintf("urn:uuid:%s", uuid.New().String()),
 				Type: uuid.New().String(),
 			},
 		}
-		capabilityChain := []interface{}{fmt.Sprintf("urn:zcap:%s", uuid.New().String())}
+		capabilityChain := []interface{}{fmt.Sprintf("urn:zcap:%s", uuid.New().String()), expected.Parent}
 		signer := testSigner(t, kms.ED25519)
 		challenge := uuid.New().String()
 		domain := uuid.New().String()
@@ -118,18 +121,54 @@ func TestNewCapability(t *testing.T) {
 			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
 			SuiteType:          ed25519signature2018.SignatureType,
 			VerificationMethod: keyID(signer),
-		})
+		}, target)
 		require.NoError(t, err)
 		require.NotEmpty(t, result.ID)
 	})
 
+	t.Run("sets expiry", func(t *testing.T) {
+		signer := testSigner(t, kms.ED25519)
+		expires := time.Now().Add(time.Hour)
+		result, err := zcapld.NewCapability(
+			&zcapld.Signer{
+				SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
+				SuiteType:          ed25519signature2018.SignatureType,
+				VerificationMethod: keyID(signer),
+			},
+			target,
+			zcapld.WithExpiresAt(expires),
+		)
+		require.NoError(t, err)
+		require.Equal(t, expires.UTC().Format(time.RFC3339), result.ExpiresAt)
+	})
+
+	t.Run("sets caveats", func(t *testing.T) {
+		signer := testSigner(t, kms.ED25519)
+		caveat := &zcapld.AllowedActionCaveat{
+			Type:          zcapld.CaveatTypeAllowedAction,
+			AllowedAction: []string{"read"},
+		}
+		result, err := zcapld.NewCapability(
+			&zcapld.Signer{
+				SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
+				SuiteType:          ed25519signature2018.SignatureType,
+				VerificationMethod: keyID(signer),
+			},
+			target,
+			zcapld.WithCaveat(caveat),
+		)
+		require.NoError(t, err)
+		require.Len(t, result.Caveats, 1)
+		require.JSONEq(t, string(marshal(t, caveat)), string(result.Caveats[0]))
+	})
+
 	t.Run("proof is verifiable", func(t *testing.T) {
 		signer := testSigner(t, kms.ED25519)
 		zcap, err := zcapld.NewCapability(&zcapld.Signer{
 			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
 			SuiteType:          ed25519signature2018.SignatureType,
 			VerificationMethod: keyID(signer),
-		})
+		}, target)
 		require.NoError(t, err)
 		ver, err := ariesver.New(
 			zcapld.SimpleKeyResolver{keyID(signer): keyValue(t, signer)},
@@ -146,13 +185,84 @@ func TestNewCapability(t *testing.T) {
 		require.Contains(t, err.Error(), "must provide a signer")
 	})
 
+	t.Run("error: empty ID", func(t *testing.T) {
+		signer := testSigner(t, kms.ED25519)
+		_, err := zcapld.NewCapability(&zcapld.Signer{
+			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
+			SuiteType:          ed25519signature2018.SignatureType,
+			VerificationMethod: keyID(signer),
+		}, target, zcapld.WithID(""))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "capability ID is required")
+	})
+
+	t.Run("error: empty invocation target ID", func(t *testing.T) {
+		signer := testSigner(t, kms.ED25519)
+		_, err := zcapld.NewCapability(&zcapld.Signer{
+			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
+			SuiteType:          ed25519signature2018.SignatureType,
+			VerificationMethod: keyID(signer),
+		}, zcapld.WithInvocationTarget("", "urn:edv:document"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invocation target ID is required")
+	})
+
+	t.Run("error: root capability with a capabilityChain", func(t *testing.T) {
+		signer := testSigner(t, kms.ED25519)
+		_, err := zcapld.NewCapability(&zcapld.Signer{
+			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
+			SuiteType:          ed25519signature2018.SignatureType,
+			VerificationMethod: keyID(signer),
+		}, target, zcapld.WithCapabilityChain("urn:zcap:root"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "a root capability must not have a capabilityChain")
+	})
+
+	t.Run("error: delegated capability without a capabilityChain", func(t *testing.T) {
+		signer := testSigner(t, kms.ED25519)
+		_, err := zcapld.NewCapability(&zcapld.Signer{
+			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
+			SuiteType:          ed25519signature2018.SignatureType,
+			VerificationMethod: keyID(signer),
+		}, target, zcapld.WithParent("urn:zcap:root"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "a delegated capability must have a capabilityChain")
+	})
+
+	t.Run("error: capabilityChain contains a cycle", func(t *testing.T) {
+		signer := testSigner(t, kms.ED25519)
+		_, err := zcapld.NewCapability(&zcapld.Signer{
+			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
+			SuiteType:          ed25519signature2018.SignatureType,
+			VerificationMethod: keyID(signer),
+		},
+			target,
+			zcapld.WithID("urn:zcap:child"),
+			zcapld.WithParent("urn:zcap:root"),
+			zcapld.WithCapabilityChain("urn:zcap:child", "urn:zcap:root"),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "the capability chain contains a cycle")
+	})
+
+	t.Run("error: caveat cannot be marshalled", func(t *testing.T) {
+		signer := testSigner(t, kms.ED25519)
+		_, err := zcapld.NewCapability(&zcapld.Signer{
+			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
+			SuiteType:          ed25519signature2018.SignatureType,
+			VerificationMethod: keyID(signer),
+		}, target, zcapld.WithCaveat(make(chan int)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to marshal caveat")
+	})
+
 	t.Run("error: fails if signature suites are not provided", func(t *testing.T) {
 		signer := testSigner(t, kms.ED25519)
 		_, err := zcapld.NewCapability(&zcapld.Signer{
 			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
 			SuiteType:          "",
 			VerificationMethod: keyID(signer),
-		})
+		}, target)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "signature type is missing")
 	})
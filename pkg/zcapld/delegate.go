@@ -0,0 +1,166 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+// DelegateCapability returns a new, unsigned capability delegated from 'parent' to 'invoker'. Sign it with
+// SignCapability.
+//
+// The capability gets a new urn:uuid ID, the invocation target of the parent, and the capability chain of the parent
+// followed by the parent itself; options setting these are ignored. The allowed actions default to the parent's.
+// Use WithAllowedActions, WithExpiresAt, WithAudience, and WithCaveat to restrict the capability further.
+// An error wrapping ErrActionNotAllowed is returned if the allowed actions are not a subset of the parent's.
+// The capability is checked with ValidateSemantics.
+func DelegateCapability(parent *Capability, invoker string, options ...CapabilityOption) (*Capability, error) {
+	if parent == nil {
+		return nil, errors.New("parent capability is required")
+	}
+
+	if invoker == "" {
+		return nil, errors.New("invoker is required")
+	}
+
+	chain, err := parent.delegationChain()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build capability chain from parent %s: %w", parent.ID, err)
+	}
+
+	opts := delegationOptions(parent, invoker)
+
+	for i := range options {
+		options[i](opts)
+	}
+
+	return delegate(parent, opts, chain)
+}
+
+// delegationOptions are the default options of capabilities delegated from the parent to the invoker.
+func delegationOptions(parent *Capability, invoker string) *CapabilityOptions {
+	return &CapabilityOptions{
+		ID:            fmt.Sprintf("urn:uuid:%s", uuid.New().String()),
+		Invoker:       invoker,
+		AllowedAction: append([]string{}, parent.AllowedAction...),
+	}
+}
+
+// delegate returns the unsigned capability delegated from the parent with the options and capability chain.
+func delegate(parent *Capability, opts *CapabilityOptions, chain []interface{}) (*Capability, error) {
+	opts.Parent = parent.ID
+	opts.InvocationTarget = parent.InvocationTarget
+	opts.CapabilityChain = chain
+
+	err := validateDelegatedActions(parent, opts.AllowedAction)
+	if err != nil {
+		return nil, err
+	}
+
+	caveats, err := marshalCaveats(opts.Caveats)
+	if err != nil {
+		return nil, err
+	}
+
+	zcap := &Capability{
+		Context:          SecurityContextV2,
+		ID:               opts.ID,
+		Invoker:          opts.Invoker,
+		Controller:       opts.Controller,
+		Delegator:        opts.Delegator,
+		Parent:           opts.Parent,
+		AllowedAction:    opts.AllowedAction,
+		Audience:         opts.Audience,
+		InvocationTarget: opts.InvocationTarget,
+		ExpiresAt:        opts.ExpiresAt,
+		Caveats:          caveats,
+		// the unsigned delegation proof carries the capability chain until the capability is signed
+		Proof: []verifiable.Proof{{
+			proofPurposeField:         ProofPurpose,
+			proofCapabilityChainField: chain,
+		}},
+	}
+
+	err = zcap.ValidateSemantics()
+	if err != nil {
+		return nil, fmt.Errorf("invalid delegated capability: %w", err)
+	}
+
+	return zcap, nil
+}
+
+// SignCapability signs the capability, replacing its proofs with a delegation proof over its capability chain.
+// Unsigned capabilities returned by DelegateCapability are signed with the chain they were delegated with.
+func SignCapability(zcap *Capability, signer *Signer) error {
+	if signer == nil {
+		return errors.New("must provide a signer")
+	}
+
+	chain, err := zcap.capabilityChain()
+	if err != nil {
+		return fmt.Errorf("failed to get capabilityChain: %w", err)
+	}
+
+	unsigned := Clone(zcap)
+	unsigned.Proof = nil
+
+	err = signZCAP(unsigned, signer, &CapabilityOptions{CapabilityChain: chain})
+	if err != nil {
+		return fmt.Errorf("failed to sign zcap: %w", err)
+	}
+
+	err = unsigned.validateCapabilityChain()
+	if err != nil {
+		return fmt.Errorf("invalid capability chain: %w", err)
+	}
+
+	zcap.Proof = unsigned.Proof
+
+	return nil
+}
+
+// delegationChain is the capability chain of capabilities delegated from this one. Its entries are all URIs.
+func (c *Capability) delegationChain() ([]interface{}, error) {
+	chain, err := c.capabilityChain()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capabilityChain: %w", err)
+	}
+
+	ids := make([]interface{}, 0, len(chain)+1)
+
+	for i := range chain {
+		// only the last entry of the chain may be an embedded capability
+		ids = append(ids, parentID(chain[i]))
+	}
+
+	return append(ids, c.ID), nil
+}
+
+// validateDelegatedActions ensures the actions are a subset of those allowed by the parent.
+func validateDelegatedActions(parent *Capability, actions []string) error {
+	if len(parent.AllowedAction) == 0 || stringsContain(parent.AllowedAction, AllowedActionWildcard) {
+		return nil
+	}
+
+	if len(actions) == 0 {
+		return fmt.Errorf("%w: the delegated capability must restrict its actions to those of parent %s: %+v",
+			ErrActionNotAllowed, parent.ID, parent.AllowedAction)
+	}
+
+	for _, action := range actions {
+		if !stringsContain(parent.AllowedAction, action) {
+			return fmt.Errorf(`%w: action "%s" is not allowed by parent capability %s; allowed actions are: %+v`,
+				ErrActionNotAllowed, action, parent.ID, parent.AllowedAction)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestDelegateCapability(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	invoker := keyID(testSigner(t, kms.ED25519))
+
+	t.Run("success: delegates and signs a capability that verifies", func(t *testing.T) {
+		expires := time.Now().Add(time.Hour)
+
+		zcap, err := zcapld.DelegateCapability(root, invoker,
+			zcapld.WithAllowedActions("read"),
+			zcapld.WithExpiresAt(expires),
+			zcapld.WithAudience("https://example.com"),
+			zcapld.WithCaveat(&zcapld.AllowedActionCaveat{
+				Type:          zcapld.CaveatTypeAllowedAction,
+				AllowedAction: []string{"read"},
+			}),
+			zcapld.WithInvocationTarget("urn:ignored", ""),
+		)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(zcap.ID, "urn:uuid:"))
+		require.Equal(t, root.ID, zcap.Parent)
+		require.Equal(t, invoker, zcap.Invoker)
+		require.Equal(t, root.InvocationTarget, zcap.InvocationTarget)
+		require.Equal(t, []string{"read"}, zcap.AllowedAction)
+		require.Equal(t, []string{"https://example.com"}, zcap.Audience)
+		require.Equal(t, expires.UTC().Format(time.RFC3339), zcap.ExpiresAt)
+		require.Len(t, zcap.Caveats, 1)
+
+		depth, err := zcap.Depth()
+		require.NoError(t, err)
+		require.Equal(t, 1, depth)
+
+		require.NoError(t, zcapld.SignCapability(zcap, &zcapld.Signer{
+			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(rootSigner)),
+			SuiteType:          ed25519signature2018.SignatureType,
+			VerificationMethod: keyID(rootSigner),
+		}))
+		require.Len(t, zcap.Proof, 1)
+		require.Equal(t, []interface{}{root.ID}, zcap.Proof[0]["capabilityChain"])
+		require.Contains(t, zcap.Proof[0], "jws")
+
+		err = verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithCaveats(zcapld.DefaultCaveatRegistry()),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         zcap,
+				CapabilityAction:   "read",
+				VerificationMethod: invoker,
+			},
+			invocation(invoker, expectRootCapability(root.ID), expectAudience("https://example.com")),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("success: allowed actions default to the parent's", func(t *testing.T) {
+		zcap, err := zcapld.DelegateCapability(root, invoker)
+		require.NoError(t, err)
+		require.Equal(t, root.AllowedAction, zcap.AllowedAction)
+	})
+
+	t.Run("success: chain of a delegated parent", func(t *testing.T) {
+		chain := delegationChain(t, root, rootSigner, 1)
+
+		zcap, err := zcapld.DelegateCapability(chain[0].zcap, invoker)
+		require.NoError(t, err)
+
+		id, isURI, err := zcap.ParentCapabilityID()
+		require.NoError(t, err)
+		require.True(t, isURI)
+		require.Equal(t, chain[0].zcap.ID, id)
+		require.Equal(t, []interface{}{root.ID, chain[0].zcap.ID}, zcap.Proof[0]["capabilityChain"])
+	})
+
+	t.Run("error: actions not allowed by the parent", func(t *testing.T) {
+		_, err := zcapld.DelegateCapability(root, invoker, zcapld.WithAllowedActions("read", "delete"))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrActionNotAllowed))
+		require.Contains(t, err.Error(), `action "delete" is not allowed by parent capability`)
+
+		_, err = zcapld.DelegateCapability(root, invoker, zcapld.WithAllowedActions())
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrActionNotAllowed))
+	})
+
+	t.Run("error: invalid delegated capability", func(t *testing.T) {
+		_, err := zcapld.DelegateCapability(root, invoker, zcapld.WithExpiresAt(time.Now().Add(-time.Hour)))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityExpired))
+		require.Contains(t, err.Error(), "invalid delegated capability")
+	})
+
+	t.Run("error: missing arguments", func(t *testing.T) {
+		_, err := zcapld.DelegateCapability(nil, invoker)
+		require.EqualError(t, err, "parent capability is required")
+
+		_, err = zcapld.DelegateCapability(root, "")
+		require.EqualError(t, err, "invoker is required")
+	})
+
+	t.Run("error: invalid parent chain", func(t *testing.T) {
+		_, err := zcapld.DelegateCapability(&zcapld.Capability{ID: "urn:zcap:1", Parent: "urn:zcap:0"}, invoker)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to build capability chain from parent urn:zcap:1")
+	})
+}
+
+func TestSignCapability(t *testing.T) {
+	t.Run("error: no signer", func(t *testing.T) {
+		err := zcapld.SignCapability(&zcapld.Capability{}, nil)
+		require.EqualError(t, err, "must provide a signer")
+	})
+
+	t.Run("error: no capability chain", func(t *testing.T) {
+		signer := testSigner(t, kms.ED25519)
+		err := zcapld.SignCapability(&zcapld.Capability{ID: "urn:zcap:1", Parent: "urn:zcap:0"}, &zcapld.Signer{
+			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
+			SuiteType:          ed25519signature2018.SignatureType,
+			VerificationMethod: keyID(signer),
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get capabilityChain")
+	})
+}
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package zcapld_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
@@ -30,6 +31,7 @@ func TestE2E(t *testing.T) {
 			VerificationMethod: didKeyURL,
 		},
 		zcapld.WithInvoker(didKeyURL),
+		zcapld.WithInvocationTarget("urn:edv:document:123", "urn:edv:document"),
 	)
 	require.NoError(t, err)
 
@@ -44,6 +46,7 @@ func TestE2E(t *testing.T) {
 	)
 	require.NoError(t, err)
 	err = verifier.Verify(
+		context.Background(),
 		&zcapld.Proof{
 			Capability:         zcap,
 			VerificationMethod: didKeyURL,
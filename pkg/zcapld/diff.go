@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+// FieldDiff is a field that differs between two capabilities.
+type FieldDiff struct {
+	// FieldName is the path of the field, eg. "InvocationTarget.ID", "AllowedAction[1]" or "Proof[0].jws".
+	FieldName string
+	// OldValue is the value of the field in the first capability, or nil if it has no such field.
+	OldValue interface{}
+	// NewValue is the value of the field in the second capability, or nil if it has no such field.
+	NewValue interface{}
+}
+
+// Diff returns the fields that differ from capability 'a' to capability 'b', in the order of the fields of
+// Capability. Slices are compared element by element, and proofs key by key. A nil capability has no fields set.
+func Diff(a, b *Capability) []FieldDiff {
+	if a == nil {
+		a = &Capability{}
+	}
+
+	if b == nil {
+		b = &Capability{}
+	}
+
+	d := &differ{}
+
+	d.value("Context", a.Context, b.Context)
+	d.value("ID", a.ID, b.ID)
+	d.value("Invoker", a.Invoker, b.Invoker)
+	d.value("Controller", a.Controller, b.Controller)
+	d.value("Delegator", a.Delegator, b.Delegator)
+	d.value("Parent", a.Parent, b.Parent)
+	d.strings("AllowedAction", a.AllowedAction, b.AllowedAction)
+	d.strings("Audience", a.Audience, b.Audience)
+	d.value("InvocationTarget.ID", a.InvocationTarget.ID, b.InvocationTarget.ID)
+	d.value("InvocationTarget.Type", a.InvocationTarget.Type, b.InvocationTarget.Type)
+	d.value("ExpiresAt", a.ExpiresAt, b.ExpiresAt)
+	d.rawMessages("Caveats", a.Caveats, b.Caveats)
+	d.proofs("Proof", a.Proof, b.Proof)
+
+	return d.diffs
+}
+
+type differ struct {
+	diffs []FieldDiff
+}
+
+func (d *differ) value(name string, a, b string) {
+	if a != b {
+		d.diffs = append(d.diffs, FieldDiff{FieldName: name, OldValue: a, NewValue: b})
+	}
+}
+
+func (d *differ) strings(name string, a, b []string) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		switch {
+		case i >= len(a):
+			d.diffs = append(d.diffs, FieldDiff{FieldName: index(name, i), NewValue: b[i]})
+		case i >= len(b):
+			d.diffs = append(d.diffs, FieldDiff{FieldName: index(name, i), OldValue: a[i]})
+		default:
+			d.value(index(name, i), a[i], b[i])
+		}
+	}
+}
+
+func (d *differ) rawMessages(name string, a, b []json.RawMessage) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		switch {
+		case i >= len(a):
+			d.diffs = append(d.diffs, FieldDiff{FieldName: index(name, i), NewValue: b[i]})
+		case i >= len(b):
+			d.diffs = append(d.diffs, FieldDiff{FieldName: index(name, i), OldValue: a[i]})
+		case !bytes.Equal(a[i], b[i]):
+			d.diffs = append(d.diffs, FieldDiff{FieldName: index(name, i), OldValue: a[i], NewValue: b[i]})
+		}
+	}
+}
+
+func (d *differ) proofs(name string, a, b []verifiable.Proof) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		switch {
+		case i >= len(a):
+			d.diffs = append(d.diffs, FieldDiff{FieldName: index(name, i), NewValue: b[i]})
+		case i >= len(b):
+			d.diffs = append(d.diffs, FieldDiff{FieldName: index(name, i), OldValue: a[i]})
+		default:
+			d.proof(index(name, i), a[i], b[i])
+		}
+	}
+}
+
+// proof compares the values of the proofs by their JSON representation, in the order of their keys.
+func (d *differ) proof(name string, a, b verifiable.Proof) {
+	keys := make([]string, 0, len(a)+len(b))
+
+	for k := range a {
+		keys = append(keys, k)
+	}
+
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		valueA, okA := a[k]
+		valueB, okB := b[k]
+
+		if okA && okB && jsonEqual(valueA, valueB) {
+			continue
+		}
+
+		d.diffs = append(d.diffs, FieldDiff{FieldName: name + "." + k, OldValue: valueA, NewValue: valueB})
+	}
+}
+
+func index(name string, i int) string {
+	return fmt.Sprintf("%s[%d]", name, i)
+}
+
+func jsonEqual(a, b interface{}) bool {
+	rawA, errA := json.Marshal(a)
+	rawB, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && bytes.Equal(rawA, rawB)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestDiff(t *testing.T) {
+	t.Run("no differences", func(t *testing.T) {
+		a := testCapability()
+		require.Empty(t, zcapld.Diff(a, zcapld.Clone(a)))
+		require.Empty(t, zcapld.Diff(nil, nil))
+		require.Empty(t, zcapld.Diff(nil, &zcapld.Capability{}))
+	})
+
+	t.Run("differences", func(t *testing.T) {
+		a := testCapability()
+		b := zcapld.Clone(a)
+		b.ID = "urn:zcap:other"
+		b.InvocationTarget.Type = "urn:edv:vault"
+		b.AllowedAction = []string{"read", "write", "delete"}
+		b.Audience = nil
+		b.Caveats[0] = json.RawMessage(`{}`)
+		b.Proof[0]["jws"] = "other"
+		b.Proof[0]["nonce"] = "123"
+		delete(b.Proof[0], "created")
+
+		require.Equal(t, []zcapld.FieldDiff{
+			{FieldName: "ID", OldValue: a.ID, NewValue: "urn:zcap:other"},
+			{FieldName: "AllowedAction[2]", NewValue: "delete"},
+			{FieldName: "Audience[0]", OldValue: "https://example.com"},
+			{FieldName: "InvocationTarget.Type", OldValue: "urn:edv:document", NewValue: "urn:edv:vault"},
+			{FieldName: "Caveats[0]", OldValue: a.Caveats[0], NewValue: json.RawMessage(`{}`)},
+			{FieldName: "Proof[0].created", OldValue: "2020-10-07T21:59:06Z"},
+			{FieldName: "Proof[0].jws", OldValue: a.Proof[0]["jws"], NewValue: "other"},
+			{FieldName: "Proof[0].nonce", NewValue: "123"},
+		}, zcapld.Diff(a, b))
+	})
+
+	t.Run("re-serialized proof values are equal", func(t *testing.T) {
+		a := testCapability()
+
+		raw, err := json.Marshal(a)
+		require.NoError(t, err)
+
+		b := &zcapld.Capability{}
+		require.NoError(t, json.Unmarshal(raw, b))
+		require.Empty(t, zcapld.Diff(a, b))
+	})
+
+	t.Run("nil capability", func(t *testing.T) {
+		a := &zcapld.Capability{ID: "urn:zcap:123", Proof: testCapability().Proof}
+
+		diffs := zcapld.Diff(a, nil)
+		require.Equal(t, zcapld.FieldDiff{FieldName: "ID", OldValue: "urn:zcap:123", NewValue: ""}, diffs[0])
+		require.Equal(t, zcapld.FieldDiff{FieldName: "Proof[0]", OldValue: a.Proof[0]}, diffs[1])
+	})
+}
@@ -0,0 +1,152 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+// Equal returns true if both capabilities are semantically equal.
+func Equal(a, b *Capability) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Context == b.Context &&
+		a.ID == b.ID &&
+		a.Invoker == b.Invoker &&
+		a.Controller == b.Controller &&
+		a.Delegator == b.Delegator &&
+		a.Parent == b.Parent &&
+		a.ExpiresAt == b.ExpiresAt &&
+		a.InvocationTarget == b.InvocationTarget &&
+		stringsEqual(a.AllowedAction, b.AllowedAction) &&
+		stringsEqual(a.Audience, b.Audience) &&
+		rawMessagesEqual(a.Caveats, b.Caveats) &&
+		proofsEqual(a.Proof, b.Proof)
+}
+
+// Clone returns a copy of the capability that shares no memory with the original.
+func Clone(c *Capability) *Capability {
+	if c == nil {
+		return nil
+	}
+
+	clone := *c
+
+	if c.AllowedAction != nil {
+		clone.AllowedAction = append([]string{}, c.AllowedAction...)
+	}
+
+	if c.Audience != nil {
+		clone.Audience = append([]string{}, c.Audience...)
+	}
+
+	if c.Caveats != nil {
+		clone.Caveats = make([]json.RawMessage, len(c.Caveats))
+
+		for i := range c.Caveats {
+			clone.Caveats[i] = append(json.RawMessage{}, c.Caveats[i]...)
+		}
+	}
+
+	if c.Proof != nil {
+		clone.Proof = make([]verifiable.Proof, len(c.Proof))
+
+		for i := range c.Proof {
+			clone.Proof[i] = copyMap(c.Proof[i])
+		}
+	}
+
+	return &clone
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func rawMessagesEqual(a, b []json.RawMessage) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// proofsEqual compares the proofs by their JSON representation (keys are sorted when marshalled).
+func proofsEqual(a, b []verifiable.Proof) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		rawA, errA := json.Marshal(a[i])
+		rawB, errB := json.Marshal(b[i])
+
+		if errA != nil || errB != nil || !bytes.Equal(rawA, rawB) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	c := make(map[string]interface{}, len(m))
+
+	for k, v := range m {
+		c[k] = copyValue(v)
+	}
+
+	return c
+}
+
+func copyValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return copyMap(value)
+	case verifiable.Proof:
+		return verifiable.Proof(copyMap(value))
+	case []interface{}:
+		c := make([]interface{}, len(value))
+
+		for i := range value {
+			c[i] = copyValue(value[i])
+		}
+
+		return c
+	case []string:
+		return append([]string{}, value...)
+	case []byte:
+		return append([]byte{}, value...)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestEqual(t *testing.T) {
+	t.Run("equal capabilities", func(t *testing.T) {
+		a := testCapability()
+		require.True(t, zcapld.Equal(a, zcapld.Clone(a)))
+		require.True(t, zcapld.Equal(nil, nil))
+	})
+
+	t.Run("different capabilities", func(t *testing.T) {
+		mutations := []func(c *zcapld.Capability){
+			func(c *zcapld.Capability) { c.ID = uuid.New().String() },
+			func(c *zcapld.Capability) { c.Invoker = uuid.New().String() },
+			func(c *zcapld.Capability) { c.Parent = uuid.New().String() },
+			func(c *zcapld.Capability) { c.ExpiresAt = "2020-10-07T21:59:06Z" },
+			func(c *zcapld.Capability) { c.InvocationTarget.Type = uuid.New().String() },
+			func(c *zcapld.Capability) { c.AllowedAction = append(c.AllowedAction, "delete") },
+			func(c *zcapld.Capability) { c.AllowedAction[0] = "delete" },
+			func(c *zcapld.Capability) { c.Audience[0] = "https://other.example.com" },
+			func(c *zcapld.Capability) { c.Caveats[0] = json.RawMessage(`{}`) },
+			func(c *zcapld.Capability) { c.Caveats = nil },
+			func(c *zcapld.Capability) { c.Proof[0]["jws"] = uuid.New().String() },
+			func(c *zcapld.Capability) { c.Proof[0]["capabilityChain"].([]interface{})[0] = uuid.New().String() },
+			func(c *zcapld.Capability) { c.Proof = append(c.Proof, verifiable.Proof{}) },
+		}
+
+		for i := range mutations {
+			a := testCapability()
+			b := zcapld.Clone(a)
+			mutations[i](b)
+			require.False(t, zcapld.Equal(a, b), "mutation %d", i)
+		}
+
+		require.False(t, zcapld.Equal(testCapability(), nil))
+		require.False(t, zcapld.Equal(nil, testCapability()))
+	})
+}
+
+func TestClone(t *testing.T) {
+	t.Run("clone is independent of the original", func(t *testing.T) {
+		original := testCapability()
+		expected := zcapld.Clone(original)
+		clone := zcapld.Clone(original)
+
+		clone.AllowedAction[0] = "delete"
+		clone.Audience[0] = "https://other.example.com"
+		clone.Caveats[0][0] = '['
+		clone.Proof[0]["jws"] = uuid.New().String()
+		clone.Proof[0]["capabilityChain"].([]interface{})[0] = uuid.New().String()
+
+		require.Equal(t, expected, original)
+	})
+
+	t.Run("nil capability", func(t *testing.T) {
+		require.Nil(t, zcapld.Clone(nil))
+	})
+}
+
+func testCapability() *zcapld.Capability {
+	return &zcapld.Capability{
+		ID:            uuid.New().String(),
+		Context:       zcapld.SecurityContextV2,
+		Invoker:       uuid.New().String(),
+		Controller:    uuid.New().String(),
+		Delegator:     uuid.New().String(),
+		Parent:        uuid.New().String(),
+		AllowedAction: []string{"read", "write"},
+		Audience:      []string{"https://example.com"},
+		InvocationTarget: zcapld.InvocationTarget{
+			ID:   uuid.New().String(),
+			Type: "urn:edv:document",
+		},
+		Caveats: []json.RawMessage{json.RawMessage(`{"type":"urn:test:caveat"}`)},
+		Proof: []verifiable.Proof{{
+			"type":               "Ed25519Signature2018",
+			"created":            "2020-10-07T21:59:06Z",
+			"verificationMethod": uuid.New().String(),
+			"proofPurpose":       "capabilityDelegation",
+			"capabilityChain":    []interface{}{uuid.New().String()},
+			"jws":                uuid.New().String(),
+		}},
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors wrapped by the errors returned from the package, to be matched with errors.Is.
+var (
+	// ErrCapabilityNotFound is returned by CapabilityResolvers when the capability does not exist.
+	ErrCapabilityNotFound = errors.New("uri not found")
+	// ErrCapabilityExpired is returned when a capability in the chain, or one of its expiry caveats, has expired.
+	ErrCapabilityExpired = errors.New("capability expired")
+	// ErrInvokerNotAuthorized is returned when the verification method of the invocation is not an authorized
+	// invoker of the capability.
+	ErrInvokerNotAuthorized = errors.New("invoker not authorized")
+	// ErrChainTooDeep is returned when a capability chain is longer than the verifier allows.
+	ErrChainTooDeep = errors.New("capability chain too deep")
+	// ErrActionNotAllowed is returned when the invoked action is not allowed by the capability or its caveats, or
+	// is not the expected action.
+	ErrActionNotAllowed = errors.New("action not allowed")
+	// ErrTargetMismatch is returned when the invocation target of the root capability is not the expected one.
+	ErrTargetMismatch = errors.New("invocation target mismatch")
+	// ErrTargetTypeMismatch is returned when the invocation target type of the root capability is not the expected
+	// one.
+	ErrTargetTypeMismatch = errors.New("invocation target type mismatch")
+	// ErrRootCapabilityMismatch is returned when the root capability of the chain is not the expected one.
+	ErrRootCapabilityMismatch = errors.New("root capability mismatch")
+	// ErrPolicyDenied is returned when the PolicyEngine of the Verifier does not allow an invocation.
+	ErrPolicyDenied = errors.New("denied by policy")
+	// ErrInvalidCapabilityID is returned when the ID of a capability in the chain is not a URI, or not one of the
+	// allowed schemes.
+	ErrInvalidCapabilityID = errors.New("invalid capability ID")
+	// ErrChainCycle is returned when a capability appears more than once in a capability chain, including the
+	// capability being invoked.
+	ErrChainCycle = errors.New("the capability chain contains a cycle")
+	// ErrSelfReferenceRequired is returned when no root capability is expected by the invocation and the root
+	// capability of the chain is not self-referencing, ie. its ID is not its invocation target ID.
+	ErrSelfReferenceRequired = errors.New("self-referencing root capability required")
+	// ErrMaxInvocationsExceeded is returned when a capability has been invoked more times than its
+	// MaxInvocationsCaveat allows.
+	ErrMaxInvocationsExceeded = errors.New("max invocations exceeded")
+	// ErrInvalidInvocationTarget is returned when the handler of the invocation target type of the root capability
+	// rejects its invocation target.
+	ErrInvalidInvocationTarget = errors.New("invalid invocation target")
+	// ErrUnauthorizedTarget is returned when an additional target of the invocation is not covered by a capability
+	// in the chain. It is wrapped by an UnauthorizedTargetError.
+	ErrUnauthorizedTarget = errors.New("unauthorized target")
+)
+
+// UnauthorizedTargetError identifies the additional target of an invocation that a capability does not cover.
+// It wraps ErrUnauthorizedTarget.
+type UnauthorizedTargetError struct {
+	// CapabilityID is the ID of the capability that does not cover the target.
+	CapabilityID string
+	// Target is the additional target of the invocation.
+	Target string
+}
+
+func (e *UnauthorizedTargetError) Error() string {
+	return fmt.Sprintf("%s: target %s is not covered by capability %s", ErrUnauthorizedTarget, e.Target, e.CapabilityID)
+}
+
+// Unwrap returns ErrUnauthorizedTarget.
+func (e *UnauthorizedTargetError) Unwrap() error {
+	return ErrUnauthorizedTarget
+}
+
+// Reasons of ChainVerificationErrors, naming the step of the chain verification that failed.
+const (
+	// ChainReasonInvokedCapability is the reason when the capability being invoked does not allow the invocation.
+	ChainReasonInvokedCapability = "invoked capability"
+	// ChainReasonRootCapability is the reason when the root capability cannot be resolved or does not allow the
+	// invocation.
+	ChainReasonRootCapability = "root capability"
+	// ChainReasonDelegatedCapability is the reason when an intermediate capability in the chain cannot be resolved or
+	// does not allow the invocation.
+	ChainReasonDelegatedCapability = "delegated capability"
+	// ChainReasonDelegation is the reason when the capability being invoked was not delegated by an authorized
+	// delegator of its parent.
+	ChainReasonDelegation = "delegation"
+)
+
+// ChainVerificationError is returned when a capability in the capability chain fails verification.
+type ChainVerificationError struct {
+	// CapabilityID is the ID of the capability that failed verification.
+	CapabilityID string
+	// ChainDepth is the position of the capability in the chain, starting at 0 with the root capability.
+	ChainDepth int
+	// Reason is the step of the chain verification that failed, one of the ChainReason constants.
+	Reason string
+	// Cause is the error found verifying the capability.
+	Cause error
+}
+
+func (e *ChainVerificationError) Error() string {
+	return fmt.Sprintf("invalid capability chain: %s", e.Cause)
+}
+
+// Unwrap returns the cause of the error.
+func (e *ChainVerificationError) Unwrap() error {
+	return e.Cause
+}
+
+// IsChainVerificationError returns the ChainVerificationError in the error's chain, if any.
+func IsChainVerificationError(err error) (*ChainVerificationError, bool) {
+	var e *ChainVerificationError
+	if errors.As(err, &e) {
+		return e, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,189 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util/signature"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestVerifier_Verify_SentinelErrors(t *testing.T) {
+	now := time.Now()
+	rootSigner := testSigner(t, kms.ED25519)
+	root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+		withID("urn:zcap:root"), withInvoker(keyID(rootSigner)), withVerMethod(keyID(rootSigner)),
+		withInvocationTarget("urn:target"), withExpiresAt(now.Add(time.Hour)))
+
+	tests := []struct {
+		name     string
+		action   string
+		vm       string
+		options  []invocationOption
+		clock    func() time.Time
+		expected error
+	}{
+		{
+			name:     "capability expired",
+			clock:    func() time.Time { return now.Add(2 * time.Hour) },
+			expected: zcapld.ErrCapabilityExpired,
+		},
+		{
+			name:     "invoker not authorized",
+			vm:       "did:example:other",
+			expected: zcapld.ErrInvokerNotAuthorized,
+		},
+		{
+			name:     "action not allowed",
+			action:   "delete",
+			options:  []invocationOption{expectAction("delete")},
+			expected: zcapld.ErrActionNotAllowed,
+		},
+		{
+			name:     "target mismatch",
+			options:  []invocationOption{expectTarget("urn:other")},
+			expected: zcapld.ErrTargetMismatch,
+		},
+		{
+			name:     "target type mismatch",
+			options:  []invocationOption{expectTargetType("urn:edv:vault")},
+			expected: zcapld.ErrTargetTypeMismatch,
+		},
+		{
+			name:     "root capability mismatch",
+			options:  []invocationOption{expectRootCapability("urn:zcap:other")},
+			expected: zcapld.ErrRootCapabilityMismatch,
+		},
+	}
+
+	for i := range tests {
+		test := tests[i]
+
+		t.Run(test.name, func(t *testing.T) {
+			action, vm, clock := "read", root.Invoker, time.Now
+
+			if test.action != "" {
+				action = test.action
+			}
+
+			if test.vm != "" {
+				vm = test.vm
+			}
+
+			if test.clock != nil {
+				clock = test.clock
+			}
+
+			err := verifier(t,
+				zcapld.SimpleCapabilityResolver{root.ID: root},
+				zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+				zcapld.WithClock(clock),
+			).Verify(
+				context.Background(),
+				&zcapld.Proof{
+					Capability:         root,
+					CapabilityAction:   action,
+					VerificationMethod: vm,
+				},
+				invocation(vm, append([]invocationOption{expectRootCapability(root.ID)}, test.options...)...),
+			)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, test.expected), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestIsChainVerificationError(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	chain := delegationChain(t, root, rootSigner, 1)
+	parent := chain[0]
+	invokerSigner := testSigner(t, kms.ED25519)
+	newCapability := func(signer signature.Signer, options ...zcapOption) *zcapld.Capability {
+		return capability(t, signer, ed25519signature2018.SignatureType,
+			append([]zcapOption{
+				withInvoker(keyID(invokerSigner)), withParent(parent.zcap.ID), withVerMethod(keyID(signer)),
+				withCapabilityChain([]interface{}{root.ID, parent.zcap.ID}),
+			}, options...)...)
+	}
+	verify := func(r zcapld.CapabilityResolver, c *zcapld.Capability, vm string) error {
+		return verifier(t, r, chainKeyResolver(t, rootSigner, chain)).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         c,
+				CapabilityAction:   "read",
+				VerificationMethod: vm,
+			},
+			invocation(vm, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("root capability", func(t *testing.T) {
+		c := newCapability(parent.signer)
+		err := verify(zcapld.SimpleCapabilityResolver{parent.zcap.ID: parent.zcap}, c, c.Invoker)
+
+		e, ok := zcapld.IsChainVerificationError(err)
+		require.True(t, ok)
+		require.Equal(t, root.ID, e.CapabilityID)
+		require.Equal(t, 0, e.ChainDepth)
+		require.Equal(t, zcapld.ChainReasonRootCapability, e.Reason)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+		require.Equal(t, "invalid capability chain: "+e.Cause.Error(), err.Error())
+	})
+
+	t.Run("delegated capability", func(t *testing.T) {
+		c := newCapability(parent.signer)
+		err := verify(zcapld.SimpleCapabilityResolver{root.ID: root}, c, c.Invoker)
+
+		e, ok := zcapld.IsChainVerificationError(err)
+		require.True(t, ok)
+		require.Equal(t, parent.zcap.ID, e.CapabilityID)
+		require.Equal(t, 1, e.ChainDepth)
+		require.Equal(t, zcapld.ChainReasonDelegatedCapability, e.Reason)
+	})
+
+	t.Run("invoked capability", func(t *testing.T) {
+		c := newCapability(parent.signer, withExpiresAt(time.Now().Add(-time.Hour)))
+		err := verify(chainResolver(root, chain), c, c.Invoker)
+
+		e, ok := zcapld.IsChainVerificationError(err)
+		require.True(t, ok)
+		require.Equal(t, c.ID, e.CapabilityID)
+		require.Equal(t, 2, e.ChainDepth)
+		require.Equal(t, zcapld.ChainReasonInvokedCapability, e.Reason)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityExpired))
+	})
+
+	t.Run("delegation", func(t *testing.T) {
+		c := newCapability(testSigner(t, kms.ED25519))
+		err := verify(chainResolver(root, chain), c, c.Invoker)
+
+		e, ok := zcapld.IsChainVerificationError(err)
+		require.True(t, ok)
+		require.Equal(t, c.ID, e.CapabilityID)
+		require.Equal(t, 2, e.ChainDepth)
+		require.Equal(t, zcapld.ChainReasonDelegation, e.Reason)
+	})
+
+	t.Run("not a chain verification error", func(t *testing.T) {
+		err := verify(chainResolver(root, chain), newCapability(parent.signer), "did:example:other")
+		require.True(t, errors.Is(err, zcapld.ErrInvokerNotAuthorized))
+
+		_, ok := zcapld.IsChainVerificationError(err)
+		require.False(t, ok)
+
+		_, ok = zcapld.IsChainVerificationError(nil)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,153 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package grpc resolves capabilities with the CapabilityResolverService gRPC service of resolver.proto.
+package grpc
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. resolver.proto
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+// capabilityResolver resolves capabilities with a CapabilityResolverService client.
+type capabilityResolver struct {
+	client CapabilityResolverServiceClient
+}
+
+// GRPCCapabilityResolver returns a resolver of the capabilities served by the CapabilityResolverService on the
+// connection. The resolver is also a zcapld.BatchCapabilityResolver.
+func GRPCCapabilityResolver(conn *grpc.ClientConn) zcapld.CapabilityResolver {
+	return &capabilityResolver{client: NewCapabilityResolverServiceClient(conn)}
+}
+
+// Resolve the capability with the ID.
+func (r *capabilityResolver) Resolve(ctx context.Context, uri string) (*zcapld.Capability, error) {
+	resp, err := r.client.Resolve(ctx, &CapabilityID{Id: uri})
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("%w: %s", zcapld.ErrCapabilityNotFound, uri)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("grpc resolver: failed to resolve capability %s: %w", uri, err)
+	}
+
+	return unmarshalCapability(uri, resp)
+}
+
+// ResolveMulti resolves the capabilities with the IDs in a single call.
+func (r *capabilityResolver) ResolveMulti(ctx context.Context, ids []string) ([]*zcapld.Capability, []error) {
+	zcaps := make([]*zcapld.Capability, len(ids))
+	errs := make([]error, len(ids))
+
+	resp, err := r.client.ResolveMulti(ctx, &CapabilityIDs{Ids: ids})
+	if err == nil && len(resp.Capabilities) != len(ids) {
+		err = fmt.Errorf("resolved %d capabilities instead of %d", len(resp.Capabilities), len(ids))
+	}
+
+	if err != nil {
+		for i := range errs {
+			errs[i] = fmt.Errorf("grpc resolver: failed to resolve %d capabilities: %w", len(ids), err)
+		}
+
+		return zcaps, errs
+	}
+
+	for i := range ids {
+		zcaps[i], errs[i] = unmarshalCapability(ids[i], resp.Capabilities[i])
+	}
+
+	return zcaps, errs
+}
+
+// unmarshalCapability unmarshals the capability resolved for the ID. Capabilities without a document are not
+// found, and capabilities with another ID are rejected.
+func unmarshalCapability(id string, c *Capability) (*zcapld.Capability, error) {
+	if len(c.Document) == 0 {
+		return nil, fmt.Errorf("%w: %s", zcapld.ErrCapabilityNotFound, id)
+	}
+
+	zcap := &zcapld.Capability{}
+
+	err := json.Unmarshal(c.Document, zcap)
+	if err != nil {
+		return nil, fmt.Errorf("grpc resolver: failed to unmarshal capability %s: %w", id, err)
+	}
+
+	if zcap.ID != id {
+		return nil, fmt.Errorf("grpc resolver: resolved capability %s instead of %s", zcap.ID, id)
+	}
+
+	return zcap, nil
+}
+
+// capabilityResolverServer serves the capabilities of a store.
+type capabilityResolverServer struct {
+	store zcapld.CapabilityStore
+}
+
+// GRPCCapabilityResolverServer returns a CapabilityResolverService that serves the capabilities of the store.
+func GRPCCapabilityResolverServer(store zcapld.CapabilityStore) CapabilityResolverServiceServer {
+	return &capabilityResolverServer{store: store}
+}
+
+// Resolve the capability with the ID. Fails with the NOT_FOUND code if there is none.
+func (s *capabilityResolverServer) Resolve(ctx context.Context, req *CapabilityID) (*Capability, error) {
+	c, err := s.get(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Document) == 0 {
+		return nil, status.Errorf(codes.NotFound, "capability not found: %s", req.Id)
+	}
+
+	return c, nil
+}
+
+// ResolveMulti resolves the capabilities with the IDs. The capabilities that are not found have no document.
+func (s *capabilityResolverServer) ResolveMulti(ctx context.Context, req *CapabilityIDs) (*Capabilities, error) {
+	resp := &Capabilities{Capabilities: make([]*Capability, len(req.Ids))}
+
+	for i, id := range req.Ids {
+		c, err := s.get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Capabilities[i] = c
+	}
+
+	return resp, nil
+}
+
+// get the capability with the ID from the store. The capability has no document if it is not found.
+func (s *capabilityResolverServer) get(ctx context.Context, id string) (*Capability, error) {
+	zcap, err := s.store.Get(ctx, id)
+	if errors.Is(err, zcapld.ErrCapabilityNotFound) {
+		return &Capability{Id: id}, nil
+	}
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get capability %s: %s", id, err)
+	}
+
+	document, err := json.Marshal(zcap)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal capability %s: %s", id, err)
+	}
+
+	return &Capability{Id: id, Document: document}, nil
+}
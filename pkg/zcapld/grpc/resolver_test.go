@@ -0,0 +1,133 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	zcapgrpc "github.com/trustbloc/edge-core/pkg/zcapld/grpc"
+)
+
+func TestGRPCCapabilityResolver(t *testing.T) {
+	expected := &zcapld.Capability{
+		Context:          zcapld.SecurityContextV2,
+		ID:               "urn:zcap:123",
+		Invoker:          "did:example:123",
+		InvocationTarget: zcapld.InvocationTarget{ID: "urn:target"},
+	}
+
+	store := zcapld.NewMemoryCapabilityStore()
+	require.NoError(t, store.Save(context.Background(), expected))
+
+	r := zcapgrpc.GRPCCapabilityResolver(serve(t, zcapgrpc.GRPCCapabilityResolverServer(store)))
+
+	t.Run("success", func(t *testing.T) {
+		result, err := r.Resolve(context.Background(), expected.ID)
+		require.NoError(t, err)
+		require.Equal(t, expected, result)
+	})
+
+	t.Run("error: not found", func(t *testing.T) {
+		_, err := r.Resolve(context.Background(), "urn:zcap:456")
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+	})
+
+	t.Run("success: resolve multiple", func(t *testing.T) {
+		batch, ok := r.(zcapld.BatchCapabilityResolver)
+		require.True(t, ok)
+
+		zcaps, errs := batch.ResolveMulti(context.Background(), []string{expected.ID, "urn:zcap:456"})
+		require.Equal(t, []*zcapld.Capability{expected, nil}, zcaps)
+		require.NoError(t, errs[0])
+		require.True(t, errors.Is(errs[1], zcapld.ErrCapabilityNotFound))
+	})
+
+	t.Run("error: capability with another ID", func(t *testing.T) {
+		r := zcapgrpc.GRPCCapabilityResolver(serve(t, zcapgrpc.GRPCCapabilityResolverServer(
+			&swappingStore{zcap: expected})))
+
+		_, err := r.Resolve(context.Background(), "urn:zcap:456")
+		require.EqualError(t, err, "grpc resolver: resolved capability urn:zcap:123 instead of urn:zcap:456")
+
+		zcaps, errs := r.(zcapld.BatchCapabilityResolver).ResolveMulti(context.Background(),
+			[]string{expected.ID, "urn:zcap:456"})
+		require.Equal(t, []*zcapld.Capability{expected, nil}, zcaps)
+		require.NoError(t, errs[0])
+		require.EqualError(t, errs[1], "grpc resolver: resolved capability urn:zcap:123 instead of urn:zcap:456")
+	})
+
+	t.Run("error: store error", func(t *testing.T) {
+		r := zcapgrpc.GRPCCapabilityResolver(serve(t, zcapgrpc.GRPCCapabilityResolverServer(&failingStore{})))
+
+		_, err := r.Resolve(context.Background(), expected.ID)
+		require.Error(t, err)
+		require.False(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+		require.Equal(t, codes.Internal, status.Code(errors.Unwrap(err)))
+		require.Contains(t, err.Error(), "failed to get capability urn:zcap:123: test")
+
+		_, errs := r.(zcapld.BatchCapabilityResolver).ResolveMulti(context.Background(), []string{expected.ID})
+		require.Len(t, errs, 1)
+		require.Contains(t, errs[0].Error(), "grpc resolver: failed to resolve 1 capabilities")
+	})
+}
+
+type failingStore struct {
+	zcapld.CapabilityStore
+}
+
+func (s *failingStore) Get(context.Context, string) (*zcapld.Capability, error) {
+	return nil, errors.New("test")
+}
+
+// swappingStore returns the same capability for any ID.
+type swappingStore struct {
+	zcapld.CapabilityStore
+	zcap *zcapld.Capability
+}
+
+func (s *swappingStore) Get(context.Context, string) (*zcapld.Capability, error) {
+	return s.zcap, nil
+}
+
+// serve the service on an in-memory listener and returns a connection to it.
+func serve(t *testing.T, service zcapgrpc.CapabilityResolverServiceServer) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	zcapgrpc.RegisterCapabilityResolverServiceServer(server, service)
+
+	go func() {
+		_ = server.Serve(listener) // nolint:errcheck // fails once stopped
+	}()
+
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, conn.Close())
+	})
+
+	return conn
+}
@@ -0,0 +1,132 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// HTTPBatchCapabilityResolver is an HTTPCapabilityResolver that also resolves several capabilities at once from a
+// batch endpoint.
+type HTTPBatchCapabilityResolver struct {
+	*HTTPCapabilityResolver
+	batchURL string
+}
+
+// NewHTTPBatchCapabilityResolver returns a new HTTPBatchCapabilityResolver that fetches capabilities from
+// GET <baseURL>/<capabilityID> and batches of capabilities from POST <batchURL> with the client. The request body of
+// the batch endpoint is the JSON array of the capability IDs, and its response body the JSON array of the
+// capabilities in the same order, with null for the capabilities not found.
+func NewHTTPBatchCapabilityResolver(baseURL, batchURL string, client *http.Client,
+	options ...HTTPResolverOption) *HTTPBatchCapabilityResolver {
+	return &HTTPBatchCapabilityResolver{
+		HTTPCapabilityResolver: NewHTTPCapabilityResolver(baseURL, client, options...),
+		batchURL:               batchURL,
+	}
+}
+
+// ResolveMulti fetches the capabilities with a single request, retried as configured. The errors of the capabilities
+// not found wrap ErrCapabilityNotFound, and the capabilities fetched with another ID than requested are rejected; if
+// the request fails, every capability has its error.
+func (h *HTTPBatchCapabilityResolver) ResolveMulti(ctx context.Context, ids []string) ([]*Capability, []error) {
+	zcaps, errs := make([]*Capability, len(ids)), make([]error, len(ids))
+
+	if len(ids) == 0 {
+		return zcaps, errs
+	}
+
+	if h.resolveTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, h.resolveTimeout)
+		defer cancel()
+	}
+
+	var results []*Capability
+
+	err := h.withRetries(ctx, func() (bool, error) {
+		var (
+			retry bool
+			err   error
+		)
+
+		results, retry, err = h.fetchBatch(ctx, ids)
+
+		return retry, err
+	})
+
+	for i := range ids {
+		switch {
+		case err != nil:
+			errs[i] = err
+		case results[i] == nil:
+			errs[i] = fmt.Errorf("%w: %s", ErrCapabilityNotFound, ids[i])
+		case !uriEqual(results[i].ID, ids[i]):
+			errs[i] = fmt.Errorf("http resolver: fetched capability %s instead of %s", results[i].ID, ids[i])
+		default:
+			zcaps[i] = results[i]
+		}
+	}
+
+	return zcaps, errs
+}
+
+// fetchBatch fetches the capabilities, reporting whether the request may be retried if it fails.
+func (h *HTTPBatchCapabilityResolver) fetchBatch(ctx context.Context, ids []string) ([]*Capability, bool, error) {
+	payload, err := json.Marshal(ids)
+	if err != nil {
+		return nil, false, fmt.Errorf("http resolver: failed to marshal capability IDs: %w", err)
+	}
+
+	req, err := h.newRequest(ctx, http.MethodPost, h.batchURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, ctx.Err() == nil, fmt.Errorf("http resolver: failed to fetch %d capabilities: %w", len(ids), err)
+	}
+
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			logger.Warnf("failed to close response body: %s", errClose)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, fmt.Errorf("http resolver: failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode >= http.StatusInternalServerError, fmt.Errorf(
+			"http resolver: unexpected response status fetching %d capabilities: %d %s",
+			len(ids), resp.StatusCode, body)
+	}
+
+	var zcaps []*Capability
+
+	err = json.Unmarshal(body, &zcaps)
+	if err != nil {
+		return nil, false, fmt.Errorf("http resolver: failed to unmarshal capabilities: %w", err)
+	}
+
+	if len(zcaps) != len(ids) {
+		return nil, false, fmt.Errorf("http resolver: fetched %d capabilities instead of %d", len(zcaps), len(ids))
+	}
+
+	return zcaps, false, nil
+}
@@ -0,0 +1,244 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestHTTPBatchCapabilityResolver_ResolveMulti(t *testing.T) {
+	first := &zcapld.Capability{Context: zcapld.SecurityContextV2, ID: "urn:zcap:1"}
+	second := &zcapld.Capability{Context: zcapld.SecurityContextV2, ID: "urn:zcap:2"}
+	zcaps := map[string]*zcapld.Capability{first.ID: first, second.ID: second}
+
+	batchHandler := func(t *testing.T) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "/zcaps/batch", r.URL.Path)
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+			var ids []string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&ids))
+
+			results := make([]*zcapld.Capability, len(ids))
+			for i := range ids {
+				results[i] = zcaps[ids[i]]
+			}
+
+			require.NoError(t, json.NewEncoder(w).Encode(results))
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(batchHandler(t))
+		defer server.Close()
+
+		results, errs := zcapld.NewHTTPBatchCapabilityResolver(server.URL+"/zcaps", server.URL+"/zcaps/batch",
+			server.Client(), zcapld.WithHTTPHeader("Authorization", "Bearer token"),
+		).ResolveMulti(context.Background(), []string{second.ID, "urn:zcap:unknown", first.ID})
+		require.Equal(t, []*zcapld.Capability{second, nil, first}, results)
+		require.NoError(t, errs[0])
+		require.True(t, errors.Is(errs[1], zcapld.ErrCapabilityNotFound))
+		require.NoError(t, errs[2])
+	})
+
+	t.Run("success: no IDs", func(t *testing.T) {
+		results, errs := zcapld.NewHTTPBatchCapabilityResolver("", "", http.DefaultClient).ResolveMulti(
+			context.Background(), nil)
+		require.Empty(t, results)
+		require.Empty(t, errs)
+	})
+
+	t.Run("success: resolves single capabilities", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+			require.NoError(t, json.NewEncoder(w).Encode(first))
+		}))
+		defer server.Close()
+
+		result, err := zcapld.NewHTTPBatchCapabilityResolver(server.URL, server.URL+"/batch", server.Client()).
+			Resolve(context.Background(), first.ID)
+		require.NoError(t, err)
+		require.Equal(t, first, result)
+	})
+
+	t.Run("success: retries server errors", func(t *testing.T) {
+		var calls int32
+
+		handler := batchHandler(t)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+
+			handler(w, r)
+		}))
+		defer server.Close()
+
+		results, errs := zcapld.NewHTTPBatchCapabilityResolver(server.URL, server.URL+"/zcaps/batch",
+			server.Client(), zcapld.WithHTTPHeader("Authorization", "Bearer token"),
+			zcapld.WithRetryPolicy(zcapld.RetryPolicy{MaxRetries: 1, Backoff: time.Millisecond}),
+		).ResolveMulti(context.Background(), []string{first.ID})
+		require.Equal(t, []*zcapld.Capability{first}, results)
+		require.Equal(t, []error{nil}, errs)
+		require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("error: unexpected status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		results, errs := zcapld.NewHTTPBatchCapabilityResolver(server.URL, server.URL, server.Client()).
+			ResolveMulti(context.Background(), []string{first.ID, second.ID})
+		require.Equal(t, []*zcapld.Capability{nil, nil}, results)
+		require.Len(t, errs, 2)
+
+		for _, err := range errs {
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "unexpected response status fetching 2 capabilities: 403 forbidden")
+		}
+	})
+
+	t.Run("error: invalid response", func(t *testing.T) {
+		for body, expected := range map[string]string{
+			"{":              "failed to unmarshal capabilities",
+			`[{"id":"urn"}]`: "fetched 1 capabilities instead of 2",
+		} {
+			body := body
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, err := w.Write([]byte(body))
+				require.NoError(t, err)
+			}))
+
+			_, errs := zcapld.NewHTTPBatchCapabilityResolver(server.URL, server.URL, server.Client()).
+				ResolveMulti(context.Background(), []string{first.ID, second.ID})
+			require.Error(t, errs[0])
+			require.Contains(t, errs[0].Error(), expected)
+
+			server.Close()
+		}
+	})
+
+	t.Run("error: capability with another ID", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewEncoder(w).Encode([]*zcapld.Capability{first, first}))
+		}))
+		defer server.Close()
+
+		results, errs := zcapld.NewHTTPBatchCapabilityResolver(server.URL, server.URL, server.Client()).
+			ResolveMulti(context.Background(), []string{first.ID, second.ID})
+		require.Equal(t, []*zcapld.Capability{first, nil}, results)
+		require.NoError(t, errs[0])
+		require.Error(t, errs[1])
+		require.Contains(t, errs[1].Error(), "fetched capability urn:zcap:1 instead of urn:zcap:2")
+	})
+
+	t.Run("error: server unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		_, errs := zcapld.NewHTTPBatchCapabilityResolver(server.URL, server.URL, server.Client()).
+			ResolveMulti(context.Background(), []string{first.ID})
+		require.Error(t, errs[0])
+		require.Contains(t, errs[0].Error(), "failed to fetch 1 capabilities")
+	})
+}
+
+func TestVerifier_BatchCapabilityResolver(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	chain := delegationChain(t, root, rootSigner, 2)
+	parent := chain[len(chain)-1]
+	capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+		withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(parent.zcap.ID),
+		withVerMethod(keyID(parent.signer)),
+		withCapabilityChain([]interface{}{root.ID, chain[0].zcap.ID, parent.zcap.ID}))
+	verify := func(resolver zcapld.CapabilityResolver) error {
+		return verifier(t, resolver, chainKeyResolver(t, rootSigner, chain)).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("success: chain resolved at once", func(t *testing.T) {
+		resolver := &mockBatchResolver{SimpleCapabilityResolver: chainResolver(root, chain)}
+
+		require.NoError(t, verify(resolver))
+		require.Equal(t, [][]string{{root.ID, chain[0].zcap.ID, parent.zcap.ID}}, resolver.batches)
+		require.Zero(t, resolver.resolved)
+	})
+
+	t.Run("error: capability of the batch not found", func(t *testing.T) {
+		resolver := &mockBatchResolver{SimpleCapabilityResolver: zcapld.SimpleCapabilityResolver{root.ID: root}}
+
+		err := verify(resolver)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+		require.Contains(t, err.Error(), "failed to resolve capability URI "+chain[0].zcap.ID)
+		require.Len(t, resolver.batches, 1)
+	})
+
+	t.Run("success: results of invalid batches are ignored", func(t *testing.T) {
+		resolver := &mockBatchResolver{SimpleCapabilityResolver: chainResolver(root, chain), truncate: true}
+
+		require.NoError(t, verify(resolver))
+		require.Len(t, resolver.batches, 1)
+		require.Equal(t, 3, resolver.resolved)
+	})
+}
+
+// mockBatchResolver is a BatchCapabilityResolver recording its batches and counting its single resolutions.
+type mockBatchResolver struct {
+	zcapld.SimpleCapabilityResolver
+	batches  [][]string
+	resolved int
+	// truncate drops the last result of the batches.
+	truncate bool
+}
+
+func (m *mockBatchResolver) Resolve(ctx context.Context, uri string) (*zcapld.Capability, error) {
+	m.resolved++
+
+	return m.SimpleCapabilityResolver.Resolve(ctx, uri)
+}
+
+func (m *mockBatchResolver) ResolveMulti(ctx context.Context, ids []string) ([]*zcapld.Capability, []error) {
+	m.batches = append(m.batches, ids)
+
+	zcaps, errs := make([]*zcapld.Capability, len(ids)), make([]error, len(ids))
+
+	for i := range ids {
+		zcaps[i], errs[i] = m.SimpleCapabilityResolver.Resolve(ctx, ids[i])
+	}
+
+	if m.truncate {
+		return zcaps[:len(ids)-1], errs[:len(ids)-1]
+	}
+
+	return zcaps, errs
+}
@@ -0,0 +1,256 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RetryPolicy configures the retries of failed requests made by the HTTPCapabilityResolver. Requests are retried
+// if they fail to reach the server or the server responds with a 5xx status code.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt.
+	MaxRetries int
+	// Backoff is the delay before the first retry. It doubles with every retry.
+	Backoff time.Duration
+}
+
+// HTTPResolverOption sets an option for the HTTPCapabilityResolver.
+type HTTPResolverOption func(*HTTPCapabilityResolver)
+
+// WithHTTPHeader sets a header on all requests made by the HTTPCapabilityResolver, eg. "Authorization".
+func WithHTTPHeader(key, value string) HTTPResolverOption {
+	return func(r *HTTPCapabilityResolver) {
+		r.headers.Set(key, value)
+	}
+}
+
+// WithRetryPolicy sets the RetryPolicy of the HTTPCapabilityResolver. Defaults to no retries.
+func WithRetryPolicy(p RetryPolicy) HTTPResolverOption {
+	return func(r *HTTPCapabilityResolver) {
+		r.retry = p
+	}
+}
+
+// WithResolveTimeout sets the time limit of each call to Resolve, including its retries, independently of the
+// timeout of the http.Client. Defaults to 0, meaning no limit other than the context's.
+func WithResolveTimeout(d time.Duration) HTTPResolverOption {
+	return func(r *HTTPCapabilityResolver) {
+		r.resolveTimeout = d
+	}
+}
+
+// HTTPCapabilityResolver resolves capabilities from a REST endpoint.
+type HTTPCapabilityResolver struct {
+	baseURL        string
+	client         *http.Client
+	headers        http.Header
+	retry          RetryPolicy
+	resolveTimeout time.Duration
+}
+
+// NewHTTPCapabilityResolver returns a new HTTPCapabilityResolver that fetches capabilities from
+// GET <baseURL>/<capabilityID> with the client.
+func NewHTTPCapabilityResolver(
+	baseURL string, client *http.Client, options ...HTTPResolverOption) *HTTPCapabilityResolver {
+	r := &HTTPCapabilityResolver{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+		headers: make(http.Header),
+	}
+
+	for i := range options {
+		options[i](r)
+	}
+
+	return r
+}
+
+// Resolve fetches the capability. It returns ErrCapabilityNotFound if the server responds with 404 Not Found, and an
+// error if the server responds with a capability with another ID.
+func (h *HTTPCapabilityResolver) Resolve(ctx context.Context, uri string) (*Capability, error) {
+	if h.resolveTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, h.resolveTimeout)
+		defer cancel()
+	}
+
+	var zcap *Capability
+
+	err := h.withRetries(ctx, func() (bool, error) {
+		var (
+			retry bool
+			err   error
+		)
+
+		zcap, retry, err = h.fetch(ctx, h.endpoint(uri), uri)
+
+		return retry, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return zcap, nil
+}
+
+// Revoke deletes the capability with DELETE <baseURL>/<capabilityID>. It returns ErrCapabilityNotFound if the
+// server responds with 404 Not Found.
+func (h *HTTPCapabilityResolver) Revoke(ctx context.Context, id string) error {
+	return h.withRetries(ctx, func() (bool, error) {
+		return h.delete(ctx, h.endpoint(id), id)
+	})
+}
+
+// RevokeIfExists is like Revoke but returns nil if the capability does not exist.
+func (h *HTTPCapabilityResolver) RevokeIfExists(ctx context.Context, id string) error {
+	err := h.Revoke(ctx, id)
+	if errors.Is(err, ErrCapabilityNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+func (h *HTTPCapabilityResolver) endpoint(id string) string {
+	return h.baseURL + "/" + url.PathEscape(id)
+}
+
+// withRetries makes the request with 'attempt' until it succeeds, it may not be retried, or the retries
+// are exhausted.
+func (h *HTTPCapabilityResolver) withRetries(ctx context.Context, attempt func() (bool, error)) error {
+	backoff := h.retry.Backoff
+
+	for i := 0; ; i++ {
+		retry, err := attempt()
+		if err == nil {
+			return nil
+		}
+
+		if !retry || i >= h.retry.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("http resolver: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+func (h *HTTPCapabilityResolver) newRequest(
+	ctx context.Context, method, endpoint string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("http resolver: failed to create request: %w", err)
+	}
+
+	for key, values := range h.headers {
+		for i := range values {
+			req.Header.Add(key, values[i])
+		}
+	}
+
+	return req, nil
+}
+
+// fetch the capability, reporting whether the request may be retried if it fails.
+func (h *HTTPCapabilityResolver) fetch(ctx context.Context, endpoint, uri string) (*Capability, bool, error) {
+	req, err := h.newRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	req.Header.Set("Accept", ContentTypeZcapLD)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, ctx.Err() == nil, fmt.Errorf("http resolver: failed to fetch capability %s: %w", uri, err)
+	}
+
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			logger.Warnf("failed to close response body: %s", errClose)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, fmt.Errorf("http resolver: failed to read response body: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, false, fmt.Errorf("%w: %s", ErrCapabilityNotFound, uri)
+	default:
+		return nil, resp.StatusCode >= http.StatusInternalServerError, fmt.Errorf(
+			"http resolver: unexpected response status fetching capability %s: %d %s",
+			uri, resp.StatusCode, body)
+	}
+
+	zcap := &Capability{}
+
+	err = json.Unmarshal(body, zcap)
+	if err != nil {
+		return nil, false, fmt.Errorf("http resolver: failed to unmarshal capability %s: %w", uri, err)
+	}
+
+	if !uriEqual(zcap.ID, uri) {
+		return nil, false, fmt.Errorf("http resolver: fetched capability %s instead of %s", zcap.ID, uri)
+	}
+
+	return zcap, false, nil
+}
+
+// delete the capability, reporting whether the request may be retried if it fails.
+func (h *HTTPCapabilityResolver) delete(ctx context.Context, endpoint, id string) (bool, error) {
+	req, err := h.newRequest(ctx, http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("http resolver: failed to revoke capability %s: %w", id, err)
+	}
+
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			logger.Warnf("failed to close response body: %s", errClose)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return false, nil
+	case http.StatusNotFound:
+		return false, fmt.Errorf("%w: %s", ErrCapabilityNotFound, id)
+	default:
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return true, fmt.Errorf("http resolver: failed to read response body: %w", err)
+		}
+
+		return resp.StatusCode >= http.StatusInternalServerError, fmt.Errorf(
+			"http resolver: unexpected response status revoking capability %s: %d %s",
+			id, resp.StatusCode, body)
+	}
+}
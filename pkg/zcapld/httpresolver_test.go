@@ -0,0 +1,296 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestHTTPCapabilityResolver(t *testing.T) {
+	expected := &zcapld.Capability{
+		Context:          zcapld.SecurityContextV2,
+		ID:               "urn:zcap:123",
+		Invoker:          "did:example:123",
+		InvocationTarget: zcapld.InvocationTarget{ID: "urn:target"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+			require.Equal(t, "/zcaps/"+expected.ID, r.URL.Path)
+			require.Equal(t, zcapld.ContentTypeZcapLD, r.Header.Get("Accept"))
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+			require.NoError(t, json.NewEncoder(w).Encode(expected))
+		}))
+		defer server.Close()
+
+		result, err := zcapld.NewHTTPCapabilityResolver(server.URL+"/zcaps/", server.Client(),
+			zcapld.WithHTTPHeader("Authorization", "Bearer token"),
+		).Resolve(context.Background(), expected.ID)
+		require.NoError(t, err)
+		require.Equal(t, expected, result)
+	})
+
+	t.Run("error: not found", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		_, err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client()).Resolve(
+			context.Background(), expected.ID)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+	})
+
+	t.Run("error: unexpected status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		_, err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client()).Resolve(
+			context.Background(), expected.ID)
+		require.Error(t, err)
+		require.False(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+		require.Contains(t, err.Error(), "unexpected response status fetching capability urn:zcap:123: 403 forbidden")
+	})
+
+	t.Run("error: invalid capability", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte("{"))
+			require.NoError(t, err)
+		}))
+		defer server.Close()
+
+		_, err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client()).Resolve(
+			context.Background(), expected.ID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal capability")
+	})
+
+	t.Run("error: capability with another ID", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewEncoder(w).Encode(expected))
+		}))
+		defer server.Close()
+
+		_, err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client()).Resolve(
+			context.Background(), "urn:zcap:456")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "fetched capability urn:zcap:123 instead of urn:zcap:456")
+	})
+
+	t.Run("error: server unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		_, err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client()).Resolve(
+			context.Background(), expected.ID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to fetch capability")
+	})
+
+	t.Run("success: retries server errors", func(t *testing.T) {
+		var calls int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+
+			require.NoError(t, json.NewEncoder(w).Encode(expected))
+		}))
+		defer server.Close()
+
+		result, err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client(),
+			zcapld.WithRetryPolicy(zcapld.RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}),
+		).Resolve(context.Background(), expected.ID)
+		require.NoError(t, err)
+		require.Equal(t, expected, result)
+		require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("error: retries exhausted", func(t *testing.T) {
+		var calls int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		_, err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client(),
+			zcapld.WithRetryPolicy(zcapld.RetryPolicy{MaxRetries: 1, Backoff: time.Millisecond}),
+		).Resolve(context.Background(), expected.ID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "500")
+		require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("error: client errors are not retried", func(t *testing.T) {
+		var calls int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		_, err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client(),
+			zcapld.WithRetryPolicy(zcapld.RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}),
+		).Resolve(context.Background(), expected.ID)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("error: context done while backing off", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client(),
+			zcapld.WithRetryPolicy(zcapld.RetryPolicy{MaxRetries: 3, Backoff: time.Hour}),
+		).Resolve(ctx, expected.ID)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("error: resolve timeout", func(t *testing.T) {
+		var calls int32
+
+		done := make(chan struct{})
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+		}))
+		defer server.Close()
+		defer close(done)
+
+		start := time.Now()
+
+		_, err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client(),
+			zcapld.WithResolveTimeout(50*time.Millisecond),
+			zcapld.WithRetryPolicy(zcapld.RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}),
+		).Resolve(context.Background(), expected.ID)
+		require.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+		require.Less(t, int64(time.Since(start)), int64(time.Second))
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("success: within resolve timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewEncoder(w).Encode(expected))
+		}))
+		defer server.Close()
+
+		result, err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client(),
+			zcapld.WithResolveTimeout(time.Minute),
+		).Resolve(context.Background(), expected.ID)
+		require.NoError(t, err)
+		require.Equal(t, expected, result)
+	})
+}
+
+func TestHTTPCapabilityResolver_Revoke(t *testing.T) {
+	const id = "urn:zcap:123"
+
+	t.Run("success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodDelete, r.Method)
+			require.Equal(t, "/zcaps/"+id, r.URL.Path)
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		err := zcapld.NewHTTPCapabilityResolver(server.URL+"/zcaps", server.Client(),
+			zcapld.WithHTTPHeader("Authorization", "Bearer token"),
+		).Revoke(context.Background(), id)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: not found", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		r := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client())
+
+		err := r.Revoke(context.Background(), id)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+
+		require.NoError(t, r.RevokeIfExists(context.Background(), id))
+	})
+
+	t.Run("error: unexpected status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, err := w.Write([]byte("forbidden"))
+			require.NoError(t, err)
+		}))
+		defer server.Close()
+
+		r := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client())
+
+		err := r.Revoke(context.Background(), id)
+		require.EqualError(t, err,
+			"http resolver: unexpected response status revoking capability urn:zcap:123: 403 forbidden")
+
+		require.Equal(t, err, r.RevokeIfExists(context.Background(), id))
+	})
+
+	t.Run("success: retries server errors", func(t *testing.T) {
+		var calls int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client(),
+			zcapld.WithRetryPolicy(zcapld.RetryPolicy{MaxRetries: 1, Backoff: time.Millisecond}),
+		).Revoke(context.Background(), id)
+		require.NoError(t, err)
+		require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("error: server unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		err := zcapld.NewHTTPCapabilityResolver(server.URL, server.Client()).Revoke(context.Background(), id)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to revoke capability urn:zcap:123")
+	})
+}
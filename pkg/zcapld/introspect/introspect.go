@@ -0,0 +1,93 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package introspect describes the structure of capabilities and their chains, eg. to debug authorization failures.
+// Nothing is verified.
+package introspect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+// CapabilityDescription describes a capability and its capability chain.
+type CapabilityDescription struct {
+	// ID of the capability.
+	ID string
+	// Depth is the length of the capability chain. Root capabilities have a depth of 0.
+	Depth int
+	// IsRoot reports whether this is a root capability.
+	IsRoot bool
+	// RootID is the ID of the root capability of the chain. A root capability is its own root.
+	RootID string
+	// AllowedActions are the actions allowed by the capability. Empty if it does not restrict the actions.
+	AllowedActions []string
+	// ExpiresAt is the expiry of the capability, if any. The capabilities in the chain may expire earlier.
+	ExpiresAt *time.Time
+	// Invokers are the entities authorized to invoke the capability.
+	Invokers []string
+	// Links are the IDs of the capabilities in the chain, ordered from the root capability to the parent of the
+	// capability.
+	Links []string
+}
+
+// Describe the capability and its capability chain, resolving the URIs of the chain with the resolver. Neither the
+// capabilities nor the chain are verified, and the capability is left unchanged.
+func Describe(ctx context.Context, capability *zcapld.Capability,
+	resolver zcapld.CapabilityResolver) (*CapabilityDescription, error) {
+	if capability == nil {
+		return nil, errors.New("capability is required")
+	}
+
+	chain, err := capability.ResolvedChain(ctx, resolver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve capability chain of capability %s: %w", capability.ID, err)
+	}
+
+	invokers, err := capability.Invokers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invokers of capability %s: %w", capability.ID, err)
+	}
+
+	expires, err := expiresAt(capability)
+	if err != nil {
+		return nil, err
+	}
+
+	description := &CapabilityDescription{
+		ID:             capability.ID,
+		Depth:          len(chain) - 1,
+		IsRoot:         capability.IsRoot(),
+		RootID:         chain[0].ID,
+		AllowedActions: append([]string{}, capability.AllowedAction...),
+		ExpiresAt:      expires,
+		Invokers:       invokers,
+		Links:          make([]string, 0, len(chain)-1),
+	}
+
+	for _, link := range chain[:len(chain)-1] {
+		description.Links = append(description.Links, link.ID)
+	}
+
+	return description, nil
+}
+
+func expiresAt(capability *zcapld.Capability) (*time.Time, error) {
+	if capability.ExpiresAt == "" {
+		return nil, nil
+	}
+
+	expires, err := time.Parse(time.RFC3339, capability.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expiry format on capability %s: %w", capability.ID, err)
+	}
+
+	return &expires, nil
+}
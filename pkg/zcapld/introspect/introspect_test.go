@@ -0,0 +1,89 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package introspect_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/introspect"
+	zcapldtesting "github.com/trustbloc/edge-core/pkg/zcapld/testing"
+)
+
+func TestDescribe(t *testing.T) {
+	root := zcapldtesting.NewTestCapability("urn:zcap:root", "urn:zcap:root",
+		zcapldtesting.WithController("did:example:root"))
+	parent := zcapldtesting.NewTestCapability("urn:zcap:parent", root.ID,
+		zcapldtesting.WithInvoker("did:example:parent"), zcapldtesting.WithParent(root.ID))
+	resolver := zcapld.SimpleCapabilityResolver{root.ID: root, parent.ID: parent}
+
+	t.Run("root capability", func(t *testing.T) {
+		result, err := introspect.Describe(context.Background(), root, resolver)
+		require.NoError(t, err)
+		require.Equal(t, &introspect.CapabilityDescription{
+			ID:             root.ID,
+			Depth:          0,
+			IsRoot:         true,
+			RootID:         root.ID,
+			AllowedActions: []string{},
+			Invokers:       []string{"did:example:root"},
+			Links:          []string{},
+		}, result)
+	})
+
+	t.Run("delegated capability", func(t *testing.T) {
+		expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		capability := zcapldtesting.NewTestCapability("urn:zcap:child", root.ID,
+			zcapldtesting.WithInvoker("did:example:child"),
+			zcapldtesting.WithParent(root.ID, parent.ID),
+			zcapldtesting.WithAllowedActions("read", "write"),
+			zcapldtesting.WithExpiresAt(expires),
+		)
+		clone := zcapld.Clone(capability)
+
+		result, err := introspect.Describe(context.Background(), capability, resolver)
+		require.NoError(t, err)
+		require.Equal(t, &introspect.CapabilityDescription{
+			ID:             capability.ID,
+			Depth:          2,
+			IsRoot:         false,
+			RootID:         root.ID,
+			AllowedActions: []string{"read", "write"},
+			ExpiresAt:      &expires,
+			Invokers:       []string{"did:example:child"},
+			Links:          []string{root.ID, parent.ID},
+		}, result)
+		require.True(t, zcapld.Equal(clone, capability))
+	})
+
+	t.Run("error: capability required", func(t *testing.T) {
+		_, err := introspect.Describe(context.Background(), nil, resolver)
+		require.EqualError(t, err, "capability is required")
+	})
+
+	t.Run("error: capability in the chain not found", func(t *testing.T) {
+		capability := zcapldtesting.NewTestCapability("urn:zcap:child", root.ID,
+			zcapldtesting.WithParent(root.ID, "urn:zcap:unknown"))
+
+		_, err := introspect.Describe(context.Background(), capability, resolver)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+	})
+
+	t.Run("error: invalid expiry", func(t *testing.T) {
+		capability := zcapldtesting.NewTestCapability("urn:zcap:child", root.ID)
+		capability.ExpiresAt = "tomorrow"
+
+		_, err := introspect.Describe(context.Background(), capability, resolver)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid expiry format on capability urn:zcap:child")
+	})
+}
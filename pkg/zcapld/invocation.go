@@ -0,0 +1,208 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CapabilityInvocationBuilder builds CapabilityInvocations, ensuring their required fields are set.
+// A builder can be reused: every call to Build returns a new CapabilityInvocation.
+type CapabilityInvocationBuilder struct {
+	invocation CapabilityInvocation
+}
+
+// NewCapabilityInvocationBuilder returns a new CapabilityInvocationBuilder.
+func NewCapabilityInvocationBuilder() *CapabilityInvocationBuilder {
+	return &CapabilityInvocationBuilder{}
+}
+
+// SetExpectedTarget sets the ID of the expected invocation target. Required.
+func (b *CapabilityInvocationBuilder) SetExpectedTarget(id string) *CapabilityInvocationBuilder {
+	b.invocation.ExpectedTarget = id
+
+	return b
+}
+
+// SetExpectedTargetType sets the type of the expected invocation target, eg. "urn:edv:vault". Optional.
+func (b *CapabilityInvocationBuilder) SetExpectedTargetType(targetType string) *CapabilityInvocationBuilder {
+	b.invocation.ExpectedTargetType = targetType
+
+	return b
+}
+
+// SetExpectedRootCapability sets the ID of the expected root capability. Required.
+func (b *CapabilityInvocationBuilder) SetExpectedRootCapability(id string) *CapabilityInvocationBuilder {
+	b.invocation.ExpectedRootCapability = id
+
+	return b
+}
+
+// SetExpectedAction sets the expected capability action. Required.
+func (b *CapabilityInvocationBuilder) SetExpectedAction(action string) *CapabilityInvocationBuilder {
+	b.invocation.ExpectedAction = action
+
+	return b
+}
+
+// SetExpectedAudience sets the expected audience of the capabilities. Optional.
+func (b *CapabilityInvocationBuilder) SetExpectedAudience(audience string) *CapabilityInvocationBuilder {
+	b.invocation.ExpectedAudience = audience
+
+	return b
+}
+
+// SetPurpose sets the expected proof purpose. Optional, defaults to "capabilityInvocation".
+func (b *CapabilityInvocationBuilder) SetPurpose(purpose string) *CapabilityInvocationBuilder {
+	b.invocation.Purpose = purpose
+
+	return b
+}
+
+// SetVerificationMethod sets the verification method of the invocation. Required.
+func (b *CapabilityInvocationBuilder) SetVerificationMethod(vm *VerificationMethod) *CapabilityInvocationBuilder {
+	if vm == nil {
+		b.invocation.VerificationMethod = nil
+
+		return b
+	}
+
+	b.invocation.VerificationMethod = &VerificationMethod{ID: vm.ID, Controller: vm.Controller}
+
+	return b
+}
+
+// WithTraceContext sets the trace context of the invocation, passed to the resolvers of the capability chain
+// through the context.Context of their Resolve calls. Optional.
+func (b *CapabilityInvocationBuilder) WithTraceContext(tc TraceContext) *CapabilityInvocationBuilder {
+	b.invocation.TraceContext = tc
+
+	return b
+}
+
+// WithAdditionalTargets sets the targets invoked along with the expected target, eg. to read two documents at once.
+// Each must be covered by the capabilities in the chain; see AdditionalTargetsCaveat. Optional.
+func (b *CapabilityInvocationBuilder) WithAdditionalTargets(targets ...string) *CapabilityInvocationBuilder {
+	b.invocation.AdditionalTargets = append([]string(nil), targets...)
+
+	return b
+}
+
+// Build returns a new CapabilityInvocation, or an error listing the required fields that are missing.
+func (b *CapabilityInvocationBuilder) Build() (*CapabilityInvocation, error) {
+	var missing []string
+
+	if b.invocation.ExpectedTarget == "" {
+		missing = append(missing, "expected target")
+	}
+
+	if b.invocation.ExpectedRootCapability == "" {
+		missing = append(missing, "expected root capability")
+	}
+
+	if b.invocation.ExpectedAction == "" {
+		missing = append(missing, "expected action")
+	}
+
+	if b.invocation.VerificationMethod == nil || b.invocation.VerificationMethod.ID == "" {
+		missing = append(missing, "verification method")
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required fields of capability invocation: %s", strings.Join(missing, ", "))
+	}
+
+	invocation := b.invocation
+	vm := *b.invocation.VerificationMethod
+	invocation.VerificationMethod = &vm
+
+	if b.invocation.AdditionalTargets != nil {
+		invocation.AdditionalTargets = append([]string(nil), b.invocation.AdditionalTargets...)
+	}
+
+	return &invocation, nil
+}
+
+// ProofBuilder builds invocation Proofs, ensuring their required fields are set.
+// A builder can be reused: every call to Build returns a new Proof.
+type ProofBuilder struct {
+	proof Proof
+}
+
+// NewProofBuilder returns a new ProofBuilder.
+func NewProofBuilder() *ProofBuilder {
+	return &ProofBuilder{}
+}
+
+// SetCapability sets the invoked capability. Required.
+func (b *ProofBuilder) SetCapability(c *Capability) *ProofBuilder {
+	b.proof.Capability = c
+
+	return b
+}
+
+// SetCapabilityAction sets the invoked action. Optional.
+func (b *ProofBuilder) SetCapabilityAction(action string) *ProofBuilder {
+	b.proof.CapabilityAction = action
+
+	return b
+}
+
+// SetVerificationMethod sets the verification method of the proof to the ID of 'vm'. Required.
+func (b *ProofBuilder) SetVerificationMethod(vm *VerificationMethod) *ProofBuilder {
+	b.proof.VerificationMethod = ""
+
+	if vm != nil {
+		b.proof.VerificationMethod = vm.ID
+	}
+
+	return b
+}
+
+// SetCreated sets the creation time of the proof, checked against the Verifier's maximum proof age. Optional.
+func (b *ProofBuilder) SetCreated(created time.Time) *ProofBuilder {
+	b.proof.Created = created
+
+	return b
+}
+
+// SetNonce sets the nonce of the proof, checked by the Verifier's NonceChecker. Optional.
+func (b *ProofBuilder) SetNonce(nonce string) *ProofBuilder {
+	b.proof.Nonce = nonce
+
+	return b
+}
+
+// SetChallenge sets the challenge of the proof, to be checked with VerifyChallenge. Optional.
+func (b *ProofBuilder) SetChallenge(challenge string) *ProofBuilder {
+	b.proof.Challenge = challenge
+
+	return b
+}
+
+// Build returns a new Proof, or an error listing the required fields that are missing.
+func (b *ProofBuilder) Build() (*Proof, error) {
+	var missing []string
+
+	if b.proof.Capability == nil {
+		missing = append(missing, "capability")
+	}
+
+	if b.proof.VerificationMethod == "" {
+		missing = append(missing, "verification method")
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required fields of proof: %s", strings.Join(missing, ", "))
+	}
+
+	proof := b.proof
+
+	return &proof, nil
+}
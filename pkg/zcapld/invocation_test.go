@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestCapabilityInvocationBuilder(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		vm := &zcapld.VerificationMethod{ID: "did:example:123#key-1", Controller: "did:example:123"}
+
+		result, err := zcapld.NewCapabilityInvocationBuilder().
+			SetExpectedTarget("urn:target").
+			SetExpectedTargetType("urn:edv:vault").
+			SetExpectedRootCapability("urn:zcap:root").
+			SetExpectedAction("read").
+			SetExpectedAudience("https://example.com").
+			SetPurpose(zcapld.ProofPurposeCapabilityInvocation).
+			SetVerificationMethod(vm).
+			Build()
+		require.NoError(t, err)
+		require.Equal(t, &zcapld.CapabilityInvocation{
+			ExpectedTarget:         "urn:target",
+			ExpectedTargetType:     "urn:edv:vault",
+			ExpectedAction:         "read",
+			ExpectedRootCapability: "urn:zcap:root",
+			ExpectedAudience:       "https://example.com",
+			Purpose:                zcapld.ProofPurposeCapabilityInvocation,
+			VerificationMethod:     vm,
+		}, result)
+		require.False(t, result.VerificationMethod == vm)
+	})
+
+	t.Run("success: builder can be reused", func(t *testing.T) {
+		b := zcapld.NewCapabilityInvocationBuilder().
+			SetExpectedTarget("urn:target").
+			SetExpectedRootCapability("urn:zcap:root").
+			SetExpectedAction("read").
+			SetVerificationMethod(&zcapld.VerificationMethod{ID: "did:example:123#key-1"})
+
+		first, err := b.Build()
+		require.NoError(t, err)
+
+		first.VerificationMethod.ID = "did:example:456#key-1"
+
+		second, err := b.SetExpectedAction("write").Build()
+		require.NoError(t, err)
+		require.Equal(t, "read", first.ExpectedAction)
+		require.Equal(t, "write", second.ExpectedAction)
+		require.Equal(t, "did:example:123#key-1", second.VerificationMethod.ID)
+	})
+
+	t.Run("error: missing required fields", func(t *testing.T) {
+		_, err := zcapld.NewCapabilityInvocationBuilder().SetExpectedAction("read").Build()
+		require.Error(t, err)
+		require.Contains(t, err.Error(),
+			"missing required fields of capability invocation: expected target, expected root capability, "+
+				"verification method")
+	})
+
+	t.Run("error: verification method unset", func(t *testing.T) {
+		_, err := zcapld.NewCapabilityInvocationBuilder().
+			SetExpectedTarget("urn:target").
+			SetExpectedRootCapability("urn:zcap:root").
+			SetExpectedAction("read").
+			SetVerificationMethod(&zcapld.VerificationMethod{ID: "did:example:123#key-1"}).
+			SetVerificationMethod(nil).
+			Build()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing required fields of capability invocation: verification method")
+	})
+}
+
+func TestProofBuilder(t *testing.T) {
+	c := &zcapld.Capability{ID: "urn:zcap:123"}
+	vm := &zcapld.VerificationMethod{ID: "did:example:123#key-1", Controller: "did:example:123"}
+
+	t.Run("success", func(t *testing.T) {
+		created := time.Now()
+
+		result, err := zcapld.NewProofBuilder().
+			SetCapability(c).
+			SetCapabilityAction("read").
+			SetVerificationMethod(vm).
+			SetCreated(created).
+			SetNonce("nonce").
+			SetChallenge("challenge").
+			Build()
+		require.NoError(t, err)
+		require.Equal(t, &zcapld.Proof{
+			Capability:         c,
+			CapabilityAction:   "read",
+			VerificationMethod: vm.ID,
+			Created:            created,
+			Nonce:              "nonce",
+			Challenge:          "challenge",
+		}, result)
+	})
+
+	t.Run("success: builder can be reused", func(t *testing.T) {
+		b := zcapld.NewProofBuilder().SetCapability(c).SetCapabilityAction("read").SetVerificationMethod(vm)
+
+		first, err := b.Build()
+		require.NoError(t, err)
+
+		second, err := b.SetCapabilityAction("write").Build()
+		require.NoError(t, err)
+		require.Equal(t, "read", first.CapabilityAction)
+		require.Equal(t, "write", second.CapabilityAction)
+	})
+
+	t.Run("error: missing required fields", func(t *testing.T) {
+		_, err := zcapld.NewProofBuilder().SetCapabilityAction("read").Build()
+		require.EqualError(t, err, "missing required fields of proof: capability, verification method")
+	})
+
+	t.Run("error: verification method unset", func(t *testing.T) {
+		_, err := zcapld.NewProofBuilder().SetCapability(c).SetVerificationMethod(vm).SetVerificationMethod(nil).Build()
+		require.EqualError(t, err, "missing required fields of proof: verification method")
+	})
+}
@@ -0,0 +1,111 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld/zcapcontext"
+)
+
+const (
+	securityVocab  = zcapcontext.SecurityVocabURI
+	securityPrefix = "sec:"
+)
+
+// MarshalJSONLD marshals the capability to JSON-LD in the security v2 context. The context is set if the capability
+// has none, and types in the security vocabulary are compacted to the "sec" prefix.
+func MarshalJSONLD(c *Capability) ([]byte, error) {
+	if c == nil {
+		return nil, errors.New("capability is nil")
+	}
+
+	zcap := Clone(c)
+
+	if zcap.Context == "" {
+		zcap.Context = SecurityContextV2
+	}
+
+	if zcap.Context != SecurityContextV2 {
+		return nil, fmt.Errorf("unsupported JSON-LD context: %s", zcap.Context)
+	}
+
+	zcap.InvocationTarget.Type = compactIRI(zcap.InvocationTarget.Type)
+
+	for i := range zcap.Caveats {
+		caveat, err := compactCaveatType(zcap.Caveats[i])
+		if err != nil {
+			return nil, err
+		}
+
+		zcap.Caveats[i] = caveat
+	}
+
+	raw, err := json.Marshal(zcap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal zcap: %w", err)
+	}
+
+	return raw, nil
+}
+
+// UnmarshalJSONLD unmarshals a JSON-LD capability in the security v2 context into 'c'.
+// It returns an error if the capability has keys that are not defined for capabilities.
+func UnmarshalJSONLD(data []byte, c *Capability) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	zcap := &Capability{}
+
+	err := decoder.Decode(zcap)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal zcap: %w", err)
+	}
+
+	if zcap.Context != SecurityContextV2 {
+		return fmt.Errorf("unsupported JSON-LD context: %s", zcap.Context)
+	}
+
+	*c = *zcap
+
+	return nil
+}
+
+func compactIRI(iri string) string {
+	if strings.HasPrefix(iri, securityVocab) {
+		return securityPrefix + strings.TrimPrefix(iri, securityVocab)
+	}
+
+	return iri
+}
+
+func compactCaveatType(raw json.RawMessage) (json.RawMessage, error) {
+	caveat := make(map[string]interface{})
+
+	err := json.Unmarshal(raw, &caveat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal caveat: %w", err)
+	}
+
+	typ, ok := caveat["type"].(string)
+	if !ok || !strings.HasPrefix(typ, securityVocab) {
+		return raw, nil
+	}
+
+	caveat["type"] = compactIRI(typ)
+
+	compacted, err := json.Marshal(caveat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal caveat: %w", err)
+	}
+
+	return compacted, nil
+}
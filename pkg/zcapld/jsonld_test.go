@@ -0,0 +1,167 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestMarshalJSONLD(t *testing.T) {
+	t.Run("roundtrip", func(t *testing.T) {
+		seed := time.Now().UnixNano()
+		r := rand.New(rand.NewSource(seed)) // nolint:gosec // not used for security
+
+		for i := 0; i < 200; i++ {
+			expected := randomCapability(r)
+
+			raw, err := zcapld.MarshalJSONLD(expected)
+			require.NoError(t, err, "seed=%d", seed)
+
+			result := &zcapld.Capability{}
+
+			err = zcapld.UnmarshalJSONLD(raw, result)
+			require.NoError(t, err, "seed=%d", seed)
+			require.True(t, zcapld.Equal(expected, result), "seed=%d capability=%s", seed, raw)
+		}
+	})
+
+	t.Run("sets the security v2 context", func(t *testing.T) {
+		raw, err := zcapld.MarshalJSONLD(&zcapld.Capability{ID: "urn:zcap:123"})
+		require.NoError(t, err)
+
+		result := &zcapld.Capability{}
+
+		err = zcapld.UnmarshalJSONLD(raw, result)
+		require.NoError(t, err)
+		require.Equal(t, zcapld.SecurityContextV2, result.Context)
+	})
+
+	t.Run("compacts security vocabulary types", func(t *testing.T) {
+		raw, err := zcapld.MarshalJSONLD(&zcapld.Capability{
+			ID: "urn:zcap:123",
+			InvocationTarget: zcapld.InvocationTarget{
+				ID:   "urn:zcap:123",
+				Type: "https://w3id.org/security#Target",
+			},
+			Caveats: []json.RawMessage{
+				[]byte(`{"type":"https://w3id.org/security#ExpirationCaveat","expires":"2020-01-01T00:00:00Z"}`),
+			},
+		})
+		require.NoError(t, err)
+
+		result := &zcapld.Capability{}
+
+		err = zcapld.UnmarshalJSONLD(raw, result)
+		require.NoError(t, err)
+		require.Equal(t, "sec:Target", result.InvocationTarget.Type)
+		require.JSONEq(t, `{"type":"sec:ExpirationCaveat","expires":"2020-01-01T00:00:00Z"}`, string(result.Caveats[0]))
+	})
+
+	t.Run("error: nil capability", func(t *testing.T) {
+		_, err := zcapld.MarshalJSONLD(nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "capability is nil")
+	})
+
+	t.Run("error: unsupported context", func(t *testing.T) {
+		_, err := zcapld.MarshalJSONLD(&zcapld.Capability{Context: "https://example.org/context"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported JSON-LD context")
+	})
+
+	t.Run("error: invalid caveat", func(t *testing.T) {
+		_, err := zcapld.MarshalJSONLD(&zcapld.Capability{Caveats: []json.RawMessage{[]byte(`[]`)}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal caveat")
+	})
+}
+
+func TestUnmarshalJSONLD(t *testing.T) {
+	t.Run("error: unknown key", func(t *testing.T) {
+		err := zcapld.UnmarshalJSONLD(
+			[]byte(`{"@context":"https://w3id.org/security/v2","id":"urn:zcap:123","unknown":"value"}`),
+			&zcapld.Capability{},
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `unknown field "unknown"`)
+	})
+
+	t.Run("error: unsupported context", func(t *testing.T) {
+		err := zcapld.UnmarshalJSONLD(
+			[]byte(`{"@context":"https://example.org/context","id":"urn:zcap:123"}`),
+			&zcapld.Capability{},
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported JSON-LD context")
+	})
+
+	t.Run("error: malformed", func(t *testing.T) {
+		err := zcapld.UnmarshalJSONLD([]byte(`{`), &zcapld.Capability{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal zcap")
+	})
+}
+
+// randomCapability generates a valid capability with random optional fields.
+func randomCapability(r *rand.Rand) *zcapld.Capability {
+	id := func() string {
+		return fmt.Sprintf("urn:zcap:%d", r.Int63())
+	}
+
+	maybe := func(f func() string) string {
+		if r.Intn(2) == 0 {
+			return ""
+		}
+
+		return f()
+	}
+
+	c := &zcapld.Capability{
+		Context:    zcapld.SecurityContextV2,
+		ID:         id(),
+		Invoker:    maybe(id),
+		Controller: maybe(id),
+		Delegator:  maybe(id),
+		Parent:     maybe(id),
+		ExpiresAt: maybe(func() string {
+			return time.Unix(r.Int63n(1<<32), 0).UTC().Format(time.RFC3339)
+		}),
+		InvocationTarget: zcapld.InvocationTarget{
+			ID:   id(),
+			Type: maybe(func() string { return fmt.Sprintf("sec:Type%d", r.Intn(100)) }),
+		},
+	}
+
+	for i := r.Intn(3); i > 0; i-- {
+		c.AllowedAction = append(c.AllowedAction, fmt.Sprintf("action%d", r.Intn(100)))
+	}
+
+	for i := r.Intn(3); i > 0; i-- {
+		c.Caveats = append(c.Caveats,
+			json.RawMessage(fmt.Sprintf(`{"expires":"%d","type":"sec:Caveat%d"}`, r.Int63(), r.Intn(100))))
+	}
+
+	for i := r.Intn(3); i > 0; i-- {
+		c.Proof = append(c.Proof, verifiable.Proof{
+			"type":               "Ed25519Signature2018",
+			"proofPurpose":       zcapld.ProofPurpose,
+			"verificationMethod": id(),
+			"capabilityChain":    []interface{}{id(), id()},
+		})
+	}
+
+	return c
+}
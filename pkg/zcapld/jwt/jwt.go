@@ -0,0 +1,253 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package jwt extracts capability invocation proofs from JWTs.
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	ariesjwt "github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/middleware"
+)
+
+const (
+	algEdDSA = "EdDSA"
+	algRS256 = "RS256"
+)
+
+// JWTKeyProvider provides the public keys verifying the JWTs signed by verification methods. zcapld.KeyResolvers,
+// eg. zcapld.DIDKeyResolver, are JWTKeyProviders.
+type JWTKeyProvider interface {
+	// Resolve the public key of the verification method.
+	Resolve(verificationMethod string) (*verifier.PublicKey, error)
+}
+
+// Claims are the claims of a JWT-encoded capability invocation proof.
+type Claims struct {
+	// Capability is the invoked capability, or its ID.
+	Capability json.RawMessage `json:"capability"`
+	// CapabilityAction is the invoked action. Defaults to the action of the request method.
+	CapabilityAction string `json:"capabilityAction,omitempty"`
+	// VerificationMethod is the verification method that signed the JWT.
+	VerificationMethod string `json:"verificationMethod"`
+	// IssuedAt is the creation time of the proof, in seconds since the Unix epoch.
+	IssuedAt int64 `json:"iat,omitempty"`
+	// Expiry is the time after which the JWT is rejected, in seconds since the Unix epoch. JWTs without it are
+	// rejected.
+	Expiry int64 `json:"exp"`
+	// Nonce is the nonce of the proof.
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// Option configures the ProofExtractor returned by JWTProofExtractor.
+type Option func(*jwtExtractor)
+
+// WithCapabilityResolver sets the resolver of the capabilities the JWTs refer to by ID.
+func WithCapabilityResolver(r zcapld.CapabilityResolver) Option {
+	return func(e *jwtExtractor) {
+		e.resolver = r
+	}
+}
+
+// WithExpectations sets the parameters to expect of the invocations.
+func WithExpectations(expect *zcapld.InvocationExpectations) Option {
+	return func(e *jwtExtractor) {
+		e.expect = expect
+	}
+}
+
+// WithMethodActions sets the function that maps the method of a request to the capability action it invokes.
+// Defaults to middleware.MethodAction.
+func WithMethodActions(action func(method string) string) Option {
+	return func(e *jwtExtractor) {
+		e.action = action
+	}
+}
+
+// WithClock sets the clock used to check the expiry of the JWTs. Defaults to time.Now.
+func WithClock(clock func() time.Time) Option {
+	return func(e *jwtExtractor) {
+		e.clock = clock
+	}
+}
+
+// JWTProofExtractor returns a middleware.ProofExtractor for requests authenticated with a JWT bearer token in the
+// Authorization header. The JWT claims map to the fields of the proof (see Claims), and its signature is verified
+// with the key of the claimed verification method returned by the provider. The "kid" header, if any, must be the
+// verification method, and the JWT must not have expired: its "exp" claim is required. EdDSA and RS256 signatures are
+// supported.
+func JWTProofExtractor(provider JWTKeyProvider, opts ...Option) middleware.ProofExtractor {
+	e := &jwtExtractor{
+		keys:     provider,
+		resolver: zcapld.SimpleCapabilityResolver{},
+		expect:   &zcapld.InvocationExpectations{},
+		action:   middleware.MethodAction,
+		clock:    time.Now,
+	}
+
+	for i := range opts {
+		opts[i](e)
+	}
+
+	return e
+}
+
+type jwtExtractor struct {
+	keys     JWTKeyProvider
+	resolver zcapld.CapabilityResolver
+	expect   *zcapld.InvocationExpectations
+	action   func(method string) string
+	clock    func() time.Time
+}
+
+func (e *jwtExtractor) Extract(r *http.Request) (*zcapld.Proof, *zcapld.CapabilityInvocation, error) {
+	token, err := middleware.BearerToken(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse Authorization header: %w", err)
+	}
+
+	parsed, err := ariesjwt.Parse(token, ariesjwt.WithSignatureVerifier(jose.SignatureVerifierFunc(e.verify)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to verify jwt: %w", err)
+	}
+
+	claims, err := decodeClaims(parsed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if claims.Expiry == 0 {
+		return nil, nil, errors.New("jwt has no exp claim")
+	}
+
+	if e.clock().After(time.Unix(claims.Expiry, 0)) {
+		return nil, nil, fmt.Errorf("jwt expired at %s", time.Unix(claims.Expiry, 0).UTC().Format(time.RFC3339))
+	}
+
+	capability, err := e.capability(r, claims.Capability)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	action := e.action(r.Method)
+
+	proof := &zcapld.Proof{
+		Capability:         capability,
+		CapabilityAction:   claims.CapabilityAction,
+		VerificationMethod: claims.VerificationMethod,
+		Nonce:              claims.Nonce,
+	}
+
+	if proof.CapabilityAction == "" {
+		proof.CapabilityAction = action
+	}
+
+	if claims.IssuedAt != 0 {
+		proof.Created = time.Unix(claims.IssuedAt, 0)
+	}
+
+	invocation := &zcapld.CapabilityInvocation{
+		ExpectedTarget:         e.expect.Target,
+		ExpectedAction:         e.expect.Action,
+		ExpectedRootCapability: e.expect.RootCapability,
+		VerificationMethod: &zcapld.VerificationMethod{
+			ID:         claims.VerificationMethod,
+			Controller: claims.VerificationMethod,
+		},
+	}
+
+	if invocation.ExpectedAction == "" {
+		invocation.ExpectedAction = action
+	}
+
+	return proof, invocation, nil
+}
+
+// verify the JWS signature with the key of the verification method claimed in the payload.
+func (e *jwtExtractor) verify(headers jose.Headers, payload, signingInput, signature []byte) error {
+	claims := &Claims{}
+
+	err := json.Unmarshal(payload, claims)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal jwt claims: %w", err)
+	}
+
+	if claims.VerificationMethod == "" {
+		return errors.New("jwt has no verificationMethod claim")
+	}
+
+	if kid, ok := headers.KeyID(); ok && kid != claims.VerificationMethod {
+		return fmt.Errorf(`jwt kid "%s" does not match the verificationMethod claim "%s"`,
+			kid, claims.VerificationMethod)
+	}
+
+	alg, _ := headers.Algorithm()
+
+	var verifySignature func(*verifier.PublicKey, []byte, []byte) error
+
+	switch alg {
+	case algEdDSA:
+		verifySignature = ariesjwt.VerifyEdDSA
+	case algRS256:
+		verifySignature = ariesjwt.VerifyRS256
+	default:
+		return fmt.Errorf("unsupported jwt alg: %s", alg)
+	}
+
+	key, err := e.keys.Resolve(claims.VerificationMethod)
+	if err != nil {
+		return fmt.Errorf("failed to resolve key of verification method %s: %w", claims.VerificationMethod, err)
+	}
+
+	return verifySignature(key, signingInput, signature)
+}
+
+// capability returns the capability of the claim, resolving it if the claim is its ID.
+func (e *jwtExtractor) capability(r *http.Request, claim json.RawMessage) (*zcapld.Capability, error) {
+	var id string
+
+	if json.Unmarshal(claim, &id) == nil {
+		capability, err := e.resolver.Resolve(r.Context(), id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve capability %s: %w", id, err)
+		}
+
+		return capability, nil
+	}
+
+	capability := &zcapld.Capability{}
+
+	err := json.Unmarshal(claim, capability)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal capability claim: %w", err)
+	}
+
+	return capability, nil
+}
+
+func decodeClaims(token *ariesjwt.JSONWebToken) (*Claims, error) {
+	claims := &Claims{}
+
+	err := token.DecodeClaims(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal jwt claims: %w", err)
+	}
+
+	if len(claims.Capability) == 0 {
+		return nil, errors.New("jwt has no capability claim")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,304 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jwt_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	ariesjwt "github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/jwt"
+)
+
+const vm = "did:example:123#key-1"
+
+func TestJWTProofExtractor(t *testing.T) {
+	signer := newSigner(t, vm)
+	keys := zcapld.SimpleKeyResolver{vm: signer.publicKey()}
+	capability := &zcapld.Capability{
+		Context:          zcapld.SecurityContextV2,
+		ID:               "urn:zcap:123",
+		Invoker:          vm,
+		InvocationTarget: zcapld.InvocationTarget{ID: "urn:target"},
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	t.Run("success: embedded capability", func(t *testing.T) {
+		iat := time.Now().Add(-time.Minute).Unix()
+		r := request(t, http.MethodPost, signer, map[string]interface{}{
+			"capability":         capability,
+			"verificationMethod": vm,
+			"iat":                iat,
+			"exp":                exp,
+			"nonce":              "abc",
+		})
+
+		proof, invocation, err := jwt.JWTProofExtractor(keys,
+			jwt.WithExpectations(&zcapld.InvocationExpectations{Target: "urn:target", RootCapability: capability.ID}),
+		).Extract(r)
+		require.NoError(t, err)
+		require.Equal(t, &zcapld.Proof{
+			Capability:         capability,
+			CapabilityAction:   "write",
+			VerificationMethod: vm,
+			Created:            time.Unix(iat, 0),
+			Nonce:              "abc",
+		}, proof)
+		require.Equal(t, &zcapld.CapabilityInvocation{
+			ExpectedTarget:         "urn:target",
+			ExpectedAction:         "write",
+			ExpectedRootCapability: capability.ID,
+			VerificationMethod:     &zcapld.VerificationMethod{ID: vm, Controller: vm},
+		}, invocation)
+	})
+
+	t.Run("success: capability ID", func(t *testing.T) {
+		r := request(t, http.MethodGet, signer, map[string]interface{}{
+			"capability":         capability.ID,
+			"capabilityAction":   "read",
+			"verificationMethod": vm,
+			"exp":                exp,
+		})
+
+		proof, invocation, err := jwt.JWTProofExtractor(keys,
+			jwt.WithCapabilityResolver(zcapld.SimpleCapabilityResolver{capability.ID: capability}),
+		).Extract(r)
+		require.NoError(t, err)
+		require.Equal(t, capability, proof.Capability)
+		require.Equal(t, "read", proof.CapabilityAction)
+		require.True(t, proof.Created.IsZero())
+		require.Equal(t, "read", invocation.ExpectedAction)
+	})
+
+	t.Run("success: custom method actions", func(t *testing.T) {
+		r := request(t, http.MethodDelete, signer, map[string]interface{}{
+			"capability":         capability,
+			"verificationMethod": vm,
+			"exp":                exp,
+		})
+
+		proof, invocation, err := jwt.JWTProofExtractor(keys,
+			jwt.WithMethodActions(func(method string) string { return method }),
+		).Extract(r)
+		require.NoError(t, err)
+		require.Equal(t, http.MethodDelete, proof.CapabilityAction)
+		require.Equal(t, http.MethodDelete, invocation.ExpectedAction)
+	})
+
+	t.Run("error: invalid claims or signature", func(t *testing.T) {
+		other := newSigner(t, vm)
+		tests := []struct {
+			name   string
+			signer *testSigner
+			claims map[string]interface{}
+			err    string
+		}{
+			{
+				name:   "signed with another key",
+				signer: other,
+				claims: map[string]interface{}{"capability": capability, "verificationMethod": vm, "exp": exp},
+				err:    "failed to verify jwt",
+			},
+			{
+				name:   "kid does not match the verification method",
+				signer: newSigner(t, "did:example:456#key-1"),
+				claims: map[string]interface{}{"capability": capability, "verificationMethod": vm, "exp": exp},
+				err:    `jwt kid "did:example:456#key-1" does not match the verificationMethod claim "` + vm + `"`,
+			},
+			{
+				name:   "unknown verification method",
+				signer: newSigner(t, ""),
+				claims: map[string]interface{}{
+					"capability":         capability,
+					"verificationMethod": "did:example:456",
+					"exp":                exp,
+				},
+				err: "failed to resolve key of verification method did:example:456",
+			},
+			{
+				name:   "no verification method",
+				signer: signer,
+				claims: map[string]interface{}{"capability": capability, "exp": exp},
+				err:    "jwt has no verificationMethod claim",
+			},
+			{
+				name:   "no capability",
+				signer: signer,
+				claims: map[string]interface{}{"verificationMethod": vm, "exp": exp},
+				err:    "jwt has no capability claim",
+			},
+			{
+				name:   "unresolved capability",
+				signer: signer,
+				claims: map[string]interface{}{"capability": "urn:zcap:456", "verificationMethod": vm, "exp": exp},
+				err:    "failed to resolve capability urn:zcap:456",
+			},
+			{
+				name:   "invalid capability",
+				signer: signer,
+				claims: map[string]interface{}{"capability": []string{"invalid"}, "verificationMethod": vm, "exp": exp},
+				err:    "failed to unmarshal capability claim",
+			},
+			{
+				name:   "expired",
+				signer: signer,
+				claims: map[string]interface{}{
+					"capability":         capability,
+					"verificationMethod": vm,
+					"exp":                time.Now().Add(-time.Minute).Unix(),
+				},
+				err: "jwt expired at",
+			},
+			{
+				name:   "no exp",
+				signer: signer,
+				claims: map[string]interface{}{"capability": capability, "verificationMethod": vm},
+				err:    "jwt has no exp claim",
+			},
+		}
+
+		for i := range tests {
+			tc := tests[i]
+
+			t.Run(tc.name, func(t *testing.T) {
+				_, _, err := jwt.JWTProofExtractor(keys).Extract(request(t, http.MethodGet, tc.signer, tc.claims))
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+			})
+		}
+	})
+
+	t.Run("error: expired at the time of the clock", func(t *testing.T) {
+		r := request(t, http.MethodGet, signer, map[string]interface{}{
+			"capability":         capability,
+			"verificationMethod": vm,
+			"exp":                exp,
+		})
+
+		_, _, err := jwt.JWTProofExtractor(keys,
+			jwt.WithClock(func() time.Time { return time.Unix(exp, 0).Add(time.Second) }),
+		).Extract(r)
+		require.EqualError(t, err, "jwt expired at "+time.Unix(exp, 0).UTC().Format(time.RFC3339))
+	})
+
+	t.Run("error: unsupported alg", func(t *testing.T) {
+		token, err := ariesjwt.NewUnsecured(map[string]interface{}{
+			"capability":         capability,
+			"verificationMethod": vm,
+			"exp":                exp,
+		}, nil)
+		require.NoError(t, err)
+
+		serialized, err := token.Serialize(false)
+		require.NoError(t, err)
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+serialized)
+
+		_, _, err = jwt.JWTProofExtractor(keys).Extract(r)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported jwt alg: none")
+	})
+
+	t.Run("error: invalid Authorization header", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		_, _, err := jwt.JWTProofExtractor(keys).Extract(r)
+		require.EqualError(t, err, "failed to parse Authorization header: header is missing")
+
+		r.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+
+		_, _, err = jwt.JWTProofExtractor(keys).Extract(r)
+		require.EqualError(t, err, "failed to parse Authorization header: not a bearer token")
+
+		r.Header.Set("Authorization", "Bearer not-a-jwt")
+
+		_, _, err = jwt.JWTProofExtractor(keys).Extract(r)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to verify jwt")
+	})
+
+	t.Run("error: key provider fails", func(t *testing.T) {
+		r := request(t, http.MethodGet, signer, map[string]interface{}{
+			"capability":         capability,
+			"verificationMethod": vm,
+			"exp":                exp,
+		})
+
+		_, _, err := jwt.JWTProofExtractor(&mockKeyProvider{err: errors.New("test")}).Extract(r)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to resolve key of verification method "+vm+": test")
+	})
+}
+
+func request(t *testing.T, method string, signer *testSigner, claims map[string]interface{}) *http.Request {
+	t.Helper()
+
+	token, err := ariesjwt.NewSigned(claims, nil, signer)
+	require.NoError(t, err)
+
+	serialized, err := token.Serialize(false)
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(method, "/documents/1", nil)
+	r.Header.Set("Authorization", "Bearer "+serialized)
+
+	return r
+}
+
+type testSigner struct {
+	kid  string
+	priv ed25519.PrivateKey
+}
+
+func newSigner(t *testing.T, kid string) *testSigner {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return &testSigner{kid: kid, priv: priv}
+}
+
+func (s *testSigner) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(s.priv, data), nil
+}
+
+func (s *testSigner) Headers() jose.Headers {
+	headers := jose.Headers{jose.HeaderAlgorithm: "EdDSA"}
+
+	if s.kid != "" {
+		headers[jose.HeaderKeyID] = s.kid
+	}
+
+	return headers
+}
+
+func (s *testSigner) publicKey() *verifier.PublicKey {
+	return &verifier.PublicKey{
+		Type:  "Ed25519VerificationKey2018",
+		Value: s.priv.Public().(ed25519.PublicKey),
+	}
+}
+
+type mockKeyProvider struct {
+	err error
+}
+
+func (m *mockKeyProvider) Resolve(string) (*verifier.PublicKey, error) {
+	return nil, m.err
+}
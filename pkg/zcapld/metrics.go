@@ -0,0 +1,45 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import "time"
+
+// Reasons for verification errors recorded with VerifierMetrics.
+const (
+	reasonNonce             = "nonce"
+	reasonMissingCapability = "missing_capability"
+	reasonProofAge          = "proof_age"
+	reasonProofPurpose      = "proof_purpose"
+	reasonCapabilityChain   = "capability_chain"
+	reasonInvoker           = "invoker"
+	reasonController        = "controller"
+	reasonProof             = "proof"
+	reasonPolicy            = "policy"
+)
+
+// VerifierMetrics records metrics on the verification of capability invocations.
+type VerifierMetrics interface {
+	// RecordVerifyDuration records the time taken to verify an invocation.
+	RecordVerifyDuration(d time.Duration)
+	// RecordChainDepth records the depth of the capability chain verified, where 0 is a root capability.
+	RecordChainDepth(n int)
+	// RecordVerifyError records a failed verification. The reason is one of a small, fixed set of labels,
+	// eg. "capability_chain" or "proof".
+	RecordVerifyError(reason string)
+}
+
+// NoopMetrics is a VerifierMetrics that discards all metrics.
+type NoopMetrics struct{}
+
+// RecordVerifyDuration does nothing.
+func (NoopMetrics) RecordVerifyDuration(time.Duration) {}
+
+// RecordChainDepth does nothing.
+func (NoopMetrics) RecordChainDepth(int) {}
+
+// RecordVerifyError does nothing.
+func (NoopMetrics) RecordVerifyError(string) {}
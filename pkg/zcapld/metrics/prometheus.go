@@ -0,0 +1,77 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package metrics provides implementations of zcapld.VerifierMetrics.
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "zcapld"
+	subsystem = "verifier"
+)
+
+// PrometheusMetrics records zcapld.VerifierMetrics with Prometheus counters and histograms.
+type PrometheusMetrics struct {
+	duration prometheus.Histogram
+	depth    prometheus.Histogram
+	errors   *prometheus.CounterVec
+}
+
+// NewPrometheusMetrics returns a new PrometheusMetrics with its collectors registered with the registerer.
+func NewPrometheusMetrics(registerer prometheus.Registerer) (*PrometheusMetrics, error) {
+	m := &PrometheusMetrics{
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "verify_duration_seconds",
+			Help:      "The time taken to verify capability invocations.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		depth: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "chain_depth",
+			Help:      "The depth of the capability chains verified.",
+			Buckets:   prometheus.LinearBuckets(0, 1, 10), // nolint:gomnd // chains of depth 0 to 9
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "verify_errors_total",
+			Help:      "The number of failed capability invocation verifications.",
+		}, []string{"reason"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.duration, m.depth, m.errors} {
+		err := registerer.Register(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to register collector: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// RecordVerifyDuration observes the duration in seconds.
+func (m *PrometheusMetrics) RecordVerifyDuration(d time.Duration) {
+	m.duration.Observe(d.Seconds())
+}
+
+// RecordChainDepth observes the chain depth.
+func (m *PrometheusMetrics) RecordChainDepth(n int) {
+	m.depth.Observe(float64(n))
+}
+
+// RecordVerifyError increments the error count for the reason.
+func (m *PrometheusMetrics) RecordVerifyError(reason string) {
+	m.errors.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,84 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/metrics"
+)
+
+var _ zcapld.VerifierMetrics = (*metrics.PrometheusMetrics)(nil)
+
+func TestNewPrometheusMetrics(t *testing.T) {
+	t.Run("registers collectors", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		m, err := metrics.NewPrometheusMetrics(registry)
+		require.NoError(t, err)
+
+		m.RecordVerifyDuration(time.Second)
+		m.RecordChainDepth(2)
+		m.RecordVerifyError("proof")
+		m.RecordVerifyError("proof")
+
+		families, err := registry.Gather()
+		require.NoError(t, err)
+
+		names := make([]string, len(families))
+		for i := range families {
+			names[i] = families[i].GetName()
+		}
+
+		require.ElementsMatch(t, []string{
+			"zcapld_verifier_verify_duration_seconds",
+			"zcapld_verifier_chain_depth",
+			"zcapld_verifier_verify_errors_total",
+		}, names)
+	})
+
+	t.Run("counts errors by reason", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		m, err := metrics.NewPrometheusMetrics(registry)
+		require.NoError(t, err)
+
+		m.RecordVerifyError("proof")
+		m.RecordVerifyError("proof")
+		m.RecordVerifyError("invoker")
+
+		families, err := registry.Gather()
+		require.NoError(t, err)
+
+		counts := make(map[string]float64)
+
+		for _, family := range families {
+			if family.GetName() != "zcapld_verifier_verify_errors_total" {
+				continue
+			}
+
+			for _, metric := range family.GetMetric() {
+				counts[metric.GetLabel()[0].GetValue()] = metric.GetCounter().GetValue()
+			}
+		}
+
+		require.Equal(t, map[string]float64{"proof": 2, "invoker": 1}, counts)
+	})
+
+	t.Run("error: collectors already registered", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		_, err := metrics.NewPrometheusMetrics(registry)
+		require.NoError(t, err)
+
+		_, err = metrics.NewPrometheusMetrics(registry)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to register collector")
+	})
+}
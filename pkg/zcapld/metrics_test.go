@@ -0,0 +1,99 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestWithMetrics(t *testing.T) {
+	t.Run("records successful verifications", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 1)
+		parent := chain[len(chain)-1]
+		capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(parent.zcap.ID),
+			withVerMethod(keyID(parent.signer)), withCapabilityChain([]interface{}{root.ID, parent.zcap.ID}))
+		m := &mockMetrics{}
+		err := verifier(t, chainResolver(root, chain), chainKeyResolver(t, rootSigner, chain),
+			zcapld.WithMetrics(m),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.NoError(t, err)
+		require.Len(t, m.durations, 1)
+		require.Equal(t, []int{2}, m.depths)
+		require.Empty(t, m.errors)
+	})
+
+	t.Run("records the reason for failed verifications", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(rootSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}))
+		m := &mockMetrics{}
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{},
+			zcapld.WithMetrics(m),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Len(t, m.durations, 1)
+		require.Equal(t, []int{1}, m.depths)
+		require.Equal(t, []string{"proof"}, m.errors)
+	})
+
+	t.Run("records failures without a capability chain", func(t *testing.T) {
+		m := &mockMetrics{}
+		err := verifier(t, zcapld.SimpleCapabilityResolver{}, zcapld.SimpleKeyResolver{}, zcapld.WithMetrics(m)).
+			Verify(context.Background(), &zcapld.Proof{}, invocation("did:example:123"))
+		require.Error(t, err)
+		require.Len(t, m.durations, 1)
+		require.Empty(t, m.depths)
+		require.Equal(t, []string{"missing_capability"}, m.errors)
+	})
+}
+
+type mockMetrics struct {
+	durations []time.Duration
+	depths    []int
+	errors    []string
+}
+
+func (m *mockMetrics) RecordVerifyDuration(d time.Duration) {
+	m.durations = append(m.durations, d)
+}
+
+func (m *mockMetrics) RecordChainDepth(n int) {
+	m.depths = append(m.depths, n)
+}
+
+func (m *mockMetrics) RecordVerifyError(reason string) {
+	m.errors = append(m.errors, reason)
+}
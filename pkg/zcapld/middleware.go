@@ -18,14 +18,28 @@ import (
 )
 
 const (
-	// CapabilityInvocationHTTPHeader is the HTTP header expected on zcap'ed HTTP requests.
+	// CapabilityInvocationHTTPHeader is the HTTP header expected on zcap'ed HTTP requests, as listed among the
+	// headers covered by their HTTP signatures.
 	CapabilityInvocationHTTPHeader = "capability-invocation"
-	signatureHeader                = "signature"
+	invocationScheme               = "zcap"
 	capabilityParam                = "capability"
+	idParam                        = "id"
 	actionParam                    = "action"
 	keyIDParam                     = "keyId"
 )
 
+const (
+	// HeaderCapabilityInvocation is the canonical name of the capability invocation HTTP header. Header names are
+	// case-insensitive: it is the same header as CapabilityInvocationHTTPHeader, the lowercase name that HTTP
+	// signatures list among the signed headers.
+	HeaderCapabilityInvocation = "Capability-Invocation"
+	// HeaderCapabilityInvocationSignature is the canonical name of the HTTP header of the HTTP signatures of
+	// capability invocations.
+	HeaderCapabilityInvocationSignature = "Signature"
+	// ContentTypeZcapLD is the content type of capabilities serialized in JSON-LD.
+	ContentTypeZcapLD = `application/ld+json; profile="https://w3id.org/security#"`
+)
+
 // HTTPSigAuthConfig configures the HTTP auth handler.
 type HTTPSigAuthConfig struct {
 	CapabilityResolver CapabilityResolver
@@ -35,6 +49,16 @@ type HTTPSigAuthConfig struct {
 	ErrConsumer        func(error)
 }
 
+// InvocationHeader holds the parameters of the capability-invocation header of a request.
+type InvocationHeader struct {
+	// Capability is the capability embedded in the "capability" parameter, gzipped and base64URL-encoded.
+	Capability *Capability
+	// CapabilityID is the ID of the invoked capability in the "id" parameter.
+	CapabilityID string
+	// Action is the invoked action in the "action" parameter.
+	Action string
+}
+
 // InvocationExpectations are set by the application's context as parameters to expect for any given invocation.
 type InvocationExpectations struct {
 	Target         string
@@ -86,6 +110,7 @@ func authZHandleFunc(w http.ResponseWriter, r *http.Request,
 	}
 
 	err = verifier.Verify(
+		r.Context(),
 		&Proof{
 			Capability:         zcap,
 			CapabilityAction:   action,
@@ -122,7 +147,7 @@ func parseProofParams(r *http.Request) (zcap *Capability, keyID, action string,
 		return nil, "", "", fmt.Errorf("failed to parse capability-invocation header: %w", err)
 	}
 
-	keyID, err = parseKeyID(r)
+	keyID, err = ParseSignatureKeyID(r)
 	if err != nil {
 		return nil, "", "", fmt.Errorf("failed to parse keyID: %w", err)
 	}
@@ -130,56 +155,64 @@ func parseProofParams(r *http.Request) (zcap *Capability, keyID, action string,
 	return zcap, keyID, action, nil
 }
 
-// assume the same format as the Bearer authentication scheme:
-// https://tools.ietf.org/html/rfc6750#section-2.1
 func parseInvocationHeader(r *http.Request) (*Capability, string, error) {
-	value := strings.TrimSpace(strings.Join(r.Header.Values(CapabilityInvocationHTTPHeader), ", "))
+	header, err := ParseInvocationHeader(r)
+	if err != nil {
+		return nil, "", err
+	}
 
-	if value == "" {
-		return nil, "", fmt.Errorf(`"%s" header is missing`, CapabilityInvocationHTTPHeader)
+	if header.Action == "" {
+		return nil, "", fmt.Errorf(`"%s" header is missing`, actionParam)
 	}
 
-	scheme := strings.ToLower(value[:4])
+	return header.Capability, header.Action, nil
+}
+
+// ParseInvocationHeader parses the capability-invocation header of the request. The header has the format of the
+// Bearer authentication scheme, https://tools.ietf.org/html/rfc6750#section-2.1, with the "zcap" scheme and the
+// quoted-string parameters "capability", "id" and "action", eg. `zcap id="urn:zcap:123",action="read"`. The
+// parameters absent from the header are left empty.
+func ParseInvocationHeader(r *http.Request) (*InvocationHeader, error) {
+	value := strings.TrimSpace(strings.Join(r.Header.Values(HeaderCapabilityInvocation), ", "))
 
-	if scheme != "zcap" {
-		return nil, "", fmt.Errorf("invalid invocation scheme: %s", scheme)
+	if value == "" {
+		return nil, fmt.Errorf(`"%s" header is missing`, CapabilityInvocationHTTPHeader)
 	}
 
-	zcap, action, err := parseInvocation(value[5:])
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to parse invocation header: %w", err)
+	i := strings.IndexByte(value, ' ')
+	if i < 0 || !strings.EqualFold(value[:i], invocationScheme) {
+		return nil, fmt.Errorf("invalid invocation scheme: %s", value)
 	}
 
-	if action == "" {
-		return nil, "", fmt.Errorf(`"%s" header is missing`, actionParam)
+	header, err := parseInvocation(value[i+1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse invocation header: %w", err)
 	}
 
-	return zcap, action, nil
+	return header, nil
 }
 
 // TODO make algorithm more robust: https://github.com/trustbloc/edge-core/issues/102.
-func parseInvocation(invocation string) (*Capability, string, error) {
+func parseInvocation(invocation string) (*InvocationHeader, error) {
 	const (
 		equalityOp = "="
 		delim      = ","
 		numParts   = 2
 	)
 
-	var (
-		zcap   *Capability
-		action string
-		err    error
-	)
+	header := &InvocationHeader{}
+
+	var err error
 
 	keyValues := strings.Split(invocation, delim)
 
 	for i := range keyValues {
 		kv := strings.SplitN(keyValues[i], equalityOp, numParts)
 		if len(kv) != numParts {
-			return nil, "", fmt.Errorf("invalid key=value format: %s", keyValues[i])
+			return nil, fmt.Errorf("invalid key=value format: %s", keyValues[i])
 		}
 
-		k := kv[0]
+		k := strings.TrimSpace(kv[0])
 		v := kv[1]
 
 		switch k {
@@ -188,24 +221,29 @@ func parseInvocation(invocation string) (*Capability, string, error) {
 
 			str, err = parseQuotedString(v)
 			if err != nil {
-				return nil, "", fmt.Errorf("'capability' invocation header param value is not a quoted-string: %w", err)
+				return nil, fmt.Errorf("'capability' invocation header param value is not a quoted-string: %w", err)
 			}
 
-			zcap, err = parseCapability(str)
+			header.Capability, err = parseCapability(str)
 			if err != nil {
-				return nil, "", fmt.Errorf("failed to parse capability invocation header param value: %w", err)
+				return nil, fmt.Errorf("failed to parse capability invocation header param value: %w", err)
+			}
+		case idParam:
+			header.CapabilityID, err = parseQuotedString(v)
+			if err != nil {
+				return nil, fmt.Errorf("'id' invocation header param value is not a quoted-string: %w", err)
 			}
 		case actionParam:
-			action, err = parseQuotedString(v)
+			header.Action, err = parseQuotedString(v)
 			if err != nil {
-				return nil, "", fmt.Errorf("'action' invocation header param value is not a quoted-string: %w", err)
+				return nil, fmt.Errorf("'action' invocation header param value is not a quoted-string: %w", err)
 			}
 		default:
-			return nil, "", fmt.Errorf("unrecognized invocation header param: k=%s v=%s", k, v)
+			return nil, fmt.Errorf("unrecognized invocation header param: k=%s v=%s", k, v)
 		}
 	}
 
-	return zcap, action, nil
+	return header, nil
 }
 
 func parseQuotedString(value string) (string, error) {
@@ -253,24 +291,25 @@ func parseCapability(value string) (zcap *Capability, err error) {
 	return zcap, err
 }
 
+// ParseSignatureKeyID returns the quoted-string keyId parameter of the request's HTTP signature header.
 // TODO refactor this algorithm: https://github.com/trustbloc/edge-core/issues/104.
-func parseKeyID(r *http.Request) (string, error) {
+func ParseSignatureKeyID(r *http.Request) (string, error) {
 	const (
 		numParts   = 2
 		delim      = ","
 		equalityOp = "="
 	)
 
-	value := strings.Join(r.Header.Values(signatureHeader), fmt.Sprintf("%s ", delim))
+	value := strings.Join(r.Header.Values(HeaderCapabilityInvocationSignature), fmt.Sprintf("%s ", delim))
 	keyValues := strings.Split(value, delim)
 
 	for i := range keyValues {
-		kv := strings.Split(keyValues[i], equalityOp)
+		kv := strings.SplitN(keyValues[i], equalityOp, numParts)
 		if len(kv) != numParts {
 			return "", fmt.Errorf("malformed signature header param: %s", keyValues[i])
 		}
 
-		k := kv[0]
+		k := strings.TrimSpace(kv[0])
 		v := kv[1]
 
 		if k == keyIDParam {
@@ -283,5 +322,5 @@ func parseKeyID(r *http.Request) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("no %s parameter found for %s header", keyIDParam, signatureHeader)
+	return "", fmt.Errorf("no %s parameter found for %s header", keyIDParam, HeaderCapabilityInvocationSignature)
 }
@@ -0,0 +1,148 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	httpsig "github.com/igor-pavlenko/httpsignatures-go"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+// HTTPSigOption configures the ProofExtractor returned by HTTPSignatureProofExtractor.
+type HTTPSigOption func(*httpSigExtractor)
+
+// WithSecrets sets the secrets used to verify the HTTP signatures of the requests.
+func WithSecrets(secrets httpsig.Secrets) HTTPSigOption {
+	return func(e *httpSigExtractor) {
+		e.secrets = secrets
+	}
+}
+
+// WithCapabilityResolver sets the resolver of the capabilities invoked by the requests.
+func WithCapabilityResolver(r zcapld.CapabilityResolver) HTTPSigOption {
+	return func(e *httpSigExtractor) {
+		e.resolver = r
+	}
+}
+
+// WithExpectations sets the parameters to expect of the invocations.
+func WithExpectations(expect *zcapld.InvocationExpectations) HTTPSigOption {
+	return func(e *httpSigExtractor) {
+		e.expect = expect
+	}
+}
+
+// WithMethodActions sets the function that maps the method of a request to the capability action it invokes.
+// Defaults to MethodAction.
+func WithMethodActions(action func(method string) string) HTTPSigOption {
+	return func(e *httpSigExtractor) {
+		e.action = action
+	}
+}
+
+// HTTPSignatureProofExtractor returns a ProofExtractor for requests authenticated with HTTP signatures:
+// https://tools.ietf.org/html/draft-ietf-httpbis-message-signatures-00.
+// The signature is verified with the configured secrets, and the capability identified by the "id" parameter of
+// the capability-invocation header, eg. `zcap id="urn:zcap:123"`, is resolved with the configured resolver.
+// The capability action is determined by the request method and the verification method is the signature's keyId.
+func HTTPSignatureProofExtractor(opts ...HTTPSigOption) ProofExtractor {
+	e := &httpSigExtractor{
+		resolver: zcapld.SimpleCapabilityResolver{},
+		expect:   &zcapld.InvocationExpectations{},
+		action:   MethodAction,
+	}
+
+	for i := range opts {
+		opts[i](e)
+	}
+
+	return e
+}
+
+type httpSigExtractor struct {
+	secrets  httpsig.Secrets
+	resolver zcapld.CapabilityResolver
+	expect   *zcapld.InvocationExpectations
+	action   func(method string) string
+}
+
+func (e *httpSigExtractor) Extract(r *http.Request) (*zcapld.Proof, *zcapld.CapabilityInvocation, error) {
+	if e.secrets == nil {
+		return nil, nil, errors.New("no secrets to verify http signatures")
+	}
+
+	hs := httpsig.NewHTTPSignatures(e.secrets)
+	hs.SetDefaultSignatureHeaders([]string{
+		"(key-id)", "(created)", "(expires)", "(request-target)", "host", zcapld.CapabilityInvocationHTTPHeader,
+	})
+
+	err := hs.Verify(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to verify http signature: %w", err)
+	}
+
+	action := e.action(r.Method)
+
+	capabilityID, err := InvokedCapabilityID(r, action)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s header: %w", zcapld.CapabilityInvocationHTTPHeader, err)
+	}
+
+	keyID, err := zcapld.ParseSignatureKeyID(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s header: %w", zcapld.HeaderCapabilityInvocationSignature, err)
+	}
+
+	capability, err := e.resolver.Resolve(r.Context(), capabilityID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve capability %s: %w", capabilityID, err)
+	}
+
+	invocation := &zcapld.CapabilityInvocation{
+		ExpectedTarget:         e.expect.Target,
+		ExpectedAction:         e.expect.Action,
+		ExpectedRootCapability: e.expect.RootCapability,
+		VerificationMethod: &zcapld.VerificationMethod{
+			ID:         keyID,
+			Controller: keyID,
+		},
+	}
+
+	if invocation.ExpectedAction == "" {
+		invocation.ExpectedAction = action
+	}
+
+	return &zcapld.Proof{
+		Capability:         capability,
+		CapabilityAction:   action,
+		VerificationMethod: keyID,
+	}, invocation, nil
+}
+
+// InvokedCapabilityID returns the ID of the capability invoked by the request, from the id parameter of its
+// capability-invocation header, eg. `zcap id="urn:zcap:123"`, parsed with zcapld.ParseInvocationHeader. The header's
+// action parameter, if any, must be the action determined by the request method.
+func InvokedCapabilityID(r *http.Request, action string) (string, error) {
+	header, err := zcapld.ParseInvocationHeader(r)
+	if err != nil {
+		return "", err
+	}
+
+	if header.CapabilityID == "" {
+		return "", errors.New("no id parameter found")
+	}
+
+	if header.Action != "" && header.Action != action {
+		return "", fmt.Errorf(`action parameter "%s" does not match the request method %s`, header.Action, r.Method)
+	}
+
+	return header.CapabilityID, nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/igor-pavlenko/httpsignatures-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/middleware"
+)
+
+func TestHTTPSignatureProofExtractor(t *testing.T) {
+	root, vm, secrets := rootCapability(t)
+	resolver := zcapld.SimpleCapabilityResolver{root.ID: root}
+
+	request := func(method, invocation string) *http.Request {
+		r := httptest.NewRequest(method, "https://example.com/documents", nil)
+		r.Header.Set(zcapld.CapabilityInvocationHTTPHeader, invocation)
+
+		require.NoError(t, httpsignatures.NewHTTPSignatures(secrets).Sign(vm.ID, r))
+
+		return r
+	}
+
+	extractor := middleware.HTTPSignatureProofExtractor(
+		middleware.WithSecrets(secrets),
+		middleware.WithCapabilityResolver(resolver),
+		middleware.WithExpectations(&zcapld.InvocationExpectations{RootCapability: root.ID}),
+	)
+
+	t.Run("success", func(t *testing.T) {
+		proof, invocation, err := extractor.Extract(
+			request(http.MethodGet, fmt.Sprintf(`zcap id="%s",action="read"`, root.ID)))
+		require.NoError(t, err)
+		require.Equal(t, &zcapld.Proof{Capability: root, CapabilityAction: "read", VerificationMethod: vm.ID}, proof)
+		require.Equal(t, &zcapld.CapabilityInvocation{
+			ExpectedAction:         "read",
+			ExpectedRootCapability: root.ID,
+			VerificationMethod:     vm,
+		}, invocation)
+	})
+
+	t.Run("success: action from the request method", func(t *testing.T) {
+		proof, invocation, err := extractor.Extract(request(http.MethodPost, fmt.Sprintf(`zcap id="%s"`, root.ID)))
+		require.NoError(t, err)
+		require.Equal(t, "write", proof.CapabilityAction)
+		require.Equal(t, "write", invocation.ExpectedAction)
+	})
+
+	t.Run("success: custom method actions", func(t *testing.T) {
+		proof, _, err := middleware.HTTPSignatureProofExtractor(
+			middleware.WithSecrets(secrets),
+			middleware.WithCapabilityResolver(resolver),
+			middleware.WithMethodActions(strings.ToLower),
+		).Extract(request(http.MethodDelete, fmt.Sprintf(`zcap id="%s"`, root.ID)))
+		require.NoError(t, err)
+		require.Equal(t, "delete", proof.CapabilityAction)
+	})
+
+	t.Run("success: verified by ZcapMiddleware", func(t *testing.T) {
+		var verified *zcapld.Capability
+
+		w := httptest.NewRecorder()
+		middleware.ZcapMiddleware(verifier(t, root), extractor,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				verified, _ = middleware.GetVerifiedCapability(r.Context())
+			}),
+		).ServeHTTP(w, request(http.MethodGet, fmt.Sprintf(`zcap id="%s"`, root.ID)))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, root, verified)
+	})
+
+	t.Run("error: no secrets", func(t *testing.T) {
+		_, _, err := middleware.HTTPSignatureProofExtractor().Extract(
+			request(http.MethodGet, fmt.Sprintf(`zcap id="%s"`, root.ID)))
+		require.EqualError(t, err, "no secrets to verify http signatures")
+	})
+
+	t.Run("error: request not signed", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "https://example.com/documents", nil)
+		r.Header.Set(zcapld.CapabilityInvocationHTTPHeader, fmt.Sprintf(`zcap id="%s"`, root.ID))
+
+		_, _, err := extractor.Extract(r)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to verify http signature")
+	})
+
+	t.Run("error: signed with an unknown key", func(t *testing.T) {
+		_, _, otherSecrets := rootCapability(t)
+
+		_, _, err := middleware.HTTPSignatureProofExtractor(middleware.WithSecrets(otherSecrets)).Extract(
+			request(http.MethodGet, fmt.Sprintf(`zcap id="%s"`, root.ID)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to verify http signature")
+	})
+
+	t.Run("error: invalid capability-invocation header", func(t *testing.T) {
+		tests := map[string]string{
+			"missing scheme":         fmt.Sprintf(`id="%s"`, root.ID),
+			"invalid scheme":         fmt.Sprintf(`bearer id="%s"`, root.ID),
+			"invalid param":          "zcap id",
+			"missing id":             `zcap action="read"`,
+			"action does not match":  fmt.Sprintf(`zcap id="%s",action="write"`, root.ID),
+			"header value is absent": "",
+		}
+
+		for name, invocation := range tests {
+			_, _, err := extractor.Extract(request(http.MethodGet, invocation))
+			require.Error(t, err, name)
+		}
+	})
+
+	t.Run("error: capability not found", func(t *testing.T) {
+		_, _, err := extractor.Extract(request(http.MethodGet, `zcap id="urn:zcap:unknown"`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to resolve capability urn:zcap:unknown")
+	})
+}
@@ -0,0 +1,137 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package middleware provides net/http middleware that authorizes requests with zcap-ld capability invocations.
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+const bearerScheme = "Bearer"
+
+var logger = log.New("edge-core-zcapld-middleware")
+
+type verifiedCapabilityKey struct{}
+
+type verifiedInvocationKey struct{}
+
+// Keys of the values ZcapMiddleware sets on the request context. They are variables since struct values cannot be
+// constants; their unexported types keep them from colliding with the keys of other packages.
+// nolint:gochecknoglobals // context keys
+var (
+	// ContextKeyVerifiedCapability is the key of the verified *zcapld.Capability. See GetVerifiedCapability.
+	ContextKeyVerifiedCapability = verifiedCapabilityKey{}
+	// ContextKeyVerifiedInvocation is the key of the *zcapld.CapabilityInvocation the capability was verified
+	// against. See GetVerifiedInvocation.
+	ContextKeyVerifiedInvocation = verifiedInvocationKey{}
+)
+
+// ProofExtractor extracts the capability invocation proof from an HTTP request, along with the invocation
+// it is expected to satisfy.
+type ProofExtractor interface {
+	Extract(r *http.Request) (*zcapld.Proof, *zcapld.CapabilityInvocation, error)
+}
+
+// ProofExtractorFunc is an adapter to allow the use of ordinary functions as ProofExtractors.
+type ProofExtractorFunc func(r *http.Request) (*zcapld.Proof, *zcapld.CapabilityInvocation, error)
+
+// Extract calls f(r).
+func (f ProofExtractorFunc) Extract(r *http.Request) (*zcapld.Proof, *zcapld.CapabilityInvocation, error) {
+	return f(r)
+}
+
+// ErrorResponse is the body of the responses to unauthorized requests.
+type ErrorResponse struct {
+	Message string `json:"errMessage,omitempty"`
+}
+
+// ZcapMiddleware verifies the capability invocation proof extracted from each request before forwarding it to
+// 'handler', with the verified capability set on the request context under ContextKeyVerifiedCapability, and the
+// invocation it was verified against under ContextKeyVerifiedInvocation.
+// Requests with a missing or invalid proof are answered with 401 Unauthorized.
+func ZcapMiddleware(v *zcapld.Verifier, extractor ProofExtractor, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proof, invocation, err := extractor.Extract(r)
+		if err != nil {
+			logger.Debugf("failed to extract capability invocation proof: %s", err)
+			writeUnauthorized(w, "invalid capability invocation")
+
+			return
+		}
+
+		err = v.Verify(r.Context(), proof, invocation)
+		if err != nil {
+			logger.Debugf("failed to verify capability invocation: %s", err)
+			writeUnauthorized(w, "unauthorized capability invocation")
+
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ContextKeyVerifiedCapability, proof.Capability)
+		ctx = context.WithValue(ctx, ContextKeyVerifiedInvocation, invocation)
+
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetVerifiedCapability returns the verified capability set on the context by ZcapMiddleware, if any.
+func GetVerifiedCapability(ctx context.Context) (*zcapld.Capability, bool) {
+	capability, ok := ctx.Value(ContextKeyVerifiedCapability).(*zcapld.Capability)
+
+	return capability, ok
+}
+
+// GetVerifiedInvocation returns the invocation set on the context by ZcapMiddleware, if any. It is the invocation
+// the verified capability was verified against.
+func GetVerifiedInvocation(ctx context.Context) (*zcapld.CapabilityInvocation, bool) {
+	invocation, ok := ctx.Value(ContextKeyVerifiedInvocation).(*zcapld.CapabilityInvocation)
+
+	return invocation, ok
+}
+
+// MethodAction is the default mapping of the method of a request to the capability action it invokes: GET, HEAD and
+// OPTIONS requests invoke "read" and other requests invoke "write".
+func MethodAction(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return "read"
+	default:
+		return "write"
+	}
+}
+
+// BearerToken returns the token of the request's Authorization header, which must use the Bearer scheme.
+func BearerToken(r *http.Request) (string, error) {
+	value := strings.TrimSpace(r.Header.Get("Authorization"))
+	if value == "" {
+		return "", errors.New("header is missing")
+	}
+
+	i := strings.IndexByte(value, ' ')
+	if i < 0 || !strings.EqualFold(value[:i], bearerScheme) {
+		return "", errors.New("not a bearer token")
+	}
+
+	return strings.TrimSpace(value[i+1:]), nil
+}
+
+func writeUnauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+
+	err := json.NewEncoder(w).Encode(&ErrorResponse{Message: msg})
+	if err != nil {
+		logger.Errorf("failed to write error response: %s", err)
+	}
+}
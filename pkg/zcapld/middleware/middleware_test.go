@@ -0,0 +1,229 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util/signature"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/fingerprint"
+	"github.com/igor-pavlenko/httpsignatures-go"
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/middleware"
+	zcapldtesting "github.com/trustbloc/edge-core/pkg/zcapld/testing"
+)
+
+func TestZcapMiddleware(t *testing.T) {
+	root, vm, _ := rootCapability(t)
+	v := verifier(t, root)
+
+	extractor := func(action string) middleware.ProofExtractor {
+		return middleware.ProofExtractorFunc(
+			func(*http.Request) (*zcapld.Proof, *zcapld.CapabilityInvocation, error) {
+				return zcapldtesting.NewTestProof(root, action, vm), zcapldtesting.NewTestInvocation(
+					zcapldtesting.WithExpectedRootCapability(root.ID),
+					zcapldtesting.WithExpectedAction("read"),
+					zcapldtesting.WithVerificationMethod(vm),
+				), nil
+			})
+	}
+
+	var verifiedInvocation *zcapld.CapabilityInvocation
+
+	serve := func(extractor middleware.ProofExtractor) (*httptest.ResponseRecorder, *zcapld.Capability) {
+		var verified *zcapld.Capability
+
+		verifiedInvocation = nil
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			verified, _ = middleware.GetVerifiedCapability(r.Context())
+			verifiedInvocation, _ = middleware.GetVerifiedInvocation(r.Context())
+		})
+
+		w := httptest.NewRecorder()
+		middleware.ZcapMiddleware(v, extractor, next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		return w, verified
+	}
+
+	t.Run("success", func(t *testing.T) {
+		w, verified := serve(extractor("read"))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, root, verified)
+		require.NotNil(t, verifiedInvocation)
+		require.Equal(t, root.ID, verifiedInvocation.ExpectedRootCapability)
+	})
+
+	t.Run("error: invalid proof", func(t *testing.T) {
+		w, verified := serve(extractor("write"))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		require.Nil(t, verified)
+
+		response := &middleware.ErrorResponse{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), response))
+		require.Equal(t, "unauthorized capability invocation", response.Message)
+	})
+
+	t.Run("error: proof cannot be extracted", func(t *testing.T) {
+		w, verified := serve(middleware.ProofExtractorFunc(
+			func(*http.Request) (*zcapld.Proof, *zcapld.CapabilityInvocation, error) {
+				return nil, nil, errors.New("test")
+			}))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Nil(t, verified)
+
+		response := &middleware.ErrorResponse{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), response))
+		require.Equal(t, "invalid capability invocation", response.Message)
+	})
+}
+
+func TestGetVerifiedCapability(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := middleware.GetVerifiedCapability(ctx)
+	require.False(t, ok)
+
+	_, ok = middleware.GetVerifiedInvocation(ctx)
+	require.False(t, ok)
+
+	expected := &zcapld.Capability{ID: "urn:zcap:123"}
+	invocation := &zcapld.CapabilityInvocation{ExpectedAction: "read"}
+
+	ctx = context.WithValue(ctx, middleware.ContextKeyVerifiedCapability, expected)
+	ctx = context.WithValue(ctx, middleware.ContextKeyVerifiedInvocation, invocation)
+
+	capability, ok := middleware.GetVerifiedCapability(ctx)
+	require.True(t, ok)
+	require.Equal(t, expected, capability)
+
+	result, ok := middleware.GetVerifiedInvocation(ctx)
+	require.True(t, ok)
+	require.Equal(t, invocation, result)
+}
+
+func TestMethodAction(t *testing.T) {
+	for method, action := range map[string]string{
+		http.MethodGet:     "read",
+		http.MethodHead:    "read",
+		http.MethodOptions: "read",
+		http.MethodPost:    "write",
+		http.MethodPut:     "write",
+		http.MethodDelete:  "write",
+	} {
+		require.Equal(t, action, middleware.MethodAction(method), method)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	_, err := middleware.BearerToken(r)
+	require.EqualError(t, err, "header is missing")
+
+	r.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+
+	_, err = middleware.BearerToken(r)
+	require.EqualError(t, err, "not a bearer token")
+
+	r.Header.Set("Authorization", "bearer  token ")
+
+	token, err := middleware.BearerToken(r)
+	require.NoError(t, err)
+	require.Equal(t, "token", token)
+}
+
+// rootCapability returns a root capability invoked by a did:key, along with the secrets to sign HTTP requests with
+// the did:key.
+func rootCapability(t *testing.T) (*zcapld.Capability, *zcapld.VerificationMethod, httpsignatures.Secrets) {
+	t.Helper()
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, didKeyURL := fingerprint.CreateDIDKey(pubKey)
+
+	root, err := zcapld.NewCapability(
+		&zcapld.Signer{
+			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signature.GetEd25519Signer(privKey, pubKey))),
+			SuiteType:          ed25519signature2018.SignatureType,
+			VerificationMethod: didKeyURL,
+		},
+		zcapld.WithInvoker(didKeyURL),
+		zcapld.WithAllowedActions("read"),
+		zcapld.WithInvocationTarget("urn:edv:document:123", "urn:edv:document"),
+	)
+	require.NoError(t, err)
+
+	privPEM, err := x509.MarshalPKCS8PrivateKey(privKey)
+	require.NoError(t, err)
+
+	pubPEM, err := x509.MarshalPKIXPublicKey(pubKey)
+	require.NoError(t, err)
+
+	secrets := httpsignatures.NewSimpleSecretsStorage(map[string]httpsignatures.Secret{
+		didKeyURL: {
+			KeyID:      didKeyURL,
+			PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubPEM})),
+			PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privPEM})),
+			Algorithm:  kms.ED25519,
+		},
+	})
+
+	return root, zcapldtesting.NewTestVerificationMethod(didKeyURL, didKeyURL), secrets
+}
+
+func verifier(t *testing.T, root *zcapld.Capability) *zcapld.Verifier {
+	t.Helper()
+
+	loader := verifiable.CachingJSONLDLoader()
+
+	for vocab, filename := range map[string]string{
+		"https://w3id.org/security/v1": "w3id.org.security.v1.json",
+		zcapld.SecurityContextV2:       "w3id.org.security.v2.json",
+	} {
+		raw, err := ioutil.ReadFile(filepath.Join("..", "testdata", "context", filename)) // nolint:gosec // test data
+		require.NoError(t, err)
+
+		doc, err := ld.DocumentFromReader(bytes.NewReader(raw))
+		require.NoError(t, err)
+
+		loader.AddDocument(vocab, doc)
+	}
+
+	v, err := zcapld.NewVerifier(
+		zcapld.SimpleCapabilityResolver{root.ID: root},
+		&zcapld.DIDKeyResolver{},
+		zcapld.WithLDDocumentLoaders(loader),
+		zcapld.WithSignatureSuites(
+			ed25519signature2018.New(suite.WithVerifier(ed25519signature2018.NewPublicKeyVerifier())),
+		),
+	)
+	require.NoError(t, err)
+
+	return v
+}
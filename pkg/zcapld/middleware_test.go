@@ -16,6 +16,7 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"mime"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -53,6 +54,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -127,6 +129,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -204,6 +207,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -282,6 +286,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -357,6 +362,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -429,6 +435,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -490,6 +497,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -547,6 +555,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -622,6 +631,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -697,6 +707,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -758,6 +769,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -833,6 +845,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -908,6 +921,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -983,6 +997,7 @@ func TestNewHTTPSigAuthorizationHandler(t *testing.T) {
 			},
 			zcapld.WithAllowedActions("read", "write"),
 			zcapld.WithInvocationTarget(resource, "urn:edv:document"),
+			zcapld.WithController(ownerVerMethod),
 			zcapld.WithID(resource),
 		)
 		require.NoError(t, err)
@@ -1142,3 +1157,80 @@ func (m *mockSecrets) Get(keyID string) (httpsignatures.Secret, error) {
 
 	return m.next.Get(keyID)
 }
+
+func TestHTTPConstants(t *testing.T) {
+	require.Equal(t, zcapld.HeaderCapabilityInvocation, http.CanonicalHeaderKey(zcapld.CapabilityInvocationHTTPHeader))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(zcapld.HeaderCapabilityInvocation, `zcap capability="urn:zcap:123"`)
+	require.Equal(t, `zcap capability="urn:zcap:123"`, r.Header.Get(zcapld.CapabilityInvocationHTTPHeader))
+
+	mediaType, params, err := mime.ParseMediaType(zcapld.ContentTypeZcapLD)
+	require.NoError(t, err)
+	require.Equal(t, "application/ld+json", mediaType)
+	require.Equal(t, map[string]string{"profile": "https://w3id.org/security#"}, params)
+}
+
+func TestParseInvocationHeader(t *testing.T) {
+	request := func(invocation ...string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		for i := range invocation {
+			r.Header.Add(zcapld.HeaderCapabilityInvocation, invocation[i])
+		}
+
+		return r
+	}
+
+	t.Run("success: capability ID", func(t *testing.T) {
+		header, err := zcapld.ParseInvocationHeader(request(`zcap id="urn:zcap:123",action="read"`))
+		require.NoError(t, err)
+		require.Equal(t, &zcapld.InvocationHeader{CapabilityID: "urn:zcap:123", Action: "read"}, header)
+	})
+
+	t.Run("success: embedded capability", func(t *testing.T) {
+		zcap := &zcapld.Capability{Context: zcapld.SecurityContextV2, ID: "urn:zcap:123"}
+
+		header, err := zcapld.ParseInvocationHeader(request(fmt.Sprintf(`ZCAP capability="%s"`, compressZCAP(t, zcap))))
+		require.NoError(t, err)
+		require.Equal(t, &zcapld.InvocationHeader{Capability: zcap}, header)
+	})
+
+	t.Run("success: header with several values", func(t *testing.T) {
+		header, err := zcapld.ParseInvocationHeader(request(`zcap id="urn:zcap:123"`, `action="read"`))
+		require.NoError(t, err)
+		require.Equal(t, &zcapld.InvocationHeader{CapabilityID: "urn:zcap:123", Action: "read"}, header)
+	})
+
+	t.Run("error: invalid header", func(t *testing.T) {
+		tests := map[string]string{
+			"missing scheme":         `id="urn:zcap:123"`,
+			"invalid scheme":         `bearer id="urn:zcap:123"`,
+			"scheme only":            "zcap",
+			"invalid param":          "zcap id",
+			"unquoted id":            "zcap id=urn:zcap:123",
+			"unrecognized param":     `zcap id="urn:zcap:123",foo="bar"`,
+			"header value is absent": "",
+		}
+
+		for name, invocation := range tests {
+			_, err := zcapld.ParseInvocationHeader(request(invocation))
+			require.Error(t, err, name)
+		}
+	})
+}
+
+func TestParseSignatureKeyID(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(zcapld.HeaderCapabilityInvocationSignature,
+		`algorithm="hs2019", signature="c2lnbmF0dXJl==", keyId="did:example:123#key-1"`)
+
+	keyID, err := zcapld.ParseSignatureKeyID(r)
+	require.NoError(t, err)
+	require.Equal(t, "did:example:123#key-1", keyID)
+
+	r.Header.Set(zcapld.HeaderCapabilityInvocationSignature, `algorithm="hs2019"`)
+
+	_, err = zcapld.ParseSignatureKeyID(r)
+	require.EqualError(t, err, "no keyId parameter found for Signature header")
+}
@@ -0,0 +1,65 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// CapabilityMinter mints capabilities whose IDs are derived from idempotency keys, so that issuing a capability
+// again with the same key, eg. after a crash between minting and storing it, produces the same capability ID.
+type CapabilityMinter struct {
+	key []byte
+}
+
+// NewCapabilityMinter returns a CapabilityMinter deriving the capability IDs with the secret key. The key must be
+// kept secret and stable: minters with the same key derive the same IDs.
+func NewCapabilityMinter(signingKey []byte) *CapabilityMinter {
+	return &CapabilityMinter{key: append([]byte(nil), signingKey...)}
+}
+
+// NewCapabilityWithIDKey constructs a new, signed Capability like NewCapability does, except its ID is derived from
+// the idempotency key: urn:uuid:<UUID of HMAC-SHA256(signingKey, idempotencyKey)>. The ID overrides any set with
+// WithID.
+func (m *CapabilityMinter) NewCapabilityWithIDKey(signer *Signer, idempotencyKey string,
+	opts ...CapabilityOption) (*Capability, error) {
+	id, err := m.CapabilityID(idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCapability(signer, append(append([]CapabilityOption{}, opts...), WithID(id))...)
+}
+
+// CapabilityID returns the ID of the capabilities minted with the idempotency key.
+func (m *CapabilityMinter) CapabilityID(idempotencyKey string) (string, error) {
+	if len(m.key) == 0 {
+		return "", errors.New("capability minter has no signing key")
+	}
+
+	if idempotencyKey == "" {
+		return "", errors.New("idempotency key is required")
+	}
+
+	mac := hmac.New(sha256.New, m.key)
+	_, _ = mac.Write([]byte(idempotencyKey)) // nolint:errcheck // hash writes never fail
+
+	var id uuid.UUID
+
+	copy(id[:], mac.Sum(nil))
+
+	// RFC 4122 variant, version 8 (custom).
+	id[6] = (id[6] & 0x0f) | 0x80
+	id[8] = (id[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("urn:uuid:%s", id.String()), nil
+}
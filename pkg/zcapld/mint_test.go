@@ -0,0 +1,100 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestCapabilityMinter(t *testing.T) {
+	signingKey := []byte("secret key")
+
+	t.Run("same idempotency key mints the same capability ID", func(t *testing.T) {
+		signer := testSigner(t, kms.ED25519)
+		zcapSigner := &zcapld.Signer{
+			SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
+			SuiteType:          ed25519signature2018.SignatureType,
+			VerificationMethod: keyID(signer),
+		}
+
+		first, err := zcapld.NewCapabilityMinter(signingKey).NewCapabilityWithIDKey(zcapSigner, "request-1",
+			zcapld.WithInvocationTarget("urn:target", ""), zcapld.WithID("urn:zcap:ignored"))
+		require.NoError(t, err)
+
+		second, err := zcapld.NewCapabilityMinter(signingKey).NewCapabilityWithIDKey(zcapSigner, "request-1",
+			zcapld.WithInvocationTarget("urn:target", ""))
+		require.NoError(t, err)
+		require.Equal(t, first.ID, second.ID)
+		require.Equal(t, "urn:target", second.InvocationTarget.ID)
+
+		id, err := zcapld.NewCapabilityMinter(signingKey).CapabilityID("request-1")
+		require.NoError(t, err)
+		require.Equal(t, id, first.ID)
+	})
+
+	t.Run("capability ID is a UUID URN", func(t *testing.T) {
+		id, err := zcapld.NewCapabilityMinter(signingKey).CapabilityID("request-1")
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(id, "urn:uuid:"))
+
+		parsed, err := uuid.Parse(strings.TrimPrefix(id, "urn:uuid:"))
+		require.NoError(t, err)
+		require.Equal(t, uuid.RFC4122, parsed.Variant())
+		require.Equal(t, uuid.Version(8), parsed.Version())
+	})
+
+	t.Run("capability ID depends on the idempotency key and the signing key", func(t *testing.T) {
+		id, err := zcapld.NewCapabilityMinter(signingKey).CapabilityID("request-1")
+		require.NoError(t, err)
+
+		otherRequest, err := zcapld.NewCapabilityMinter(signingKey).CapabilityID("request-2")
+		require.NoError(t, err)
+		require.NotEqual(t, id, otherRequest)
+
+		otherKey, err := zcapld.NewCapabilityMinter([]byte("other key")).CapabilityID("request-1")
+		require.NoError(t, err)
+		require.NotEqual(t, id, otherKey)
+	})
+
+	t.Run("signing key is copied", func(t *testing.T) {
+		key := []byte("secret key")
+		minter := zcapld.NewCapabilityMinter(key)
+
+		id, err := minter.CapabilityID("request-1")
+		require.NoError(t, err)
+
+		key[0] = 'x'
+
+		result, err := minter.CapabilityID("request-1")
+		require.NoError(t, err)
+		require.Equal(t, id, result)
+	})
+
+	t.Run("error: no idempotency key", func(t *testing.T) {
+		_, err := zcapld.NewCapabilityMinter(signingKey).NewCapabilityWithIDKey(&zcapld.Signer{}, "")
+		require.EqualError(t, err, "idempotency key is required")
+	})
+
+	t.Run("error: no signing key", func(t *testing.T) {
+		_, err := zcapld.NewCapabilityMinter(nil).CapabilityID("request-1")
+		require.EqualError(t, err, "capability minter has no signing key")
+	})
+
+	t.Run("error: no signer", func(t *testing.T) {
+		_, err := zcapld.NewCapabilityMinter(signingKey).NewCapabilityWithIDKey(nil, "request-1")
+		require.EqualError(t, err, "must provide a signer")
+	})
+}
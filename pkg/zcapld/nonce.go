@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NonceChecker records the nonces of invocation proofs to prevent their replay.
+type NonceChecker interface {
+	// CheckAndStore stores the nonce, returning an error if it has already been seen.
+	CheckAndStore(nonce string) error
+}
+
+// MemoryNonceChecker is an in-memory NonceChecker.
+type MemoryNonceChecker struct {
+	seen sync.Map
+	ttl  time.Duration
+	// mutex serializes the replacement and removal of expired nonces.
+	mutex     sync.Mutex
+	lastSweep time.Time
+}
+
+// NewMemoryNonceChecker returns a new MemoryNonceChecker. Nonces expire 'ttl' after they are first seen, after
+// which they are accepted again and the memory they use is reclaimed. A 'ttl' of 0 means nonces never expire.
+func NewMemoryNonceChecker(ttl time.Duration) *MemoryNonceChecker {
+	return &MemoryNonceChecker{
+		ttl:       ttl,
+		lastSweep: time.Now(),
+	}
+}
+
+// CheckAndStore stores the nonce, returning an error if it has been seen and has not expired.
+func (m *MemoryNonceChecker) CheckAndStore(nonce string) error {
+	now := time.Now()
+
+	seen, loaded := m.seen.LoadOrStore(nonce, now)
+	if loaded && m.expired(seen.(time.Time), now) {
+		loaded = m.replaceExpired(nonce, now)
+	}
+
+	m.sweep(now)
+
+	if loaded {
+		return fmt.Errorf("nonce %s has already been used", nonce)
+	}
+
+	return nil
+}
+
+func (m *MemoryNonceChecker) expired(seen, now time.Time) bool {
+	return m.ttl > 0 && now.Sub(seen) >= m.ttl
+}
+
+// replaceExpired stores the nonce if it has expired, reporting whether it was seen again in the meantime.
+func (m *MemoryNonceChecker) replaceExpired(nonce string, now time.Time) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if seen, ok := m.seen.Load(nonce); ok && m.expired(seen.(time.Time), now) {
+		m.seen.Delete(nonce)
+	}
+
+	_, loaded := m.seen.LoadOrStore(nonce, now)
+
+	return loaded
+}
+
+// sweep removes expired nonces at most once per ttl.
+func (m *MemoryNonceChecker) sweep(now time.Time) {
+	if m.ttl == 0 {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if now.Sub(m.lastSweep) < m.ttl {
+		return
+	}
+
+	m.lastSweep = now
+
+	m.seen.Range(func(nonce, seen interface{}) bool {
+		if m.expired(seen.(time.Time), now) {
+			m.seen.Delete(nonce)
+		}
+
+		return true
+	})
+}
@@ -0,0 +1,114 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestMemoryNonceChecker(t *testing.T) {
+	t.Run("rejects nonces already seen", func(t *testing.T) {
+		checker := zcapld.NewMemoryNonceChecker(0)
+		require.NoError(t, checker.CheckAndStore("123"))
+		require.NoError(t, checker.CheckAndStore("456"))
+
+		err := checker.CheckAndStore("123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "nonce 123 has already been used")
+	})
+
+	t.Run("accepts expired nonces", func(t *testing.T) {
+		ttl := 10 * time.Millisecond
+		checker := zcapld.NewMemoryNonceChecker(ttl)
+		require.NoError(t, checker.CheckAndStore("123"))
+		require.Error(t, checker.CheckAndStore("123"))
+
+		time.Sleep(2 * ttl)
+
+		require.NoError(t, checker.CheckAndStore("123"))
+		require.Error(t, checker.CheckAndStore("123"))
+	})
+
+	t.Run("accepts a nonce only once under concurrency", func(t *testing.T) {
+		checker := zcapld.NewMemoryNonceChecker(time.Minute)
+
+		const n = 50
+
+		var (
+			wg       sync.WaitGroup
+			accepted int32
+		)
+
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				require.NoError(t, checker.CheckAndStore(fmt.Sprintf("unique-%d", i)))
+
+				if checker.CheckAndStore("shared") == nil {
+					atomic.AddInt32(&accepted, 1)
+				}
+			}(i)
+		}
+
+		wg.Wait()
+		require.Equal(t, int32(1), accepted)
+	})
+}
+
+func TestWithNonceChecker(t *testing.T) {
+	root, rootSigner := selfSignedSelfInvokingRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+
+	verify := func(checker zcapld.NonceChecker, nonce string) error {
+		return verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithNonceChecker(checker),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         root,
+				CapabilityAction:   "read",
+				VerificationMethod: root.Invoker,
+				Nonce:              nonce,
+			},
+			invocation(root.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("success: nonce not seen before", func(t *testing.T) {
+		require.NoError(t, verify(zcapld.NewMemoryNonceChecker(0), "123"))
+	})
+
+	t.Run("success: proofs without a nonce are not checked", func(t *testing.T) {
+		checker := zcapld.NewMemoryNonceChecker(0)
+		require.NoError(t, verify(checker, ""))
+		require.NoError(t, verify(checker, ""))
+	})
+
+	t.Run("error: replayed nonce", func(t *testing.T) {
+		checker := zcapld.NewMemoryNonceChecker(0)
+		require.NoError(t, verify(checker, "123"))
+
+		err := verify(checker, "123")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to check nonce: nonce 123 has already been used")
+	})
+}
@@ -0,0 +1,254 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package oidc extracts capability invocation proofs from requests authenticated with OpenID Connect ID tokens.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/middleware"
+)
+
+// OIDCProvider verifies the ID tokens issued by an OpenID provider, eg. with the keys of its JWKS endpoint.
+type OIDCProvider interface {
+	// VerifyIDToken verifies the signature of the raw ID token and returns its claims.
+	VerifyIDToken(ctx context.Context, rawIDToken string) (*Claims, error)
+}
+
+// OIDCProviderFunc is an adapter to allow the use of ordinary functions as OIDCProviders.
+type OIDCProviderFunc func(ctx context.Context, rawIDToken string) (*Claims, error)
+
+// VerifyIDToken calls f(ctx, rawIDToken).
+func (f OIDCProviderFunc) VerifyIDToken(ctx context.Context, rawIDToken string) (*Claims, error) {
+	return f(ctx, rawIDToken)
+}
+
+// Claims are the claims of an ID token used by the ProofExtractor.
+type Claims struct {
+	// Issuer identifies the OpenID provider. It is the controller of the verification method.
+	Issuer string `json:"iss"`
+	// Subject identifies the caller. It is the ID of the verification method.
+	Subject string `json:"sub"`
+	// Audience are the clients the token is intended for.
+	Audience Audience `json:"aud"`
+	// Expiry is the time after which the token is rejected, in seconds since the Unix epoch.
+	Expiry int64 `json:"exp"`
+	// IssuedAt is the creation time of the token, in seconds since the Unix epoch.
+	IssuedAt int64 `json:"iat,omitempty"`
+	// Nonce is the nonce of the token.
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// Audience is the "aud" claim, either a single string or an array of strings.
+type Audience []string
+
+// UnmarshalJSON unmarshals a single string or an array of strings.
+func (a *Audience) UnmarshalJSON(raw []byte) error {
+	var single string
+
+	if json.Unmarshal(raw, &single) == nil {
+		*a = Audience{single}
+
+		return nil
+	}
+
+	var multiple []string
+
+	err := json.Unmarshal(raw, &multiple)
+	if err != nil {
+		return fmt.Errorf("aud is neither a string nor an array of strings: %w", err)
+	}
+
+	*a = multiple
+
+	return nil
+}
+
+func (a Audience) contains(aud string) bool {
+	for i := range a {
+		if a[i] == aud {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Option configures the ProofExtractor returned by OIDCProofExtractor.
+type Option func(*oidcExtractor)
+
+// WithOIDCProviderURL sets the URL of the OpenID provider, which the "iss" claim of the ID tokens must equal.
+// Defaults to accepting any issuer the provider verifies the tokens of.
+func WithOIDCProviderURL(url string) Option {
+	return func(e *oidcExtractor) {
+		e.issuer = url
+	}
+}
+
+// WithExpectedAudience sets the client ID the "aud" claim of the ID tokens must contain. It is required: without it,
+// ID tokens issued to any other client of the OpenID provider would be accepted, so every request is rejected.
+func WithExpectedAudience(aud string) Option {
+	return func(e *oidcExtractor) {
+		e.audience = aud
+	}
+}
+
+// WithCapabilityResolver sets the resolver of the capabilities invoked by the requests.
+func WithCapabilityResolver(r zcapld.CapabilityResolver) Option {
+	return func(e *oidcExtractor) {
+		e.resolver = r
+	}
+}
+
+// WithExpectations sets the parameters to expect of the invocations.
+func WithExpectations(expect *zcapld.InvocationExpectations) Option {
+	return func(e *oidcExtractor) {
+		e.expect = expect
+	}
+}
+
+// WithMethodActions sets the function that maps the method of a request to the capability action it invokes.
+// Defaults to middleware.MethodAction.
+func WithMethodActions(action func(method string) string) Option {
+	return func(e *oidcExtractor) {
+		e.action = action
+	}
+}
+
+// WithClock sets the clock used to check the expiry of the ID tokens. Defaults to time.Now.
+func WithClock(clock func() time.Time) Option {
+	return func(e *oidcExtractor) {
+		e.clock = clock
+	}
+}
+
+// OIDCProofExtractor returns a middleware.ProofExtractor for requests authenticated with an ID token bearer token in
+// the Authorization header. The token is verified by the provider, must not be expired, and must be intended for the
+// audience set with WithExpectedAudience, which is required. The verification method
+// of the invocation has the "sub" claim as its ID and the "iss" claim as its controller, so capabilities may be
+// invoked by either. The invoked capability is identified by the capability-invocation header, as with
+// middleware.HTTPSignatureProofExtractor.
+func OIDCProofExtractor(provider OIDCProvider, opts ...Option) middleware.ProofExtractor {
+	e := &oidcExtractor{
+		provider: provider,
+		resolver: zcapld.SimpleCapabilityResolver{},
+		expect:   &zcapld.InvocationExpectations{},
+		action:   middleware.MethodAction,
+		clock:    time.Now,
+	}
+
+	for i := range opts {
+		opts[i](e)
+	}
+
+	return e
+}
+
+type oidcExtractor struct {
+	provider OIDCProvider
+	issuer   string
+	audience string
+	resolver zcapld.CapabilityResolver
+	expect   *zcapld.InvocationExpectations
+	action   func(method string) string
+	clock    func() time.Time
+}
+
+func (e *oidcExtractor) Extract(r *http.Request) (*zcapld.Proof, *zcapld.CapabilityInvocation, error) {
+	if e.audience == "" {
+		return nil, nil, errors.New("no expected audience to validate id_tokens")
+	}
+
+	token, err := middleware.BearerToken(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse Authorization header: %w", err)
+	}
+
+	claims, err := e.provider.VerifyIDToken(r.Context(), token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to verify id_token: %w", err)
+	}
+
+	err = e.validate(claims)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	action := e.action(r.Method)
+
+	capabilityID, err := middleware.InvokedCapabilityID(r, action)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s header: %w", zcapld.CapabilityInvocationHTTPHeader, err)
+	}
+
+	capability, err := e.resolver.Resolve(r.Context(), capabilityID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve capability %s: %w", capabilityID, err)
+	}
+
+	proof := &zcapld.Proof{
+		Capability:         capability,
+		CapabilityAction:   action,
+		VerificationMethod: claims.Subject,
+		Nonce:              claims.Nonce,
+	}
+
+	if claims.IssuedAt != 0 {
+		proof.Created = time.Unix(claims.IssuedAt, 0)
+	}
+
+	invocation := &zcapld.CapabilityInvocation{
+		ExpectedTarget:         e.expect.Target,
+		ExpectedAction:         e.expect.Action,
+		ExpectedRootCapability: e.expect.RootCapability,
+		VerificationMethod: &zcapld.VerificationMethod{
+			ID:         claims.Subject,
+			Controller: claims.Issuer,
+		},
+	}
+
+	if invocation.ExpectedAction == "" {
+		invocation.ExpectedAction = action
+	}
+
+	return proof, invocation, nil
+}
+
+// validate the claims of a verified ID token.
+func (e *oidcExtractor) validate(claims *Claims) error {
+	if claims.Subject == "" {
+		return errors.New("no sub claim")
+	}
+
+	if claims.Issuer == "" {
+		return errors.New("no iss claim")
+	}
+
+	if e.issuer != "" && claims.Issuer != e.issuer {
+		return fmt.Errorf(`iss "%s" is not the OpenID provider "%s"`, claims.Issuer, e.issuer)
+	}
+
+	if !claims.Audience.contains(e.audience) {
+		return fmt.Errorf(`aud %v does not contain "%s"`, []string(claims.Audience), e.audience)
+	}
+
+	if claims.Expiry == 0 {
+		return errors.New("no exp claim")
+	}
+
+	if e.clock().After(time.Unix(claims.Expiry, 0)) {
+		return fmt.Errorf("expired at %s", time.Unix(claims.Expiry, 0).UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
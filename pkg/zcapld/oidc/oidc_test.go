@@ -0,0 +1,209 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package oidc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/oidc"
+)
+
+const (
+	issuer   = "https://accounts.example.com"
+	subject  = "did:example:alice"
+	clientID = "client-123"
+	rawToken = "eyJhbGciOiJSUzI1NiJ9.test.signature"
+)
+
+func TestOIDCProofExtractor(t *testing.T) {
+	capability := &zcapld.Capability{ID: "urn:zcap:123", Invoker: subject}
+	now := time.Now()
+
+	validClaims := func() *oidc.Claims {
+		return &oidc.Claims{
+			Issuer:   issuer,
+			Subject:  subject,
+			Audience: oidc.Audience{clientID},
+			Expiry:   now.Add(time.Hour).Unix(),
+			IssuedAt: now.Unix(),
+			Nonce:    "nonce",
+		}
+	}
+
+	provider := func(claims *oidc.Claims) oidc.OIDCProvider {
+		return oidc.OIDCProviderFunc(func(_ context.Context, token string) (*oidc.Claims, error) {
+			require.Equal(t, rawToken, token)
+
+			return claims, nil
+		})
+	}
+
+	request := func(method string) *http.Request {
+		r := httptest.NewRequest(method, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+rawToken)
+		r.Header.Set(zcapld.CapabilityInvocationHTTPHeader, `zcap id="urn:zcap:123"`)
+
+		return r
+	}
+
+	extract := func(r *http.Request, claims *oidc.Claims, opts ...oidc.Option) (
+		*zcapld.Proof, *zcapld.CapabilityInvocation, error) {
+		return oidc.OIDCProofExtractor(provider(claims), append([]oidc.Option{
+			oidc.WithCapabilityResolver(zcapld.SimpleCapabilityResolver{capability.ID: capability}),
+			oidc.WithOIDCProviderURL(issuer),
+			oidc.WithExpectedAudience(clientID),
+			oidc.WithClock(func() time.Time { return now }),
+		}, opts...)...).Extract(r)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		proof, invocation, err := extract(request(http.MethodGet), validClaims(),
+			oidc.WithExpectations(&zcapld.InvocationExpectations{Target: "urn:target", RootCapability: "urn:zcap:root"}))
+		require.NoError(t, err)
+		require.Equal(t, &zcapld.Proof{
+			Capability:         capability,
+			CapabilityAction:   "read",
+			VerificationMethod: subject,
+			Created:            time.Unix(now.Unix(), 0),
+			Nonce:              "nonce",
+		}, proof)
+		require.Equal(t, &zcapld.CapabilityInvocation{
+			ExpectedTarget:         "urn:target",
+			ExpectedAction:         "read",
+			ExpectedRootCapability: "urn:zcap:root",
+			VerificationMethod:     &zcapld.VerificationMethod{ID: subject, Controller: issuer},
+		}, invocation)
+	})
+
+	t.Run("success: method actions", func(t *testing.T) {
+		proof, invocation, err := extract(request(http.MethodPost), validClaims(),
+			oidc.WithMethodActions(func(string) string { return "invoke" }))
+		require.NoError(t, err)
+		require.Equal(t, "invoke", proof.CapabilityAction)
+		require.Equal(t, "invoke", invocation.ExpectedAction)
+	})
+
+	t.Run("success: any issuer by default", func(t *testing.T) {
+		claims := validClaims()
+		claims.Issuer = "https://other.example.com"
+
+		_, invocation, err := oidc.OIDCProofExtractor(provider(claims),
+			oidc.WithCapabilityResolver(zcapld.SimpleCapabilityResolver{capability.ID: capability}),
+			oidc.WithExpectedAudience(clientID),
+		).Extract(request(http.MethodGet))
+		require.NoError(t, err)
+		require.Equal(t, "https://other.example.com", invocation.VerificationMethod.Controller)
+	})
+
+	t.Run("error: no expected audience", func(t *testing.T) {
+		_, _, err := oidc.OIDCProofExtractor(provider(validClaims()),
+			oidc.WithCapabilityResolver(zcapld.SimpleCapabilityResolver{capability.ID: capability}),
+		).Extract(request(http.MethodGet))
+		require.EqualError(t, err, "no expected audience to validate id_tokens")
+	})
+
+	t.Run("error: token issued to another client", func(t *testing.T) {
+		claims := validClaims()
+		claims.Audience = oidc.Audience{"client-456", "client-789"}
+
+		_, _, err := extract(request(http.MethodGet), claims)
+		require.EqualError(t, err, `invalid id_token: aud [client-456 client-789] does not contain "client-123"`)
+	})
+
+	t.Run("error: invalid Authorization header", func(t *testing.T) {
+		r := request(http.MethodGet)
+		r.Header.Del("Authorization")
+
+		_, _, err := extract(r, validClaims())
+		require.EqualError(t, err, "failed to parse Authorization header: header is missing")
+
+		r.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+
+		_, _, err = extract(r, validClaims())
+		require.EqualError(t, err, "failed to parse Authorization header: not a bearer token")
+	})
+
+	t.Run("error: token not verified", func(t *testing.T) {
+		_, _, err := oidc.OIDCProofExtractor(oidc.OIDCProviderFunc(
+			func(context.Context, string) (*oidc.Claims, error) {
+				return nil, errors.New("test")
+			}), oidc.WithExpectedAudience(clientID)).Extract(request(http.MethodGet))
+		require.EqualError(t, err, "failed to verify id_token: test")
+	})
+
+	t.Run("error: invalid claims", func(t *testing.T) {
+		mutations := map[string]func(c *oidc.Claims){
+			"invalid id_token: no sub claim": func(c *oidc.Claims) { c.Subject = "" },
+			"invalid id_token: no iss claim": func(c *oidc.Claims) { c.Issuer = "" },
+			`invalid id_token: iss "https://other.example.com" is not the OpenID provider "https://accounts.example.com"`: func(
+				c *oidc.Claims) {
+				c.Issuer = "https://other.example.com"
+			},
+			`invalid id_token: aud [other] does not contain "client-123"`: func(c *oidc.Claims) {
+				c.Audience = oidc.Audience{"other"}
+			},
+			`invalid id_token: aud [] does not contain "client-123"`: func(c *oidc.Claims) {
+				c.Audience = nil
+			},
+			"invalid id_token: no exp claim": func(c *oidc.Claims) { c.Expiry = 0 },
+		}
+
+		for expected, mutate := range mutations {
+			claims := validClaims()
+			mutate(claims)
+
+			_, _, err := extract(request(http.MethodGet), claims)
+			require.EqualError(t, err, expected)
+		}
+	})
+
+	t.Run("error: expired", func(t *testing.T) {
+		claims := validClaims()
+		claims.Expiry = now.Add(-time.Minute).Unix()
+
+		_, _, err := extract(request(http.MethodGet), claims)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid id_token: expired at")
+	})
+
+	t.Run("error: capability-invocation header", func(t *testing.T) {
+		r := request(http.MethodGet)
+		r.Header.Del(zcapld.CapabilityInvocationHTTPHeader)
+
+		_, _, err := extract(r, validClaims())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse capability-invocation header")
+	})
+
+	t.Run("error: capability not found", func(t *testing.T) {
+		r := request(http.MethodGet)
+		r.Header.Set(zcapld.CapabilityInvocationHTTPHeader, `zcap id="urn:zcap:456"`)
+
+		_, _, err := extract(r, validClaims())
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+	})
+}
+
+func TestAudience_UnmarshalJSON(t *testing.T) {
+	claims := &oidc.Claims{}
+	require.NoError(t, json.Unmarshal([]byte(`{"aud":"client-123"}`), claims))
+	require.Equal(t, oidc.Audience{"client-123"}, claims.Audience)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"aud":["a","b"]}`), claims))
+	require.Equal(t, oidc.Audience{"a", "b"}, claims.Audience)
+
+	require.Error(t, json.Unmarshal([]byte(`{"aud":1}`), claims))
+}
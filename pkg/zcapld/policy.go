@@ -0,0 +1,71 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"context"
+	"fmt"
+)
+
+// PolicyDecision is the decision of a PolicyEngine on a capability invocation.
+type PolicyDecision int
+
+// Decisions of PolicyEngines.
+const (
+	// PolicyIndeterminate is the decision of a PolicyEngine unable to decide, eg. because no rule applies. The
+	// Verifier denies the invocation.
+	PolicyIndeterminate PolicyDecision = iota
+	// PolicyAllow allows the invocation.
+	PolicyAllow
+	// PolicyDeny denies the invocation.
+	PolicyDeny
+)
+
+func (d PolicyDecision) String() string {
+	switch d {
+	case PolicyAllow:
+		return "allow"
+	case PolicyDeny:
+		return "deny"
+	case PolicyIndeterminate:
+		return "indeterminate"
+	default:
+		return fmt.Sprintf("PolicyDecision(%d)", int(d))
+	}
+}
+
+// PolicyEngine evaluates organization-specific rules on capability invocations, on top of the ZCAP-LD semantics
+// verified by the Verifier. Implementations are found in the policy package.
+type PolicyEngine interface {
+	Evaluate(ctx context.Context, capability *Capability, invocation *CapabilityInvocation) (PolicyDecision, error)
+}
+
+// WithPolicyEngine sets the PolicyEngine evaluating the invocations once the Verifier has verified them
+// successfully. Invocations are denied with an error wrapping ErrPolicyDenied unless the engine allows them.
+func WithPolicyEngine(p PolicyEngine) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.PolicyEngine = p
+	}
+}
+
+// evaluatePolicy ensures the policy engine, if any, allows the invocation of the capability.
+func (v *Verifier) evaluatePolicy(ctx context.Context, capability *Capability, invocation *CapabilityInvocation) error {
+	if v.policy == nil {
+		return nil
+	}
+
+	decision, err := v.policy.Evaluate(ctx, capability, invocation)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate policy: %w", err)
+	}
+
+	if decision != PolicyAllow {
+		return fmt.Errorf("%w: the decision on capability %s is %s", ErrPolicyDenied, capability.ID, decision)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package policy provides implementations of zcapld.PolicyEngine.
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+// AlwaysAllowPolicyEngine allows every invocation. It is meant for development; it adds no rule to those of
+// ZCAP-LD.
+type AlwaysAllowPolicyEngine struct{}
+
+// Evaluate allows the invocation.
+func (AlwaysAllowPolicyEngine) Evaluate(
+	context.Context, *zcapld.Capability, *zcapld.CapabilityInvocation) (zcapld.PolicyDecision, error) {
+	return zcapld.PolicyAllow, nil
+}
+
+// CompositeANDPolicyEngine allows the invocations allowed by all its engines. Without engines, it allows nothing.
+type CompositeANDPolicyEngine struct {
+	engines []zcapld.PolicyEngine
+}
+
+// NewCompositeANDPolicyEngine returns a CompositeANDPolicyEngine of the engines, evaluated in order.
+func NewCompositeANDPolicyEngine(engines ...zcapld.PolicyEngine) *CompositeANDPolicyEngine {
+	return &CompositeANDPolicyEngine{engines: engines}
+}
+
+// Evaluate the invocation with the engines, in order, stopping at the first one that fails or does not allow it.
+// Its decision is returned.
+func (c *CompositeANDPolicyEngine) Evaluate(ctx context.Context, capability *zcapld.Capability,
+	invocation *zcapld.CapabilityInvocation) (zcapld.PolicyDecision, error) {
+	if len(c.engines) == 0 {
+		return zcapld.PolicyIndeterminate, nil
+	}
+
+	for i := range c.engines {
+		decision, err := c.engines[i].Evaluate(ctx, capability, invocation)
+		if err != nil {
+			return zcapld.PolicyIndeterminate, fmt.Errorf("policy engine %d failed: %w", i, err)
+		}
+
+		if decision != zcapld.PolicyAllow {
+			return decision, nil
+		}
+	}
+
+	return zcapld.PolicyAllow, nil
+}
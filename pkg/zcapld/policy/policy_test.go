@@ -0,0 +1,82 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/policy"
+)
+
+func TestAlwaysAllowPolicyEngine(t *testing.T) {
+	decision, err := policy.AlwaysAllowPolicyEngine{}.Evaluate(
+		context.Background(), &zcapld.Capability{}, &zcapld.CapabilityInvocation{})
+	require.NoError(t, err)
+	require.Equal(t, zcapld.PolicyAllow, decision)
+}
+
+func TestCompositeANDPolicyEngine(t *testing.T) {
+	evaluate := func(engines ...zcapld.PolicyEngine) (zcapld.PolicyDecision, error) {
+		return policy.NewCompositeANDPolicyEngine(engines...).Evaluate(
+			context.Background(), &zcapld.Capability{}, &zcapld.CapabilityInvocation{})
+	}
+
+	t.Run("allow: all engines allow", func(t *testing.T) {
+		decision, err := evaluate(policy.AlwaysAllowPolicyEngine{}, decide(zcapld.PolicyAllow))
+		require.NoError(t, err)
+		require.Equal(t, zcapld.PolicyAllow, decision)
+	})
+
+	t.Run("deny: an engine denies", func(t *testing.T) {
+		last := &mockEngine{decision: zcapld.PolicyAllow}
+
+		decision, err := evaluate(policy.AlwaysAllowPolicyEngine{}, decide(zcapld.PolicyDeny), last)
+		require.NoError(t, err)
+		require.Equal(t, zcapld.PolicyDeny, decision)
+		require.False(t, last.called)
+	})
+
+	t.Run("indeterminate: an engine cannot decide", func(t *testing.T) {
+		decision, err := evaluate(decide(zcapld.PolicyIndeterminate), policy.AlwaysAllowPolicyEngine{})
+		require.NoError(t, err)
+		require.Equal(t, zcapld.PolicyIndeterminate, decision)
+	})
+
+	t.Run("indeterminate: no engines", func(t *testing.T) {
+		decision, err := evaluate()
+		require.NoError(t, err)
+		require.Equal(t, zcapld.PolicyIndeterminate, decision)
+	})
+
+	t.Run("error: an engine fails", func(t *testing.T) {
+		decision, err := evaluate(policy.AlwaysAllowPolicyEngine{}, &mockEngine{err: errors.New("test")})
+		require.EqualError(t, err, "policy engine 1 failed: test")
+		require.Equal(t, zcapld.PolicyIndeterminate, decision)
+	})
+}
+
+func decide(decision zcapld.PolicyDecision) zcapld.PolicyEngine {
+	return &mockEngine{decision: decision}
+}
+
+type mockEngine struct {
+	decision zcapld.PolicyDecision
+	err      error
+	called   bool
+}
+
+func (m *mockEngine) Evaluate(
+	context.Context, *zcapld.Capability, *zcapld.CapabilityInvocation) (zcapld.PolicyDecision, error) {
+	m.called = true
+
+	return m.decision, m.err
+}
@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestWithPolicyEngine(t *testing.T) {
+	root, rootSigner := selfSignedSelfInvokingRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	verify := func(engine zcapld.PolicyEngine, action string) error {
+		return verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithPolicyEngine(engine),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         root,
+				CapabilityAction:   action,
+				VerificationMethod: root.Invoker,
+			},
+			invocation(root.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("success: policy allows the invocation", func(t *testing.T) {
+		engine := &mockPolicyEngine{decision: zcapld.PolicyAllow}
+
+		require.NoError(t, verify(engine, "read"))
+		require.Equal(t, root, engine.capability)
+		require.Equal(t, root.ID, engine.invocation.ExpectedRootCapability)
+	})
+
+	t.Run("error: policy denies the invocation", func(t *testing.T) {
+		err := verify(&mockPolicyEngine{decision: zcapld.PolicyDeny}, "read")
+		require.True(t, errors.Is(err, zcapld.ErrPolicyDenied))
+		require.EqualError(t, err, "denied by policy: the decision on capability "+root.ID+" is deny")
+	})
+
+	t.Run("error: policy cannot decide", func(t *testing.T) {
+		err := verify(&mockPolicyEngine{decision: zcapld.PolicyIndeterminate}, "read")
+		require.True(t, errors.Is(err, zcapld.ErrPolicyDenied))
+		require.Contains(t, err.Error(), "is indeterminate")
+	})
+
+	t.Run("error: policy fails", func(t *testing.T) {
+		err := verify(&mockPolicyEngine{err: errors.New("test")}, "read")
+		require.EqualError(t, err, "failed to evaluate policy: test")
+	})
+
+	t.Run("policy is not evaluated if the verification fails", func(t *testing.T) {
+		engine := &mockPolicyEngine{decision: zcapld.PolicyAllow}
+
+		err := verify(engine, "write")
+		require.True(t, errors.Is(err, zcapld.ErrActionNotAllowed))
+		require.Nil(t, engine.capability)
+	})
+}
+
+func TestPolicyDecision_String(t *testing.T) {
+	require.Equal(t, "allow", zcapld.PolicyAllow.String())
+	require.Equal(t, "deny", zcapld.PolicyDeny.String())
+	require.Equal(t, "indeterminate", zcapld.PolicyIndeterminate.String())
+	require.Equal(t, "PolicyDecision(7)", zcapld.PolicyDecision(7).String())
+}
+
+type mockPolicyEngine struct {
+	decision   zcapld.PolicyDecision
+	err        error
+	capability *zcapld.Capability
+	invocation *zcapld.CapabilityInvocation
+}
+
+func (m *mockPolicyEngine) Evaluate(_ context.Context, capability *zcapld.Capability,
+	invocation *zcapld.CapabilityInvocation) (zcapld.PolicyDecision, error) {
+	m.capability, m.invocation = capability, invocation
+
+	return m.decision, m.err
+}
@@ -0,0 +1,95 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import "fmt"
+
+// ProofPurposeCapabilityInvocation is the proof purpose of capability invocations.
+const ProofPurposeCapabilityInvocation = "capabilityInvocation"
+
+// ProofPurposeAssertionMethod is the proof purpose of delegation receipts.
+const ProofPurposeAssertionMethod = "assertionMethod"
+
+// ProofPurposeVerifier verifies a proof is fit for a proof purpose.
+// The name ProofPurpose is taken by the proofPurpose constant of ZCAP-LD documents.
+type ProofPurposeVerifier interface {
+	// Name of the proof purpose, eg. "capabilityInvocation".
+	Name() string
+	// Verify the proof is fit for the proof purpose of the invocation.
+	Verify(proof *Proof, invocation *CapabilityInvocation) error
+}
+
+// ProofPurposeRegistry maps proof purpose names to their ProofPurposeVerifier.
+type ProofPurposeRegistry map[string]ProofPurposeVerifier
+
+// NewProofPurposeRegistry returns a ProofPurposeRegistry with the proof purposes registered under their names.
+func NewProofPurposeRegistry(purposes ...ProofPurposeVerifier) ProofPurposeRegistry {
+	r := make(ProofPurposeRegistry, len(purposes))
+
+	for i := range purposes {
+		r[purposes[i].Name()] = purposes[i]
+	}
+
+	return r
+}
+
+// DefaultProofPurposeRegistry returns a ProofPurposeRegistry with all built-in proof purposes.
+func DefaultProofPurposeRegistry() ProofPurposeRegistry {
+	return NewProofPurposeRegistry(&CapabilityInvocationPurpose{})
+}
+
+// InvokerResolver reports whether the verification method is an authorized invoker of the capability.
+type InvokerResolver func(capability *Capability, vm *VerificationMethod) (bool, error)
+
+// CapabilityInvocationPurpose is the capabilityInvocation proof purpose.
+type CapabilityInvocationPurpose struct {
+	// IsInvoker decides whether the verification method is an authorized invoker of the capability.
+	// Defaults to IsInvoker.
+	IsInvoker InvokerResolver
+}
+
+// Name returns "capabilityInvocation".
+func (p *CapabilityInvocationPurpose) Name() string {
+	return ProofPurposeCapabilityInvocation
+}
+
+// Verify the authorized invoker of the proof's capability matches the verification method of the invocation
+// or its controller.
+func (p *CapabilityInvocationPurpose) Verify(proof *Proof, invocation *CapabilityInvocation) error {
+	isInvokerFunc := p.IsInvoker
+	if isInvokerFunc == nil {
+		isInvokerFunc = IsInvoker
+	}
+
+	isInvoker, err := isInvokerFunc(proof.Capability, invocation.VerificationMethod)
+	if err != nil {
+		return fmt.Errorf("isInvoke: %w", err)
+	}
+
+	if !isInvoker {
+		return fmt.Errorf(
+			"%w: the authorized invoker does not match the verification method or its controller",
+			ErrInvokerNotAuthorized)
+	}
+
+	return nil
+}
+
+// verify the proof against the invocation for the named proof purpose.
+func (r ProofPurposeRegistry) verify(name string, proof *Proof, invocation *CapabilityInvocation) error {
+	purpose, ok := r[name]
+	if !ok {
+		return fmt.Errorf("unsupported proof purpose: %s", name)
+	}
+
+	err := purpose.Verify(proof, invocation)
+	if err != nil {
+		return fmt.Errorf("failed to verify proof purpose %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestCapabilityInvocationPurpose(t *testing.T) {
+	p := &zcapld.CapabilityInvocationPurpose{}
+	require.Equal(t, zcapld.ProofPurposeCapabilityInvocation, p.Name())
+
+	t.Run("success: verification method is the invoker", func(t *testing.T) {
+		err := p.Verify(
+			&zcapld.Proof{Capability: &zcapld.Capability{Invoker: "did:example:123"}},
+			invocation("did:example:123"),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: verification method is not the invoker", func(t *testing.T) {
+		err := p.Verify(
+			&zcapld.Proof{Capability: &zcapld.Capability{Invoker: "did:example:123"}},
+			invocation("did:example:456"),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "the authorized invoker does not match")
+	})
+
+	t.Run("error: verification method is missing", func(t *testing.T) {
+		err := p.Verify(
+			&zcapld.Proof{Capability: &zcapld.Capability{Invoker: "did:example:123"}},
+			&zcapld.CapabilityInvocation{},
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "isInvoke")
+	})
+}
+
+func TestNewProofPurposeRegistry(t *testing.T) {
+	m := &mockProofPurpose{name: "assertionMethod"}
+	r := zcapld.NewProofPurposeRegistry(m, &zcapld.CapabilityInvocationPurpose{})
+	require.Len(t, r, 2)
+	require.Equal(t, m, r["assertionMethod"])
+	require.IsType(t, &zcapld.CapabilityInvocationPurpose{}, r[zcapld.ProofPurposeCapabilityInvocation])
+}
+
+func TestWithProofPurposeRegistry(t *testing.T) {
+	root, rootSigner := selfSignedSelfInvokingRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+
+	verify := func(r zcapld.ProofPurposeRegistry) error {
+		return verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithProofPurposeRegistry(r),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         root,
+				CapabilityAction:   "read",
+				VerificationMethod: root.Invoker,
+			},
+			invocation(root.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("success: custom capabilityInvocation proof purpose", func(t *testing.T) {
+		m := &mockProofPurpose{name: zcapld.ProofPurposeCapabilityInvocation}
+		require.NoError(t, verify(zcapld.NewProofPurposeRegistry(m)))
+		require.True(t, m.called)
+	})
+
+	t.Run("error: proof purpose rejects the proof", func(t *testing.T) {
+		m := &mockProofPurpose{name: zcapld.ProofPurposeCapabilityInvocation, err: errors.New("test")}
+		err := verify(zcapld.NewProofPurposeRegistry(m))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to verify proof purpose capabilityInvocation")
+	})
+
+	t.Run("error: capabilityInvocation proof purpose not registered", func(t *testing.T) {
+		err := verify(zcapld.NewProofPurposeRegistry(&mockProofPurpose{name: "assertionMethod"}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported proof purpose: capabilityInvocation")
+	})
+}
+
+type mockProofPurpose struct {
+	name   string
+	err    error
+	called bool
+}
+
+func (m *mockProofPurpose) Name() string {
+	return m.name
+}
+
+func (m *mockProofPurpose) Verify(*zcapld.Proof, *zcapld.CapabilityInvocation) error {
+	m.called = true
+
+	return m.err
+}
+
+func TestWithInvokerResolver(t *testing.T) {
+	root, rootSigner := selfSignedSelfInvokingRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+
+	verify := func(vm string, options ...zcapld.VerificationOption) error {
+		return verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			options...,
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         root,
+				CapabilityAction:   "read",
+				VerificationMethod: vm,
+			},
+			invocation(vm, expectRootCapability(root.ID)),
+		)
+	}
+
+	fingerprints := func(c *zcapld.Capability, vm *zcapld.VerificationMethod) (bool, error) {
+		return c.Invoker == root.Invoker && vm.ID == "urn:fingerprint:123", nil
+	}
+
+	t.Run("success: custom invoker resolution", func(t *testing.T) {
+		require.Error(t, verify("urn:fingerprint:123"))
+		require.NoError(t, verify("urn:fingerprint:123", zcapld.WithInvokerResolver(fingerprints)))
+	})
+
+	t.Run("success: replaces the default invoker resolution", func(t *testing.T) {
+		require.NoError(t, verify(root.Invoker))
+
+		err := verify(root.Invoker, zcapld.WithInvokerResolver(fingerprints))
+		require.True(t, errors.Is(err, zcapld.ErrInvokerNotAuthorized))
+	})
+
+	t.Run("error: invoker resolution fails", func(t *testing.T) {
+		err := verify(root.Invoker, zcapld.WithInvokerResolver(
+			func(*zcapld.Capability, *zcapld.VerificationMethod) (bool, error) {
+				return false, errors.New("test")
+			}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "isInvoke: test")
+	})
+}
+
+func TestProofPurposeMatch(t *testing.T) {
+	root, rootSigner := selfSignedSelfInvokingRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+
+	verify := func(proofPurpose, expectedPurpose string) error {
+		inv := invocation(root.Invoker, expectRootCapability(root.ID))
+		inv.Purpose = expectedPurpose
+
+		return verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         root,
+				CapabilityAction:   "read",
+				VerificationMethod: root.Invoker,
+				Purpose:            proofPurpose,
+			},
+			inv,
+		)
+	}
+
+	t.Run("success: purposes default to capabilityInvocation", func(t *testing.T) {
+		require.NoError(t, verify("", ""))
+		require.NoError(t, verify(zcapld.ProofPurposeCapabilityInvocation, ""))
+		require.NoError(t, verify("", zcapld.ProofPurposeCapabilityInvocation))
+		require.NoError(t, verify(zcapld.ProofPurposeCapabilityInvocation, zcapld.ProofPurposeCapabilityInvocation))
+	})
+
+	t.Run("error: proof purpose does not match the expected purpose", func(t *testing.T) {
+		err := verify(zcapld.ProofPurpose, zcapld.ProofPurposeCapabilityInvocation)
+		require.EqualError(t, err,
+			`proof purpose "capabilityDelegation" does not match the expected proof purpose "capabilityInvocation"`)
+	})
+
+	t.Run("error: delegation proofs cannot be used for invocations", func(t *testing.T) {
+		err := verify(zcapld.ProofPurpose, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported proof purpose: capabilityDelegation")
+	})
+}
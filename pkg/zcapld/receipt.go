@@ -0,0 +1,98 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/proof"
+	ariessigner "github.com/hyperledger/aries-framework-go/pkg/doc/signature/signer"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+// DelegationReceiptType is the JSON-LD type of delegation receipts.
+const DelegationReceiptType = "sec:DelegationReceipt"
+
+// DelegationReceipt is a signed statement that a verifier verified a delegated capability at some time. It is a
+// JSON-LD document in the security v2 context: its fields are terms of that context so that the signature covers
+// them all.
+type DelegationReceipt struct {
+	Context string `json:"@context"`
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	// CapabilityID is the ID of the verified capability.
+	CapabilityID string `json:"capability"`
+	// VerifiedAt is the time the capability was verified, to the second.
+	VerifiedAt time.Time `json:"created"`
+	// VerifierID is the verification method that signed the receipt.
+	VerifierID string `json:"creator"`
+	// Signature is the linked data proof of the receipt, for the assertionMethod purpose.
+	Signature verifiable.Proof `json:"proof,omitempty"`
+}
+
+// GenerateDelegationReceipt returns a receipt of the verification of the capability, signed by the signer. The
+// capability must have been verified beforehand, eg. with Verifier.Verify: the receipt only attests that it was.
+func GenerateDelegationReceipt(
+	ctx context.Context, capability *Capability, signer *Signer) (*DelegationReceipt, error) {
+	if capability == nil || capability.ID == "" {
+		return nil, errors.New("capability with an ID is required")
+	}
+
+	if signer == nil {
+		return nil, errors.New("must provide a signer")
+	}
+
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("failed to generate delegation receipt: %w", ctx.Err())
+	}
+
+	receipt := &DelegationReceipt{
+		Context:      SecurityContextV2,
+		ID:           fmt.Sprintf("urn:uuid:%s", uuid.New().String()),
+		Type:         DelegationReceiptType,
+		CapabilityID: capability.ID,
+		VerifiedAt:   time.Now().UTC().Truncate(time.Second),
+		VerifierID:   signer.VerificationMethod,
+	}
+
+	raw, err := json.Marshal(receipt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal delegation receipt: %w", err)
+	}
+
+	signedDoc, err := ariessigner.New(signer).Sign(
+		&ariessigner.Context{
+			SignatureType:           signer.SuiteType,
+			SignatureRepresentation: proof.SignatureJWS,
+			Created:                 &receipt.VerifiedAt,
+			VerificationMethod:      signer.VerificationMethod,
+			Purpose:                 ProofPurposeAssertionMethod,
+		},
+		raw,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("document signer failed to sign delegation receipt: %w", err)
+	}
+
+	proofs, err := parseProofs(signedDoc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proof for delegation receipt: %w", err)
+	}
+
+	if len(proofs) != 1 {
+		return nil, fmt.Errorf("expected one proof on the delegation receipt, got %d", len(proofs))
+	}
+
+	receipt.Signature = proofs[0]
+
+	return receipt, nil
+}
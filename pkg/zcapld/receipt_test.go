@@ -0,0 +1,75 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	ariesver "github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestGenerateDelegationReceipt(t *testing.T) {
+	signer := testSigner(t, kms.ED25519)
+	receiptSigner := &zcapld.Signer{
+		SignatureSuite:     ed25519signature2018.New(suite.WithSigner(signer)),
+		SuiteType:          ed25519signature2018.SignatureType,
+		VerificationMethod: keyID(signer),
+	}
+	capability := &zcapld.Capability{ID: "urn:zcap:123"}
+
+	t.Run("success", func(t *testing.T) {
+		before := time.Now().Truncate(time.Second)
+
+		receipt, err := zcapld.GenerateDelegationReceipt(context.Background(), capability, receiptSigner)
+		require.NoError(t, err)
+		require.Equal(t, zcapld.SecurityContextV2, receipt.Context)
+		require.NotEmpty(t, receipt.ID)
+		require.Equal(t, zcapld.DelegationReceiptType, receipt.Type)
+		require.Equal(t, capability.ID, receipt.CapabilityID)
+		require.Equal(t, keyID(signer), receipt.VerifierID)
+		require.False(t, receipt.VerifiedAt.Before(before))
+		require.Equal(t, zcapld.ProofPurposeAssertionMethod, receipt.Signature["proofPurpose"])
+
+		ver, err := ariesver.New(
+			zcapld.SimpleKeyResolver{keyID(signer): keyValue(t, signer)},
+			ed25519signature2018.New(suite.WithVerifier(ed25519signature2018.NewPublicKeyVerifier())),
+		)
+		require.NoError(t, err)
+		require.NoError(t, ver.Verify(marshal(t, receipt), jsonld.WithDocumentLoader(testLDDocumentLoader)))
+
+		receipt.CapabilityID = "urn:zcap:456"
+		require.Error(t, ver.Verify(marshal(t, receipt), jsonld.WithDocumentLoader(testLDDocumentLoader)))
+	})
+
+	t.Run("error: capability without ID", func(t *testing.T) {
+		_, err := zcapld.GenerateDelegationReceipt(context.Background(), &zcapld.Capability{}, receiptSigner)
+		require.EqualError(t, err, "capability with an ID is required")
+	})
+
+	t.Run("error: signer not provided", func(t *testing.T) {
+		_, err := zcapld.GenerateDelegationReceipt(context.Background(), capability, nil)
+		require.EqualError(t, err, "must provide a signer")
+	})
+
+	t.Run("error: context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := zcapld.GenerateDelegationReceipt(ctx, capability, receiptSigner)
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+}
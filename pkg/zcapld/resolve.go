@@ -7,8 +7,12 @@ SPDX-License-Identifier: Apache-2.0
 package zcapld
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
@@ -21,23 +25,231 @@ type KeyResolver interface {
 }
 
 // CapabilityResolver resolves capabilities.
+// Implementations that resolve capabilities remotely should stop when the context is done.
 type CapabilityResolver interface {
-	Resolve(uri string) (*Capability, error)
+	Resolve(ctx context.Context, uri string) (*Capability, error)
+}
+
+// BatchCapabilityResolver is a CapabilityResolver that can also resolve several capabilities at once, eg. with a
+// single request to a remote service. Verifiers resolve the capabilities of chains with ResolveMulti when their
+// resolver implements it.
+type BatchCapabilityResolver interface {
+	CapabilityResolver
+	// ResolveMulti resolves the capabilities with the IDs. The capability and error at index i are the result of
+	// resolving ids[i].
+	ResolveMulti(ctx context.Context, ids []string) ([]*Capability, []error)
+}
+
+// ControllerResolver resolves the controllers of verification methods.
+type ControllerResolver interface {
+	Resolve(controllerID string) (*Controller, error)
+}
+
+// Controller of verification methods, such as a DID.
+type Controller struct {
+	ID                   string
+	CapabilityInvocation []string
 }
 
 // SimpleCapabilityResolver enables in-memory capability resolvers based on maps.
 type SimpleCapabilityResolver map[string]*Capability
 
 // Resolve resolves capabilities.
-func (s SimpleCapabilityResolver) Resolve(uri string) (*Capability, error) {
+func (s SimpleCapabilityResolver) Resolve(_ context.Context, uri string) (*Capability, error) {
 	zcap, ok := s[uri]
 	if !ok {
-		return nil, fmt.Errorf("uri not found: %s", uri)
+		return nil, fmt.Errorf("%w: %s", ErrCapabilityNotFound, uri)
+	}
+
+	return zcap, nil
+}
+
+// MemoryResolver is an in-memory CapabilityResolver that is safe for concurrent use.
+type MemoryResolver struct {
+	zcaps sync.Map
+}
+
+// NewMemoryResolver returns a new MemoryResolver with the capabilities registered.
+func NewMemoryResolver(zcaps ...*Capability) *MemoryResolver {
+	m := &MemoryResolver{}
+
+	for i := range zcaps {
+		m.Register(zcaps[i])
+	}
+
+	return m
+}
+
+// Register the capability by its ID, replacing any capability registered with the same ID.
+func (m *MemoryResolver) Register(capability *Capability) {
+	m.zcaps.Store(capability.ID, capability)
+}
+
+// Resolve the capability registered with the ID, or return ErrCapabilityNotFound.
+func (m *MemoryResolver) Resolve(_ context.Context, id string) (*Capability, error) {
+	value, ok := m.zcaps.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCapabilityNotFound, id)
+	}
+
+	zcap, ok := value.(*Capability)
+	if !ok {
+		return nil, fmt.Errorf("invalid capability registered with id %s", id)
+	}
+
+	return zcap, nil
+}
+
+// CompositeResolver resolves capabilities with a list of resolvers, returning the first capability resolved.
+type CompositeResolver struct {
+	resolvers []CapabilityResolver
+}
+
+// NewCompositeResolver returns a new CompositeResolver that tries the resolvers in order.
+func NewCompositeResolver(resolvers ...CapabilityResolver) *CompositeResolver {
+	return &CompositeResolver{resolvers: resolvers}
+}
+
+// Resolve returns the capability from the first resolver that resolves it. If no resolver does, the error from
+// the first resolver that failed for a reason other than ErrCapabilityNotFound is returned.
+func (c *CompositeResolver) Resolve(ctx context.Context, uri string) (*Capability, error) {
+	var resolveErr error
+
+	for i := range c.resolvers {
+		zcap, err := c.resolvers[i].Resolve(ctx, uri)
+		if err == nil {
+			return zcap, nil
+		}
+
+		if resolveErr == nil && !errors.Is(err, ErrCapabilityNotFound) {
+			resolveErr = err
+		}
+	}
+
+	if resolveErr != nil {
+		return nil, fmt.Errorf("composite resolver: %w", resolveErr)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrCapabilityNotFound, uri)
+}
+
+// FallbackResolver resolves capabilities with a secondary resolver only if they are not found by the primary one.
+type FallbackResolver struct {
+	primary   CapabilityResolver
+	secondary CapabilityResolver
+}
+
+// NewFallbackResolver returns a new FallbackResolver.
+func NewFallbackResolver(primary, secondary CapabilityResolver) *FallbackResolver {
+	return &FallbackResolver{
+		primary:   primary,
+		secondary: secondary,
+	}
+}
+
+// Resolve the capability with the primary resolver, falling back to the secondary resolver if the primary one
+// returns ErrCapabilityNotFound. Other errors are returned without querying the secondary resolver.
+func (f *FallbackResolver) Resolve(ctx context.Context, uri string) (*Capability, error) {
+	zcap, err := f.primary.Resolve(ctx, uri)
+	if err == nil {
+		return zcap, nil
+	}
+
+	if !errors.Is(err, ErrCapabilityNotFound) {
+		return nil, fmt.Errorf("fallback resolver: primary: %w", err)
+	}
+
+	zcap, err = f.secondary.Resolve(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("fallback resolver: secondary: %w", err)
 	}
 
 	return zcap, nil
 }
 
+// CachingResolver caches the capabilities resolved by another CapabilityResolver for a fixed TTL.
+type CachingResolver struct {
+	inner CapabilityResolver
+	ttl   time.Duration
+	cache sync.Map
+}
+
+type cacheEntry struct {
+	zcap    *Capability
+	expires time.Time
+}
+
+// NewCachingResolver returns a new CachingResolver that caches capabilities resolved by 'inner' for 'ttl'.
+func NewCachingResolver(inner CapabilityResolver, ttl time.Duration) *CachingResolver {
+	return &CachingResolver{
+		inner: inner,
+		ttl:   ttl,
+	}
+}
+
+// Resolve returns the cached capability if not yet stale, otherwise resolves it with the inner resolver.
+func (c *CachingResolver) Resolve(ctx context.Context, uri string) (*Capability, error) {
+	if value, ok := c.cache.Load(uri); ok {
+		entry, ok := value.(*cacheEntry)
+		if ok && time.Now().Before(entry.expires) {
+			return entry.zcap, nil
+		}
+
+		c.cache.Delete(uri)
+	}
+
+	zcap, err := c.inner.Resolve(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("caching resolver: %w", err)
+	}
+
+	c.cache.Store(uri, &cacheEntry{zcap: zcap, expires: time.Now().Add(c.ttl)})
+
+	return zcap, nil
+}
+
+// Flush removes all cached capabilities.
+func (c *CachingResolver) Flush() {
+	c.cache.Range(func(key, _ interface{}) bool {
+		c.cache.Delete(key)
+
+		return true
+	})
+}
+
+// StartEviction starts a background goroutine that periodically removes stale capabilities from the cache.
+// The goroutine exits when 'ctx' is done. It is a no-op if the TTL is not positive since nothing stays cached.
+func (c *CachingResolver) StartEviction(ctx context.Context) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.ttl)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				c.evict(now)
+			}
+		}
+	}()
+}
+
+func (c *CachingResolver) evict(now time.Time) {
+	c.cache.Range(func(key, value interface{}) bool {
+		entry, ok := value.(*cacheEntry)
+		if !ok || !now.Before(entry.expires) {
+			c.cache.Delete(key)
+		}
+
+		return true
+	})
+}
+
 // SimpleKeyResolver enables in-memory key resolvers based on maps.
 type SimpleKeyResolver map[string]*verifier.PublicKey
 
@@ -51,6 +263,19 @@ func (s SimpleKeyResolver) Resolve(keyID string) (*verifier.PublicKey, error) {
 	return key, nil
 }
 
+// SimpleControllerResolver enables in-memory controller resolvers based on maps.
+type SimpleControllerResolver map[string]*Controller
+
+// Resolve resolves controllers.
+func (s SimpleControllerResolver) Resolve(controllerID string) (*Controller, error) {
+	controller, ok := s[controllerID]
+	if !ok {
+		return nil, fmt.Errorf("controller not found: %s", controllerID)
+	}
+
+	return controller, nil
+}
+
 // DIDKeyResolver resolves verification keys from did:key URLs: https://w3c-ccg.github.io/did-method-key/.
 type DIDKeyResolver struct {
 }
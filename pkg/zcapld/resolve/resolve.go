@@ -0,0 +1,111 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package resolve provides capability resolvers that need no central registry.
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+// ContentAddressPrefix prefixes the content addresses of capabilities.
+const ContentAddressPrefix = "urn:sha256:"
+
+// ContentAddress returns the content address of the capability: the hex-encoded SHA-256 hash of its canonical
+// JSON-LD form, prefixed with ContentAddressPrefix.
+//
+// The canonical form is the capability marshaled with zcapld.MarshalJSONLD, without its ID and proofs, and
+// re-encoded with sorted keys and no insignificant whitespace. The ID and the proofs are excluded because the ID is
+// the hash itself and the proofs are signed over the ID.
+func ContentAddress(c *zcapld.Capability) (string, error) {
+	raw, err := zcapld.MarshalJSONLD(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal capability to JSON-LD: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	doc := make(map[string]interface{})
+
+	err = decoder.Decode(&doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode capability JSON-LD: %w", err)
+	}
+
+	delete(doc, "id")
+	delete(doc, "proof")
+
+	canonical, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode canonical capability: %w", err)
+	}
+
+	hash := sha256.Sum256(canonical)
+
+	return ContentAddressPrefix + hex.EncodeToString(hash[:]), nil
+}
+
+// ContentAddressedResolver is a zcapld.CapabilityResolver of the capabilities registered with it, indexed by their
+// content addresses. The ID of such a capability is its own integrity proof. It is safe for concurrent use.
+type ContentAddressedResolver struct {
+	mutex sync.RWMutex
+	zcaps map[string]*zcapld.Capability
+}
+
+// NewContentAddressedResolver returns a new ContentAddressedResolver without capabilities.
+func NewContentAddressedResolver() *ContentAddressedResolver {
+	return &ContentAddressedResolver{zcaps: make(map[string]*zcapld.Capability)}
+}
+
+// Register stores a copy of the capability under its content address and returns the address. The ID of the
+// capability, if set, must be its content address.
+func (r *ContentAddressedResolver) Register(c *zcapld.Capability) (string, error) {
+	if c == nil {
+		return "", errors.New("capability is nil")
+	}
+
+	id, err := ContentAddress(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute content address: %w", err)
+	}
+
+	if c.ID != "" && c.ID != id {
+		return "", fmt.Errorf("the capability ID %s is not its content address %s", c.ID, id)
+	}
+
+	zcap := zcapld.Clone(c)
+	zcap.ID = id
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.zcaps[id] = zcap
+
+	return id, nil
+}
+
+// Resolve the capability registered under the content address.
+func (r *ContentAddressedResolver) Resolve(_ context.Context, uri string) (*zcapld.Capability, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	zcap, ok := r.zcaps[uri]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", zcapld.ErrCapabilityNotFound, uri)
+	}
+
+	return zcapld.Clone(zcap), nil
+}
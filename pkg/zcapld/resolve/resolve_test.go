@@ -0,0 +1,119 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolve_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/resolve"
+)
+
+var _ zcapld.CapabilityResolver = (*resolve.ContentAddressedResolver)(nil)
+
+func newCapability() *zcapld.Capability {
+	return &zcapld.Capability{
+		Context:          zcapld.SecurityContextV2,
+		Invoker:          "did:example:invoker",
+		AllowedAction:    []string{"read"},
+		InvocationTarget: zcapld.InvocationTarget{ID: "urn:target", Type: "urn:edv:document"},
+		Caveats:          []json.RawMessage{json.RawMessage(`{"type":"sec:MaxInvocationsCaveat","limit":3}`)},
+	}
+}
+
+func TestContentAddress(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		id, err := resolve.ContentAddress(newCapability())
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(id, resolve.ContentAddressPrefix))
+		require.Len(t, id, len(resolve.ContentAddressPrefix)+64)
+	})
+
+	t.Run("success: ignores the ID and the proofs", func(t *testing.T) {
+		expected, err := resolve.ContentAddress(newCapability())
+		require.NoError(t, err)
+
+		c := newCapability()
+		c.ID = "urn:zcap:123"
+		c.Proof = []verifiable.Proof{{"type": "Ed25519Signature2018"}}
+
+		id, err := resolve.ContentAddress(c)
+		require.NoError(t, err)
+		require.Equal(t, expected, id)
+	})
+
+	t.Run("success: differs on content", func(t *testing.T) {
+		expected, err := resolve.ContentAddress(newCapability())
+		require.NoError(t, err)
+
+		c := newCapability()
+		c.AllowedAction = []string{"write"}
+
+		id, err := resolve.ContentAddress(c)
+		require.NoError(t, err)
+		require.NotEqual(t, expected, id)
+	})
+
+	t.Run("error: unsupported context", func(t *testing.T) {
+		c := newCapability()
+		c.Context = "https://example.com/context"
+
+		_, err := resolve.ContentAddress(c)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported JSON-LD context")
+	})
+}
+
+func TestContentAddressedResolver(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		r := resolve.NewContentAddressedResolver()
+
+		expected := newCapability()
+
+		id, err := r.Register(expected)
+		require.NoError(t, err)
+		require.Empty(t, expected.ID)
+
+		result, err := r.Resolve(context.Background(), id)
+		require.NoError(t, err)
+		require.Equal(t, id, result.ID)
+
+		address, err := resolve.ContentAddress(result)
+		require.NoError(t, err)
+		require.Equal(t, id, address)
+
+		again, err := r.Register(result)
+		require.NoError(t, err)
+		require.Equal(t, id, again)
+	})
+
+	t.Run("error: ID is not the content address", func(t *testing.T) {
+		c := newCapability()
+		c.ID = "urn:zcap:123"
+
+		_, err := resolve.NewContentAddressedResolver().Register(c)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "the capability ID urn:zcap:123 is not its content address")
+	})
+
+	t.Run("error: nil capability", func(t *testing.T) {
+		_, err := resolve.NewContentAddressedResolver().Register(nil)
+		require.EqualError(t, err, "capability is nil")
+	})
+
+	t.Run("error: not found", func(t *testing.T) {
+		_, err := resolve.NewContentAddressedResolver().Resolve(context.Background(), "urn:sha256:00")
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+	})
+}
@@ -7,7 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package zcapld_test
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
@@ -39,6 +42,23 @@ func TestSimpleKeyResolver_Resolve(t *testing.T) {
 	})
 }
 
+func TestSimpleControllerResolver_Resolve(t *testing.T) {
+	t.Run("resolves the controller", func(t *testing.T) {
+		expected := &zcapld.Controller{
+			ID:                   uuid.New().String(),
+			CapabilityInvocation: []string{uuid.New().String()},
+		}
+		result, err := zcapld.SimpleControllerResolver{expected.ID: expected}.Resolve(expected.ID)
+		require.NoError(t, err)
+		require.Equal(t, expected, result)
+	})
+
+	t.Run("fails if controller not found", func(t *testing.T) {
+		_, err := zcapld.SimpleControllerResolver{}.Resolve("not found")
+		require.Error(t, err)
+	})
+}
+
 func TestDIDKeyResolver_Resolve(t *testing.T) {
 	t.Run("resolves a verification key from a did:key URL", func(t *testing.T) {
 		didKeyURL := "did:key:z6MkpTHR8VNsBxYAAWHut2Geadd9jSwuBV8xRoAnwWsdvktH#" +
@@ -73,3 +93,204 @@ func TestDIDKeyResolver_Resolve(t *testing.T) {
 		require.Contains(t, err.Error(), "failed to parse url")
 	})
 }
+
+func TestCachingResolver_Resolve(t *testing.T) {
+	t.Run("caches resolved capabilities", func(t *testing.T) {
+		inner := &countingResolver{resolver: zcapld.SimpleCapabilityResolver{"uri": {ID: "uri"}}}
+		r := zcapld.NewCachingResolver(inner, time.Hour)
+
+		for i := 0; i < 3; i++ {
+			result, err := r.Resolve(context.Background(), "uri")
+			require.NoError(t, err)
+			require.Equal(t, "uri", result.ID)
+		}
+
+		require.Equal(t, 1, inner.calls)
+	})
+
+	t.Run("resolves stale capabilities again", func(t *testing.T) {
+		inner := &countingResolver{resolver: zcapld.SimpleCapabilityResolver{"uri": {ID: "uri"}}}
+		r := zcapld.NewCachingResolver(inner, time.Millisecond)
+		_, err := r.Resolve(context.Background(), "uri")
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+		_, err = r.Resolve(context.Background(), "uri")
+		require.NoError(t, err)
+		require.Equal(t, 2, inner.calls)
+	})
+
+	t.Run("flushes the cache", func(t *testing.T) {
+		inner := &countingResolver{resolver: zcapld.SimpleCapabilityResolver{"uri": {ID: "uri"}}}
+		r := zcapld.NewCachingResolver(inner, time.Hour)
+		_, err := r.Resolve(context.Background(), "uri")
+		require.NoError(t, err)
+		r.Flush()
+		_, err = r.Resolve(context.Background(), "uri")
+		require.NoError(t, err)
+		require.Equal(t, 2, inner.calls)
+	})
+
+	t.Run("evicts stale capabilities in the background", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		resolver := zcapld.SimpleCapabilityResolver{"uri": {ID: "uri"}}
+		r := zcapld.NewCachingResolver(resolver, 10*time.Millisecond)
+		r.StartEviction(ctx)
+		_, err := r.Resolve(context.Background(), "uri")
+		require.NoError(t, err)
+		delete(resolver, "uri")
+		require.Eventually(t, func() bool {
+			_, err = r.Resolve(context.Background(), "uri")
+
+			return err != nil
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("no eviction without a positive ttl", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		r := zcapld.NewCachingResolver(zcapld.SimpleCapabilityResolver{"uri": {ID: "uri"}}, 0)
+		require.NotPanics(t, func() { r.StartEviction(ctx) })
+		_, err := r.Resolve(context.Background(), "uri")
+		require.NoError(t, err)
+	})
+
+	t.Run("error: inner resolver fails", func(t *testing.T) {
+		_, err := zcapld.NewCachingResolver(zcapld.SimpleCapabilityResolver{}, time.Hour).Resolve(context.Background(), "uri")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "uri not found")
+	})
+}
+
+func TestSimpleCapabilityResolver_Resolve(t *testing.T) {
+	t.Run("error: not found", func(t *testing.T) {
+		_, err := zcapld.SimpleCapabilityResolver{}.Resolve(context.Background(), "uri")
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+	})
+}
+
+func TestMemoryResolver_Resolve(t *testing.T) {
+	t.Run("resolves registered capabilities", func(t *testing.T) {
+		r := zcapld.NewMemoryResolver(&zcapld.Capability{ID: "urn:zcap:1"})
+		r.Register(&zcapld.Capability{ID: "urn:zcap:2"})
+
+		for _, id := range []string{"urn:zcap:1", "urn:zcap:2"} {
+			result, err := r.Resolve(context.Background(), id)
+			require.NoError(t, err)
+			require.Equal(t, id, result.ID)
+		}
+	})
+
+	t.Run("replaces capabilities with the same ID", func(t *testing.T) {
+		r := zcapld.NewMemoryResolver(&zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:1"})
+		r.Register(&zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:2"})
+		result, err := r.Resolve(context.Background(), "urn:zcap:1")
+		require.NoError(t, err)
+		require.Equal(t, "did:example:2", result.Invoker)
+	})
+
+	t.Run("error: not found", func(t *testing.T) {
+		_, err := zcapld.NewMemoryResolver().Resolve(context.Background(), "urn:zcap:1")
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+	})
+}
+
+func TestCompositeResolver_Resolve(t *testing.T) {
+	t.Run("returns the first capability resolved", func(t *testing.T) {
+		first := &countingResolver{resolver: zcapld.SimpleCapabilityResolver{}}
+		second := &countingResolver{resolver: zcapld.SimpleCapabilityResolver{"uri": {ID: "second"}}}
+		third := &countingResolver{resolver: zcapld.SimpleCapabilityResolver{"uri": {ID: "third"}}}
+		result, err := zcapld.NewCompositeResolver(first, second, third).Resolve(context.Background(), "uri")
+		require.NoError(t, err)
+		require.Equal(t, "second", result.ID)
+		require.Equal(t, 1, first.calls)
+		require.Equal(t, 0, third.calls)
+	})
+
+	t.Run("tries the next resolver after any error", func(t *testing.T) {
+		result, err := zcapld.NewCompositeResolver(
+			&failingResolver{err: errors.New("test")},
+			zcapld.SimpleCapabilityResolver{"uri": {ID: "uri"}},
+		).Resolve(context.Background(), "uri")
+		require.NoError(t, err)
+		require.Equal(t, "uri", result.ID)
+	})
+
+	t.Run("error: not found by any resolver", func(t *testing.T) {
+		_, err := zcapld.NewCompositeResolver(
+			zcapld.SimpleCapabilityResolver{},
+			zcapld.SimpleCapabilityResolver{},
+		).Resolve(context.Background(), "uri")
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+	})
+
+	t.Run("error: returns the first error other than not found", func(t *testing.T) {
+		expected := errors.New("test")
+		_, err := zcapld.NewCompositeResolver(
+			zcapld.SimpleCapabilityResolver{},
+			&failingResolver{err: expected},
+			&failingResolver{err: errors.New("other")},
+		).Resolve(context.Background(), "uri")
+		require.True(t, errors.Is(err, expected))
+		require.False(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+	})
+}
+
+func TestFallbackResolver_Resolve(t *testing.T) {
+	t.Run("resolves with the primary resolver", func(t *testing.T) {
+		secondary := &countingResolver{resolver: zcapld.SimpleCapabilityResolver{"uri": {ID: "secondary"}}}
+		result, err := zcapld.NewFallbackResolver(
+			zcapld.SimpleCapabilityResolver{"uri": {ID: "primary"}},
+			secondary,
+		).Resolve(context.Background(), "uri")
+		require.NoError(t, err)
+		require.Equal(t, "primary", result.ID)
+		require.Equal(t, 0, secondary.calls)
+	})
+
+	t.Run("falls back if the primary resolver does not find the capability", func(t *testing.T) {
+		result, err := zcapld.NewFallbackResolver(
+			zcapld.SimpleCapabilityResolver{},
+			zcapld.SimpleCapabilityResolver{"uri": {ID: "secondary"}},
+		).Resolve(context.Background(), "uri")
+		require.NoError(t, err)
+		require.Equal(t, "secondary", result.ID)
+	})
+
+	t.Run("error: primary resolver fails", func(t *testing.T) {
+		secondary := &countingResolver{resolver: zcapld.SimpleCapabilityResolver{"uri": {ID: "secondary"}}}
+		_, err := zcapld.NewFallbackResolver(&failingResolver{err: errors.New("test")}, secondary).
+			Resolve(context.Background(), "uri")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "fallback resolver: primary: test")
+		require.Equal(t, 0, secondary.calls)
+	})
+
+	t.Run("error: not found by the secondary resolver", func(t *testing.T) {
+		_, err := zcapld.NewFallbackResolver(zcapld.SimpleCapabilityResolver{}, zcapld.SimpleCapabilityResolver{}).
+			Resolve(context.Background(), "uri")
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+		require.Contains(t, err.Error(), "fallback resolver: secondary")
+	})
+}
+
+type failingResolver struct {
+	err error
+}
+
+func (f *failingResolver) Resolve(context.Context, string) (*zcapld.Capability, error) {
+	return nil, f.err
+}
+
+type countingResolver struct {
+	resolver zcapld.CapabilityResolver
+	calls    int
+}
+
+func (c *countingResolver) Resolve(ctx context.Context, uri string) (*zcapld.Capability, error) {
+	c.calls++
+
+	return c.resolver.Resolve(ctx, uri)
+}
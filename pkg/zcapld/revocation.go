@@ -0,0 +1,42 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import "sync"
+
+// RevocationChecker checks whether capabilities have been revoked.
+type RevocationChecker interface {
+	IsRevoked(capabilityID string) (bool, error)
+}
+
+// MemoryRevocationChecker is an in-memory RevocationChecker.
+type MemoryRevocationChecker struct {
+	revoked sync.Map
+}
+
+// NewMemoryRevocationChecker returns a new MemoryRevocationChecker with the given capabilities revoked.
+func NewMemoryRevocationChecker(capabilityIDs ...string) *MemoryRevocationChecker {
+	m := &MemoryRevocationChecker{}
+
+	for i := range capabilityIDs {
+		m.Revoke(capabilityIDs[i])
+	}
+
+	return m
+}
+
+// Revoke the capability.
+func (m *MemoryRevocationChecker) Revoke(capabilityID string) {
+	m.revoked.Store(capabilityID, struct{}{})
+}
+
+// IsRevoked reports whether the capability has been revoked.
+func (m *MemoryRevocationChecker) IsRevoked(capabilityID string) (bool, error) {
+	_, revoked := m.revoked.Load(capabilityID)
+
+	return revoked, nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestMemoryRevocationChecker(t *testing.T) {
+	t.Run("reports revoked capabilities", func(t *testing.T) {
+		checker := zcapld.NewMemoryRevocationChecker("urn:zcap:1")
+		checker.Revoke("urn:zcap:2")
+
+		for _, id := range []string{"urn:zcap:1", "urn:zcap:2"} {
+			revoked, err := checker.IsRevoked(id)
+			require.NoError(t, err)
+			require.True(t, revoked)
+		}
+
+		revoked, err := checker.IsRevoked("urn:zcap:3")
+		require.NoError(t, err)
+		require.False(t, revoked)
+	})
+}
+
+func TestWithRevocationChecker(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	chain := delegationChain(t, root, rootSigner, 1)
+	parent := chain[len(chain)-1]
+	capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+		withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(parent.zcap.ID),
+		withVerMethod(keyID(parent.signer)), withCapabilityChain([]interface{}{root.ID, parent.zcap.ID}))
+
+	verify := func(checker zcapld.RevocationChecker) error {
+		return verifier(t, chainResolver(root, chain), chainKeyResolver(t, rootSigner, chain),
+			zcapld.WithRevocationChecker(checker),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("success: no capability revoked", func(t *testing.T) {
+		require.NoError(t, verify(zcapld.NewMemoryRevocationChecker("urn:zcap:other")))
+	})
+
+	t.Run("error: capability revoked", func(t *testing.T) {
+		for _, id := range []string{root.ID, parent.zcap.ID, capability.ID} {
+			err := verify(zcapld.NewMemoryRevocationChecker(id))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "capability "+id+" has been revoked")
+		}
+	})
+
+	t.Run("error: revocation checker fails", func(t *testing.T) {
+		err := verify(&failingRevocationChecker{err: errors.New("test")})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to check revocation of capability")
+	})
+}
+
+type failingRevocationChecker struct {
+	err error
+}
+
+func (f *failingRevocationChecker) IsRevoked(string) (bool, error) {
+	return false, f.err
+}
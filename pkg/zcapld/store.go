@@ -0,0 +1,160 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// CapabilityStore persists capabilities, eg. those delegated by a service.
+type CapabilityStore interface {
+	// Save the capability, replacing any capability saved with the same ID.
+	Save(ctx context.Context, c *Capability) error
+	// Get the capability with the ID. Returns ErrCapabilityNotFound if there is none.
+	Get(ctx context.Context, id string) (*Capability, error)
+	// Delete the capability with the ID. Returns ErrCapabilityNotFound if there is none.
+	Delete(ctx context.Context, id string) error
+	// List the capabilities that match the filter.
+	List(ctx context.Context, filter CapabilityFilter) ([]*Capability, error)
+}
+
+// CapabilityFilter narrows the capabilities listed from a CapabilityStore. Empty fields match all capabilities.
+type CapabilityFilter struct {
+	// InvokerID matches capabilities with this invoker.
+	InvokerID string
+	// DelegatorID matches capabilities with this delegator.
+	DelegatorID string
+	// RootID matches capabilities with this root capability. A root capability is its own root.
+	RootID string
+}
+
+// matches reports whether the capability matches the filter.
+func (f *CapabilityFilter) matches(c *Capability) bool {
+	if f.InvokerID != "" && f.InvokerID != c.Invoker {
+		return false
+	}
+
+	if f.DelegatorID != "" && f.DelegatorID != c.Delegator {
+		return false
+	}
+
+	if f.RootID == "" {
+		return true
+	}
+
+	rootID, err := c.rootID()
+
+	return err == nil && rootID == f.RootID
+}
+
+// rootID returns the ID of this capability's root capability: the first entry of its capability chain, or its own
+// ID if it is a root capability.
+func (c *Capability) rootID() (string, error) {
+	chain, err := c.capabilityChain()
+	if err != nil {
+		return "", fmt.Errorf("failed to get capabilityChain: %w", err)
+	}
+
+	if len(chain) == 0 {
+		return c.ID, nil
+	}
+
+	id := parentID(chain[0])
+	if id == "" {
+		return "", fmt.Errorf("invalid capability URI format: %v", chain[0])
+	}
+
+	return id, nil
+}
+
+// MemoryCapabilityStore is an in-memory CapabilityStore that is safe for concurrent use. It is also a
+// CapabilityResolver of the capabilities it stores.
+type MemoryCapabilityStore struct {
+	mutex sync.RWMutex
+	zcaps map[string]*Capability
+}
+
+// NewMemoryCapabilityStore returns a new, empty MemoryCapabilityStore.
+func NewMemoryCapabilityStore() *MemoryCapabilityStore {
+	return &MemoryCapabilityStore{zcaps: make(map[string]*Capability)}
+}
+
+// Save the capability, replacing any capability saved with the same ID.
+func (m *MemoryCapabilityStore) Save(_ context.Context, c *Capability) error {
+	if c == nil || c.ID == "" {
+		return errors.New("capability with an ID is required")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.zcaps[c.ID] = c
+
+	return nil
+}
+
+// Get the capability with the ID.
+func (m *MemoryCapabilityStore) Get(_ context.Context, id string) (*Capability, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	zcap, ok := m.zcaps[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCapabilityNotFound, id)
+	}
+
+	return zcap, nil
+}
+
+// Delete the capability with the ID.
+func (m *MemoryCapabilityStore) Delete(_ context.Context, id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.zcaps[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrCapabilityNotFound, id)
+	}
+
+	delete(m.zcaps, id)
+
+	return nil
+}
+
+// List the capabilities that match the filter, ordered by ID.
+func (m *MemoryCapabilityStore) List(_ context.Context, filter CapabilityFilter) ([]*Capability, error) {
+	return m.By(filter), nil
+}
+
+// By returns the capabilities that match all the non-empty fields of the filter, ordered by ID. It scans all the
+// stored capabilities.
+func (m *MemoryCapabilityStore) By(filter CapabilityFilter) []*Capability {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	zcaps := make([]*Capability, 0)
+
+	for _, zcap := range m.zcaps {
+		if filter.matches(zcap) {
+			zcaps = append(zcaps, zcap)
+		}
+	}
+
+	sort.Slice(zcaps, func(i, j int) bool {
+		return zcaps[i].ID < zcaps[j].ID
+	})
+
+	return zcaps
+}
+
+// Resolve the capability with the ID.
+func (m *MemoryCapabilityStore) Resolve(ctx context.Context, id string) (*Capability, error) {
+	return m.Get(ctx, id)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestMemoryCapabilityStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("save, get, and delete", func(t *testing.T) {
+		store := zcapld.NewMemoryCapabilityStore()
+		zcap := &zcapld.Capability{ID: "urn:zcap:1"}
+
+		require.NoError(t, store.Save(ctx, zcap))
+
+		result, err := store.Get(ctx, zcap.ID)
+		require.NoError(t, err)
+		require.Equal(t, zcap, result)
+
+		result, err = store.Resolve(ctx, zcap.ID)
+		require.NoError(t, err)
+		require.Equal(t, zcap, result)
+
+		require.NoError(t, store.Delete(ctx, zcap.ID))
+
+		_, err = store.Get(ctx, zcap.ID)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+
+		err = store.Delete(ctx, zcap.ID)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+	})
+
+	t.Run("error: capability without an ID", func(t *testing.T) {
+		store := zcapld.NewMemoryCapabilityStore()
+
+		for _, zcap := range []*zcapld.Capability{nil, {}} {
+			err := store.Save(ctx, zcap)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "capability with an ID is required")
+		}
+	})
+
+	t.Run("list with filters", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 2)
+		other, _ := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		invoked := &zcapld.Capability{ID: "urn:zcap:invoked", Invoker: "did:example:invoker"}
+
+		store := zcapld.NewMemoryCapabilityStore()
+
+		for _, zcap := range []*zcapld.Capability{root, chain[0].zcap, chain[1].zcap, other, invoked} {
+			require.NoError(t, store.Save(ctx, zcap))
+		}
+
+		all, err := store.List(ctx, zcapld.CapabilityFilter{})
+		require.NoError(t, err)
+		require.Len(t, all, 5)
+
+		for i := 1; i < len(all); i++ {
+			require.True(t, all[i-1].ID < all[i].ID)
+		}
+
+		result, err := store.List(ctx, zcapld.CapabilityFilter{RootID: root.ID})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []*zcapld.Capability{root, chain[0].zcap, chain[1].zcap}, result)
+
+		result, err = store.List(ctx, zcapld.CapabilityFilter{DelegatorID: chain[1].zcap.Delegator})
+		require.NoError(t, err)
+		require.Equal(t, []*zcapld.Capability{chain[1].zcap}, result)
+
+		result, err = store.List(ctx, zcapld.CapabilityFilter{InvokerID: invoked.Invoker})
+		require.NoError(t, err)
+		require.Equal(t, []*zcapld.Capability{invoked}, result)
+
+		result, err = store.List(ctx, zcapld.CapabilityFilter{
+			RootID:      root.ID,
+			DelegatorID: chain[0].zcap.Delegator,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*zcapld.Capability{chain[0].zcap}, result)
+
+		result, err = store.List(ctx, zcapld.CapabilityFilter{InvokerID: "did:example:none"})
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("by invoker and delegator", func(t *testing.T) {
+		zcaps := []*zcapld.Capability{
+			{ID: "urn:zcap:1", Invoker: "did:example:a", Delegator: "did:example:b"},
+			{ID: "urn:zcap:2", Invoker: "did:example:a", Delegator: "did:example:c"},
+			{ID: "urn:zcap:3", Invoker: "did:example:b", Delegator: "did:example:c"},
+		}
+
+		store := zcapld.NewMemoryCapabilityStore()
+
+		for i := range zcaps {
+			require.NoError(t, store.Save(ctx, zcaps[i]))
+		}
+
+		require.Equal(t, zcaps, store.By(zcapld.CapabilityFilter{}))
+		require.Equal(t, zcaps[:2], store.By(zcapld.CapabilityFilter{InvokerID: "did:example:a"}))
+		require.Equal(t, zcaps[1:], store.By(zcapld.CapabilityFilter{DelegatorID: "did:example:c"}))
+		require.Equal(t, zcaps[1:2], store.By(zcapld.CapabilityFilter{
+			InvokerID:   "did:example:a",
+			DelegatorID: "did:example:c",
+		}))
+		require.Empty(t, store.By(zcapld.CapabilityFilter{InvokerID: "did:example:c"}))
+	})
+
+	t.Run("list by a root capability embedded in the chain", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		zcap := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withParent(root.ID), withCapabilityChain([]interface{}{embedded(t, root)}))
+
+		store := zcapld.NewMemoryCapabilityStore()
+		require.NoError(t, store.Save(ctx, zcap))
+
+		result, err := store.List(ctx, zcapld.CapabilityFilter{RootID: root.ID})
+		require.NoError(t, err)
+		require.Equal(t, []*zcapld.Capability{zcap}, result)
+	})
+}
+
+// BenchmarkMemoryCapabilityStore_By scans stores of up to 10k capabilities, of which 1 in 100 match.
+func BenchmarkMemoryCapabilityStore_By(b *testing.B) {
+	for _, size := range []int{100, 1000, 10000} {
+		store := zcapld.NewMemoryCapabilityStore()
+
+		for i := 0; i < size; i++ {
+			err := store.Save(context.Background(), &zcapld.Capability{
+				ID:        fmt.Sprintf("urn:zcap:%d", i),
+				Invoker:   fmt.Sprintf("did:example:invoker-%d", i%100),
+				Delegator: fmt.Sprintf("did:example:delegator-%d", i%10),
+			})
+			require.NoError(b, err)
+		}
+
+		filters := map[string]zcapld.CapabilityFilter{
+			"invoker":               {InvokerID: "did:example:invoker-1"},
+			"invoker-and-delegator": {DelegatorID: "did:example:delegator-1", InvokerID: "did:example:invoker-1"},
+		}
+
+		for name, filter := range filters {
+			filter := filter
+
+			b.Run(fmt.Sprintf("%s/%d", name, size), func(b *testing.B) {
+				b.ReportAllocs()
+
+				for n := 0; n < b.N; n++ {
+					if len(store.By(filter)) != size/100 {
+						b.Fatal("unexpected number of capabilities")
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import "fmt"
+
+// InvocationTargetHandler validates the invocation targets of a type, eg. the IDs of EDV vaults or KMS keys.
+type InvocationTargetHandler interface {
+	// ValidateTarget returns an error if the target is not valid for its type.
+	ValidateTarget(target InvocationTarget) error
+}
+
+// InvocationTargetHandlerFunc is an adapter to allow the use of ordinary functions as InvocationTargetHandlers.
+type InvocationTargetHandlerFunc func(target InvocationTarget) error
+
+// ValidateTarget calls f(target).
+func (f InvocationTargetHandlerFunc) ValidateTarget(target InvocationTarget) error {
+	return f(target)
+}
+
+// InvocationTargetTypeRegistry maps invocation target types to the handler validating the targets of the type.
+type InvocationTargetTypeRegistry map[string]InvocationTargetHandler
+
+// NewInvocationTargetTypeRegistry returns an empty InvocationTargetTypeRegistry.
+func NewInvocationTargetTypeRegistry() InvocationTargetTypeRegistry {
+	return make(InvocationTargetTypeRegistry)
+}
+
+// RegisterHandler registers the handler of the targets of the type, replacing any handler registered for the type.
+func (r InvocationTargetTypeRegistry) RegisterHandler(targetType string, handler InvocationTargetHandler) {
+	r[targetType] = handler
+}
+
+// WithTargetTypeRegistry sets the handlers validating the invocation target of the root capability, according to
+// its type. Targets of types without a handler are not validated further. Defaults to no handlers.
+func WithTargetTypeRegistry(registry InvocationTargetTypeRegistry) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.TargetTypes = registry
+	}
+}
+
+// validate the target with the handler of its type, if any.
+func (r InvocationTargetTypeRegistry) validate(target InvocationTarget) error {
+	handler, ok := r[target.Type]
+	if !ok {
+		return nil
+	}
+
+	err := handler.ValidateTarget(target)
+	if err != nil {
+		return fmt.Errorf("%w: target %s of type %s: %s", ErrInvalidInvocationTarget, target.ID, target.Type, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestWithTargetTypeRegistry(t *testing.T) {
+	root, rootSigner := selfSignedSelfInvokingRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	verify := func(registry zcapld.InvocationTargetTypeRegistry) error {
+		return verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithTargetTypeRegistry(registry),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         root,
+				CapabilityAction:   "read",
+				VerificationMethod: root.Invoker,
+			},
+			invocation(root.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("success: handler accepts the target", func(t *testing.T) {
+		var validated zcapld.InvocationTarget
+
+		registry := zcapld.NewInvocationTargetTypeRegistry()
+		registry.RegisterHandler("urn:edv:document", zcapld.InvocationTargetHandlerFunc(
+			func(target zcapld.InvocationTarget) error {
+				validated = target
+
+				return nil
+			}))
+
+		require.NoError(t, verify(registry))
+		require.Equal(t, root.InvocationTarget, validated)
+	})
+
+	t.Run("success: no handler for the target type", func(t *testing.T) {
+		registry := zcapld.NewInvocationTargetTypeRegistry()
+		registry.RegisterHandler("urn:kms:key", zcapld.InvocationTargetHandlerFunc(
+			func(zcapld.InvocationTarget) error {
+				return errors.New("test")
+			}))
+
+		require.NoError(t, verify(registry))
+		require.NoError(t, verify(nil))
+	})
+
+	t.Run("error: handler rejects the target", func(t *testing.T) {
+		registry := zcapld.NewInvocationTargetTypeRegistry()
+		registry.RegisterHandler("urn:edv:document", zcapld.InvocationTargetHandlerFunc(
+			func(zcapld.InvocationTarget) error {
+				return errors.New("not a document")
+			}))
+
+		err := verify(registry)
+		require.True(t, errors.Is(err, zcapld.ErrInvalidInvocationTarget))
+		require.Contains(t, err.Error(), "of type urn:edv:document: not a document")
+
+		chainErr := &zcapld.ChainVerificationError{}
+		require.True(t, errors.As(err, &chainErr))
+		require.Equal(t, zcapld.ChainReasonRootCapability, chainErr.Reason)
+	})
+}
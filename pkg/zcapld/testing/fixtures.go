@@ -0,0 +1,190 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package testing provides fixture factories for tests of code that uses zcapld.
+package testing
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+// TestCapabilityOpt sets a field of the capability returned by NewTestCapability.
+type TestCapabilityOpt func(*zcapld.Capability)
+
+// WithInvoker sets the invoker of the capability.
+func WithInvoker(invoker string) TestCapabilityOpt {
+	return func(c *zcapld.Capability) {
+		c.Invoker = invoker
+	}
+}
+
+// WithController sets the controller of the capability.
+func WithController(controller string) TestCapabilityOpt {
+	return func(c *zcapld.Capability) {
+		c.Controller = controller
+	}
+}
+
+// WithDelegator sets the delegator of the capability.
+func WithDelegator(delegator string) TestCapabilityOpt {
+	return func(c *zcapld.Capability) {
+		c.Delegator = delegator
+	}
+}
+
+// WithParent sets the parent of the capability and adds an unsigned delegation proof with the capability chain,
+// which ends with the parent.
+func WithParent(chain ...string) TestCapabilityOpt {
+	return func(c *zcapld.Capability) {
+		if len(chain) == 0 {
+			return
+		}
+
+		links := make([]interface{}, len(chain))
+
+		for i := range chain {
+			links[i] = chain[i]
+		}
+
+		c.Parent = chain[len(chain)-1]
+		c.Proof = append(c.Proof, verifiable.Proof{
+			"proofPurpose":    zcapld.ProofPurpose,
+			"capabilityChain": links,
+		})
+	}
+}
+
+// WithDelegatedBy sets the verification method of the delegation proofs of the capability, added by WithParent.
+func WithDelegatedBy(verificationMethod string) TestCapabilityOpt {
+	return func(c *zcapld.Capability) {
+		for i := range c.Proof {
+			if c.Proof[i]["proofPurpose"] == zcapld.ProofPurpose {
+				c.Proof[i]["verificationMethod"] = verificationMethod
+			}
+		}
+	}
+}
+
+// WithAllowedActions sets the allowed actions of the capability.
+func WithAllowedActions(actions ...string) TestCapabilityOpt {
+	return func(c *zcapld.Capability) {
+		c.AllowedAction = actions
+	}
+}
+
+// WithAudience sets the audience of the capability.
+func WithAudience(audience ...string) TestCapabilityOpt {
+	return func(c *zcapld.Capability) {
+		c.Audience = audience
+	}
+}
+
+// WithTargetType sets the type of the invocation target of the capability.
+func WithTargetType(targetType string) TestCapabilityOpt {
+	return func(c *zcapld.Capability) {
+		c.InvocationTarget.Type = targetType
+	}
+}
+
+// WithExpiresAt sets the expiry of the capability.
+func WithExpiresAt(expires time.Time) TestCapabilityOpt {
+	return func(c *zcapld.Capability) {
+		c.ExpiresAt = expires.Format(time.RFC3339)
+	}
+}
+
+// WithCaveats adds the JSON-encoded caveats to the capability.
+func WithCaveats(caveats ...json.RawMessage) TestCapabilityOpt {
+	return func(c *zcapld.Capability) {
+		c.Caveats = append(c.Caveats, caveats...)
+	}
+}
+
+// NewTestCapability returns an unsigned capability with the ID and invocation target. Sign it with
+// zcapld.SignCapability for tests that verify its proofs.
+func NewTestCapability(id, target string, opts ...TestCapabilityOpt) *zcapld.Capability {
+	c := &zcapld.Capability{
+		Context:          zcapld.SecurityContextV2,
+		ID:               id,
+		InvocationTarget: zcapld.InvocationTarget{ID: target},
+	}
+
+	for i := range opts {
+		opts[i](c)
+	}
+
+	return c
+}
+
+// TestInvocationOpt sets a field of the invocation returned by NewTestInvocation.
+type TestInvocationOpt func(*zcapld.CapabilityInvocation)
+
+// WithExpectedTarget sets the expected invocation target.
+func WithExpectedTarget(target string) TestInvocationOpt {
+	return func(i *zcapld.CapabilityInvocation) {
+		i.ExpectedTarget = target
+	}
+}
+
+// WithExpectedAction sets the expected action.
+func WithExpectedAction(action string) TestInvocationOpt {
+	return func(i *zcapld.CapabilityInvocation) {
+		i.ExpectedAction = action
+	}
+}
+
+// WithExpectedRootCapability sets the ID of the expected root capability.
+func WithExpectedRootCapability(id string) TestInvocationOpt {
+	return func(i *zcapld.CapabilityInvocation) {
+		i.ExpectedRootCapability = id
+	}
+}
+
+// WithExpectedAudience sets the expected audience.
+func WithExpectedAudience(audience string) TestInvocationOpt {
+	return func(i *zcapld.CapabilityInvocation) {
+		i.ExpectedAudience = audience
+	}
+}
+
+// WithVerificationMethod sets the verification method of the invocation.
+func WithVerificationMethod(vm *zcapld.VerificationMethod) TestInvocationOpt {
+	return func(i *zcapld.CapabilityInvocation) {
+		i.VerificationMethod = vm
+	}
+}
+
+// NewTestInvocation returns a capability invocation that expects nothing but the options.
+func NewTestInvocation(opts ...TestInvocationOpt) *zcapld.CapabilityInvocation {
+	i := &zcapld.CapabilityInvocation{}
+
+	for j := range opts {
+		opts[j](i)
+	}
+
+	return i
+}
+
+// NewTestVerificationMethod returns a verification method with the ID and controller.
+func NewTestVerificationMethod(id, controller string) *zcapld.VerificationMethod {
+	return &zcapld.VerificationMethod{ID: id, Controller: controller}
+}
+
+// NewTestProof returns a proof of the invocation of the action of the capability by the verification method.
+func NewTestProof(c *zcapld.Capability, action string, vm *zcapld.VerificationMethod) *zcapld.Proof {
+	p := &zcapld.Proof{Capability: c, CapabilityAction: action}
+
+	if vm != nil {
+		p.VerificationMethod = vm.ID
+	}
+
+	return p
+}
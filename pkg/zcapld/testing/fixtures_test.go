@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package testing_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	zcapldtesting "github.com/trustbloc/edge-core/pkg/zcapld/testing"
+)
+
+func TestNewTestCapability(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	caveat := json.RawMessage(`{"type":"urn:test:caveat"}`)
+
+	c := zcapldtesting.NewTestCapability("urn:zcap:child", "urn:target",
+		zcapldtesting.WithInvoker("did:example:invoker"),
+		zcapldtesting.WithController("did:example:controller"),
+		zcapldtesting.WithDelegator("did:example:delegator"),
+		zcapldtesting.WithParent("urn:zcap:root", "urn:zcap:parent"),
+		zcapldtesting.WithAllowedActions("read"),
+		zcapldtesting.WithAudience("https://example.com"),
+		zcapldtesting.WithTargetType("urn:edv:document"),
+		zcapldtesting.WithExpiresAt(expires),
+		zcapldtesting.WithCaveats(caveat),
+	)
+	require.Equal(t, zcapld.SecurityContextV2, c.Context)
+	require.Equal(t, "urn:zcap:child", c.ID)
+	require.Equal(t, zcapld.InvocationTarget{ID: "urn:target", Type: "urn:edv:document"}, c.InvocationTarget)
+	require.Equal(t, "did:example:invoker", c.Invoker)
+	require.Equal(t, "did:example:controller", c.Controller)
+	require.Equal(t, "did:example:delegator", c.Delegator)
+	require.Equal(t, []string{"read"}, c.AllowedAction)
+	require.Equal(t, []string{"https://example.com"}, c.Audience)
+	require.Equal(t, expires.Format(time.RFC3339), c.ExpiresAt)
+	require.Equal(t, []json.RawMessage{caveat}, c.Caveats)
+
+	require.Equal(t, "urn:zcap:parent", c.Parent)
+	depth, err := c.Depth()
+	require.NoError(t, err)
+	require.Equal(t, 2, depth)
+}
+
+func TestNewTestInvocation(t *testing.T) {
+	vm := zcapldtesting.NewTestVerificationMethod("did:example:123#key1", "did:example:123")
+	require.Equal(t, &zcapld.VerificationMethod{ID: "did:example:123#key1", Controller: "did:example:123"}, vm)
+
+	require.Equal(t, &zcapld.CapabilityInvocation{
+		ExpectedTarget:         "urn:target",
+		ExpectedAction:         "read",
+		ExpectedRootCapability: "urn:zcap:root",
+		ExpectedAudience:       "https://example.com",
+		VerificationMethod:     vm,
+	}, zcapldtesting.NewTestInvocation(
+		zcapldtesting.WithExpectedTarget("urn:target"),
+		zcapldtesting.WithExpectedAction("read"),
+		zcapldtesting.WithExpectedRootCapability("urn:zcap:root"),
+		zcapldtesting.WithExpectedAudience("https://example.com"),
+		zcapldtesting.WithVerificationMethod(vm),
+	))
+}
+
+func TestNewTestProof(t *testing.T) {
+	c := zcapldtesting.NewTestCapability("urn:zcap:root", "urn:zcap:root")
+	vm := zcapldtesting.NewTestVerificationMethod("did:example:123#key1", "did:example:123")
+
+	require.Equal(t, &zcapld.Proof{Capability: c, CapabilityAction: "read", VerificationMethod: vm.ID},
+		zcapldtesting.NewTestProof(c, "read", vm))
+	require.Empty(t, zcapldtesting.NewTestProof(c, "read", nil).VerificationMethod)
+}
+
+func TestFixtures_VerifyCapabilityChain(t *testing.T) {
+	root := zcapldtesting.NewTestCapability("urn:zcap:root", "urn:target",
+		zcapldtesting.WithController("did:example:root"), zcapldtesting.WithAllowedActions("read", "write"))
+	child := zcapldtesting.NewTestCapability("urn:zcap:child", "urn:target",
+		zcapldtesting.WithParent(root.ID), zcapldtesting.WithDelegatedBy("did:example:root#key-1"),
+		zcapldtesting.WithAllowedActions("read"))
+
+	err := zcapld.VerifyCapabilityChain(context.Background(), zcapld.SimpleCapabilityResolver{root.ID: root}, child,
+		"read", zcapldtesting.NewTestInvocation(
+			zcapldtesting.WithExpectedAction("read"), zcapldtesting.WithExpectedRootCapability(root.ID)))
+	require.NoError(t, err)
+}
@@ -0,0 +1,41 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld
+
+import "context"
+
+// TraceContext identifies the trace and span of a capability invocation, eg. an OpenTelemetry or Zipkin span
+// context. It is passed to the resolvers of the capability chain so their calls can be correlated with the
+// invocation.
+type TraceContext interface {
+	TraceID() string
+	SpanID() string
+}
+
+type traceContextKey struct{}
+
+// ContextWithTraceContext returns a copy of ctx carrying the TraceContext.
+func ContextWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceContextFromContext returns the TraceContext carried by ctx, if any. CapabilityResolvers call it to
+// correlate their Resolve calls with the invocation being verified.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+
+	return tc, ok && tc != nil
+}
+
+// withInvocationTrace attaches the TraceContext of the invocation, if any, to ctx.
+func withInvocationTrace(ctx context.Context, invocation *CapabilityInvocation) context.Context {
+	if invocation == nil || invocation.TraceContext == nil {
+		return ctx
+	}
+
+	return ContextWithTraceContext(ctx, invocation.TraceContext)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	zcapldtesting "github.com/trustbloc/edge-core/pkg/zcapld/testing"
+)
+
+type testTraceContext struct {
+	traceID string
+	spanID  string
+}
+
+func (t *testTraceContext) TraceID() string {
+	return t.traceID
+}
+
+func (t *testTraceContext) SpanID() string {
+	return t.spanID
+}
+
+// tracingResolver records the trace contexts its Resolve calls were made with.
+type tracingResolver struct {
+	zcapld.SimpleCapabilityResolver
+	traces []zcapld.TraceContext
+}
+
+func (r *tracingResolver) Resolve(ctx context.Context, uri string) (*zcapld.Capability, error) {
+	tc, _ := zcapld.TraceContextFromContext(ctx)
+	r.traces = append(r.traces, tc)
+
+	return r.SimpleCapabilityResolver.Resolve(ctx, uri)
+}
+
+func TestTraceContext(t *testing.T) {
+	root := zcapldtesting.NewTestCapability("urn:zcap:root", "urn:zcap:root",
+		zcapldtesting.WithController("did:example:root"), zcapldtesting.WithAllowedActions("read"))
+	child := zcapldtesting.NewTestCapability("urn:zcap:child", root.ID,
+		zcapldtesting.WithParent(root.ID), zcapldtesting.WithDelegatedBy("did:example:root#key-1"),
+		zcapldtesting.WithAllowedActions("read"))
+
+	t.Run("propagated to the resolver", func(t *testing.T) {
+		tc := &testTraceContext{traceID: "trace-1", spanID: "span-1"}
+		resolver := &tracingResolver{SimpleCapabilityResolver: zcapld.SimpleCapabilityResolver{root.ID: root}}
+
+		invocation, err := zcapld.NewCapabilityInvocationBuilder().
+			SetExpectedTarget(root.ID).
+			SetExpectedRootCapability(root.ID).
+			SetExpectedAction("read").
+			SetVerificationMethod(&zcapld.VerificationMethod{ID: "did:example:123#key-1"}).
+			WithTraceContext(tc).
+			Build()
+		require.NoError(t, err)
+		require.Equal(t, tc, invocation.TraceContext)
+
+		err = zcapld.VerifyCapabilityChain(context.Background(), resolver, child, "read", invocation)
+		require.NoError(t, err)
+		require.NotEmpty(t, resolver.traces)
+
+		for _, trace := range resolver.traces {
+			require.Equal(t, tc, trace)
+		}
+	})
+
+	t.Run("no trace context", func(t *testing.T) {
+		resolver := &tracingResolver{SimpleCapabilityResolver: zcapld.SimpleCapabilityResolver{root.ID: root}}
+
+		err := zcapld.VerifyCapabilityChain(context.Background(), resolver, child, "read",
+			&zcapld.CapabilityInvocation{ExpectedRootCapability: root.ID, ExpectedTarget: root.ID, ExpectedAction: "read"})
+		require.NoError(t, err)
+		require.NotEmpty(t, resolver.traces)
+		require.Nil(t, resolver.traces[0])
+	})
+
+	t.Run("from context", func(t *testing.T) {
+		_, ok := zcapld.TraceContextFromContext(context.Background())
+		require.False(t, ok)
+
+		tc := &testTraceContext{traceID: "trace-1", spanID: "span-1"}
+
+		result, ok := zcapld.TraceContextFromContext(zcapld.ContextWithTraceContext(context.Background(), tc))
+		require.True(t, ok)
+		require.Equal(t, "trace-1", result.TraceID())
+		require.Equal(t, "span-1", result.SpanID())
+	})
+}
@@ -0,0 +1,109 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package validate checks that documents are well-formed ZCAP-LD capabilities, without resolving any URI.
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld/zcapcontext"
+)
+
+const capabilityType = "Capability"
+
+// Validate checks that the document claims to be a ZCAP-LD capability: its @context includes the security v2 or the
+// ZCAP-LD v1 context, its type (if any, since capabilities serialized by the zcapld package have none) includes
+// "Capability", its id is an absolute URI, and its invocationTarget has an id. Neither the capability chain nor the
+// proofs are verified, so a document passing validation may still fail verification.
+func Validate(doc []byte) error {
+	capability := make(map[string]interface{})
+
+	err := json.Unmarshal(doc, &capability)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal capability: %w", err)
+	}
+
+	if !zcapcontext.HasContext(capability, zcapcontext.SecurityV2ContextURI) &&
+		!zcapcontext.HasContext(capability, zcapcontext.ZcapLDV1ContextURI) {
+		return fmt.Errorf("@context must include %s or %s",
+			zcapcontext.SecurityV2ContextURI, zcapcontext.ZcapLDV1ContextURI)
+	}
+
+	err = validateType(capability)
+	if err != nil {
+		return err
+	}
+
+	id, _ := capability["id"].(string) // nolint:errcheck // checked below
+
+	u, err := url.Parse(id)
+	if err != nil || !u.IsAbs() {
+		return fmt.Errorf("capability id is not an absolute URI: %v", capability["id"])
+	}
+
+	if invocationTargetID(capability["invocationTarget"]) == "" {
+		return fmt.Errorf("capability %s has no invocationTarget id", id)
+	}
+
+	return nil
+}
+
+// validateType ensures the "type" or "@type" of the capability, if any, includes the capability type.
+func validateType(capability map[string]interface{}) error {
+	for _, key := range []string{"@type", "type"} {
+		value, ok := capability[key]
+		if !ok {
+			continue
+		}
+
+		var types []interface{}
+
+		switch v := value.(type) {
+		case string:
+			types = []interface{}{v}
+		case []interface{}:
+			types = v
+		default:
+			return fmt.Errorf("invalid %s format: %v", key, value)
+		}
+
+		for _, t := range types {
+			if s, ok := t.(string); ok && isCapabilityType(s) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%s does not include %s: %v", key, capabilityType, value)
+	}
+
+	return nil
+}
+
+// isCapabilityType reports whether the type is the capability type, either as a term or as a (compact) IRI.
+func isCapabilityType(t string) bool {
+	return t == capabilityType || strings.HasSuffix(t, ":"+capabilityType) || strings.HasSuffix(t, "#"+capabilityType)
+}
+
+// invocationTargetID returns the id of the invocation target, which is either a URI or an object with an id.
+// The id key is matched case-insensitively, like the zcapld package does when decoding capabilities.
+func invocationTargetID(target interface{}) string {
+	switch v := target.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		for key, value := range v {
+			if id, ok := value.(string); ok && strings.EqualFold(key, "id") {
+				return id
+			}
+		}
+	}
+
+	return ""
+}
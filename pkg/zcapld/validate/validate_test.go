@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package validate_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+	"github.com/trustbloc/edge-core/pkg/zcapld/validate"
+	"github.com/trustbloc/edge-core/pkg/zcapld/zcapcontext"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("success: capability serialized by zcapld", func(t *testing.T) {
+		raw, err := json.Marshal(&zcapld.Capability{
+			Context:          zcapld.SecurityContextV2,
+			ID:               "urn:zcap:123",
+			InvocationTarget: zcapld.InvocationTarget{ID: "urn:target"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, validate.Validate(raw))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		tests := []struct {
+			name string
+			doc  string
+		}{
+			{
+				name: "zcap context and type",
+				doc: `{"@context":["` + zcapcontext.ZcapLDV1ContextURI + `"],"type":"Capability",` +
+					`"id":"https://example.com/zcaps/123","invocationTarget":"https://example.com/documents/1"}`,
+			},
+			{
+				name: "compact type in array",
+				doc: `{"@context":"` + zcapcontext.SecurityV2ContextURI + `","@type":["Other","sec:Capability"],` +
+					`"id":"urn:zcap:123","invocationTarget":{"id":"urn:target","type":"urn:edv:document"}}`,
+			},
+		}
+
+		for i := range tests {
+			tc := tests[i]
+
+			t.Run(tc.name, func(t *testing.T) {
+				require.NoError(t, validate.Validate([]byte(tc.doc)))
+			})
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		const ctx = `"@context":"` + zcapcontext.SecurityV2ContextURI + `"`
+
+		tests := []struct {
+			name string
+			doc  string
+			err  string
+		}{
+			{
+				name: "not JSON",
+				doc:  `{`,
+				err:  "failed to unmarshal capability",
+			},
+			{
+				name: "missing context",
+				doc:  `{"id":"urn:zcap:123","invocationTarget":"urn:target"}`,
+				err:  "@context must include https://w3id.org/security/v2 or https://w3id.org/zcap/v1",
+			},
+			{
+				name: "other context",
+				doc:  `{"@context":"https://www.w3.org/2018/credentials/v1","id":"urn:zcap:123"}`,
+				err:  "@context must include",
+			},
+			{
+				name: "not a capability type",
+				doc:  `{` + ctx + `,"type":["VerifiableCredential"],"id":"urn:zcap:123","invocationTarget":"urn:t"}`,
+				err:  "type does not include Capability: [VerifiableCredential]",
+			},
+			{
+				name: "invalid type format",
+				doc:  `{` + ctx + `,"@type":1,"id":"urn:zcap:123","invocationTarget":"urn:t"}`,
+				err:  "invalid @type format: 1",
+			},
+			{
+				name: "missing id",
+				doc:  `{` + ctx + `,"invocationTarget":"urn:target"}`,
+				err:  "capability id is not an absolute URI: <nil>",
+			},
+			{
+				name: "relative id",
+				doc:  `{` + ctx + `,"id":"zcaps/123","invocationTarget":"urn:target"}`,
+				err:  "capability id is not an absolute URI: zcaps/123",
+			},
+			{
+				name: "missing invocation target",
+				doc:  `{` + ctx + `,"id":"urn:zcap:123"}`,
+				err:  "capability urn:zcap:123 has no invocationTarget id",
+			},
+			{
+				name: "invocation target without an id",
+				doc:  `{` + ctx + `,"id":"urn:zcap:123","invocationTarget":{"type":"urn:edv:document"}}`,
+				err:  "capability urn:zcap:123 has no invocationTarget id",
+			},
+		}
+
+		for i := range tests {
+			tc := tests[i]
+
+			t.Run(tc.name, func(t *testing.T) {
+				err := validate.Validate([]byte(tc.doc))
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+			})
+		}
+	})
+}
@@ -7,21 +7,80 @@ SPDX-License-Identifier: Apache-2.0
 package zcapld
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
 	"github.com/piprate/json-gold/ld"
+
+	"github.com/trustbloc/edge-core/pkg/internal/logging/structured"
+	"github.com/trustbloc/edge-core/pkg/log"
 )
 
+const loggerModule = "edge-core-zcapld"
+
+var logger = log.New(loggerModule)
+
+// Logger is the structured logger the Verifier writes its diagnostic logs to.
+type Logger = structured.Logger
+
+const defaultMaxChainDepth = 10
+
 // Verifier verifies zcaps.
 type Verifier struct {
-	zcaps      CapabilityResolver
-	keys       KeyResolver
-	verifier   *verifier.DocumentVerifier
-	ldProcOpts []jsonld.ProcessorOpts
+	zcaps       CapabilityResolver
+	keys        KeyResolver
+	verifier    *verifier.DocumentVerifier
+	ldProcOpts  []jsonld.ProcessorOpts
+	clock       func() time.Time
+	caveats     CaveatRegistry
+	maxAge      time.Duration
+	controllers ControllerResolver
+	metrics     VerifierMetrics
+	revocations RevocationChecker
+	delegations DelegationProofVerifier
+	purposes    ProofPurposeRegistry
+	nonces      NonceChecker
+	auditor     Auditor
+	// diagnostics logs the steps of the verifications, if set.
+	diagnostics Logger
+	// maxConcurrency limits the number of requests verified at once by VerifyBatch, if positive.
+	maxConcurrency int
+	// maxChainDepth limits the length of capability chains, if positive.
+	maxChainDepth int
+	// allowUnknownCaveatTypes skips caveats of types not found in the registry.
+	allowUnknownCaveatTypes bool
+	// caseInsensitiveActions compares actions with strings.EqualFold.
+	caseInsensitiveActions bool
+	// allowedIDSchemes are the URI schemes allowed for capability IDs, if any.
+	allowedIDSchemes []string
+	// policy evaluates the invocations once verified, if set.
+	policy PolicyEngine
+	// targetTypes validate the invocation target of the root capability, by type.
+	targetTypes InvocationTargetTypeRegistry
+	// options are those the verifier was configured with, overlaid by Clone.
+	options VerificationOptions
+}
+
+// DelegationProofVerifier verifies the delegation proof of a capability delegated from its parent capability.
+type DelegationProofVerifier interface {
+	Verify(parentCapability, childCapability *Capability) error
+}
+
+// ldDelegationProofVerifier verifies the linked data proof on the delegated capability.
+type ldDelegationProofVerifier struct {
+	v *Verifier
+}
+
+func (l *ldDelegationProofVerifier) Verify(_, child *Capability) error {
+	return l.v.verifyProof(child)
 }
 
 // Proof describes the capability, the action, and the verification method of an invocation.
@@ -29,12 +88,46 @@ type Proof struct {
 	Capability         *Capability
 	CapabilityAction   string
 	VerificationMethod string
+	Created            time.Time
+	Nonce              string
+	// Purpose is the proof purpose stated by the proof. Defaults to "capabilityInvocation".
+	Purpose string
+	// Challenge is the challenge issued to the invoker, to be checked with VerifyChallenge.
+	Challenge string
 }
 
 // VerificationOptions holds options for the Verifier.
 type VerificationOptions struct {
-	LDProcessorOptions []jsonld.ProcessorOpts
-	SignatureSuites    []verifier.SignatureSuite
+	LDProcessorOptions      []jsonld.ProcessorOpts
+	SignatureSuites         []verifier.SignatureSuite
+	Clock                   func() time.Time
+	Caveats                 CaveatRegistry
+	MaxProofAge             time.Duration
+	ControllerResolver      ControllerResolver
+	Metrics                 VerifierMetrics
+	RevocationChecker       RevocationChecker
+	DelegationProofs        DelegationProofVerifier
+	AllowUnknownCaveatTypes bool
+	ProofPurposes           ProofPurposeRegistry
+	NonceChecker            NonceChecker
+	MaxConcurrency          int
+	MaxChainDepth           int
+	InvokerResolver         InvokerResolver
+	Auditor                 Auditor
+	// MaxInvocationsCounter counts the invocations of capabilities with a MaxInvocationsCaveat.
+	MaxInvocationsCounter MaxInvocationsCounter
+	// CaseInsensitiveActions compares the invoked action with the allowed and expected actions case-insensitively.
+	CaseInsensitiveActions bool
+	// Logger receives the diagnostic logs of the verifications.
+	Logger Logger
+	// AttestationVerifiers verify the AttestationCaveats of their attestation types.
+	AttestationVerifiers AttestationVerifiers
+	// AllowedIDSchemes are the URI schemes allowed for the IDs of the capabilities in the chain. Empty allows any.
+	AllowedIDSchemes []string
+	// PolicyEngine evaluates the invocations once verified.
+	PolicyEngine PolicyEngine
+	// TargetTypes validate the invocation target of the root capability, by type.
+	TargetTypes InvocationTargetTypeRegistry
 }
 
 // VerificationOption sets an option for the Verifier.
@@ -56,207 +149,1361 @@ func WithLDDocumentLoaders(loaders ...ld.DocumentLoader) VerificationOption {
 	}
 }
 
+// WithClock sets the clock used by the Verifier to check the expiry of capabilities. Defaults to time.Now.
+func WithClock(clock func() time.Time) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.Clock = clock
+	}
+}
+
+// WithCaveats sets the caveats supported by the Verifier.
+// Capabilities with caveats not found in the registry fail verification.
+func WithCaveats(registry CaveatRegistry) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.Caveats = registry
+	}
+}
+
+// WithMaxProofAge sets the maximum age of proofs accepted by the Verifier. Proofs without a created time
+// are not checked.
+func WithMaxProofAge(maxAge time.Duration) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.MaxProofAge = maxAge
+	}
+}
+
+// WithControllerResolver sets the resolver used to verify the verification method is authorized by its controller
+// for the purpose of capabilityInvocation.
+func WithControllerResolver(r ControllerResolver) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.ControllerResolver = r
+	}
+}
+
+// WithMetrics sets the VerifierMetrics used to record metrics on verifications. Defaults to NoopMetrics.
+func WithMetrics(m VerifierMetrics) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.Metrics = m
+	}
+}
+
+// WithRevocationChecker sets the RevocationChecker used to ensure no capability in the chain has been revoked.
+func WithRevocationChecker(r RevocationChecker) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.RevocationChecker = r
+	}
+}
+
+// WithDelegationProofVerifier sets the DelegationProofVerifier used to verify the delegation proofs of the
+// delegated capabilities in the chain. Defaults to verifying their linked data proofs with the Verifier's
+// signature suites and key resolver.
+func WithDelegationProofVerifier(d DelegationProofVerifier) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.DelegationProofs = d
+	}
+}
+
+// WithAllowUnknownCaveatTypes sets whether caveats of types not supported by the Verifier are skipped. When true,
+// such caveats are ignored with a warning: this fails open, since the capability may then be invoked beyond the
+// restrictions they express. The caveats of supported types are still verified. Defaults to false, failing
+// verification instead.
+func WithAllowUnknownCaveatTypes(allow bool) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.AllowUnknownCaveatTypes = allow
+	}
+}
+
+// WithProofPurposeRegistry sets the proof purposes supported by the Verifier. Invocation proofs are verified with
+// the proof purpose registered as "capabilityInvocation". Defaults to DefaultProofPurposeRegistry.
+func WithProofPurposeRegistry(registry ProofPurposeRegistry) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.ProofPurposes = registry
+	}
+}
+
+// WithNonceChecker sets the NonceChecker used to prevent the replay of invocation proofs. Proofs without a nonce
+// are not checked.
+func WithNonceChecker(n NonceChecker) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.NonceChecker = n
+	}
+}
+
+// WithMaxConcurrency sets the maximum number of requests verified at once by VerifyBatch.
+// Defaults to 0, verifying all requests at once.
+func WithMaxConcurrency(n int) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.MaxConcurrency = n
+	}
+}
+
+// WithMaxChainDepth sets the maximum length of the capability chain of invoked capabilities. Longer chains are
+// rejected with an error wrapping ErrChainTooDeep before any capability in the chain is resolved.
+// Defaults to 10. A value of 0 or less removes the limit.
+func WithMaxChainDepth(n int) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.MaxChainDepth = n
+	}
+}
+
+// WithInvokerResolver sets how the Verifier decides whether the verification method of an invocation is an
+// authorized invoker of the capability, replacing IsInvoker. It registers a CapabilityInvocationPurpose using it
+// in the proof purpose registry, replacing any other capabilityInvocation proof purpose.
+func WithInvokerResolver(fn InvokerResolver) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.InvokerResolver = fn
+	}
+}
+
+// WithCaseInsensitiveActions makes the Verifier compare the invoked action with the capability's allowed actions and
+// the expected action case-insensitively. Actions in caveats are still compared case-sensitively.
+func WithCaseInsensitiveActions() VerificationOption {
+	return func(o *VerificationOptions) {
+		o.CaseInsensitiveActions = true
+	}
+}
+
+// WithMaxInvocationsCounter sets the MaxInvocationsCounter used to count the invocations of capabilities with a
+// MaxInvocationsCaveat. It registers the MaxInvocationsCaveat in a copy of the caveat registry, which is otherwise
+// left unchanged.
+func WithMaxInvocationsCounter(c MaxInvocationsCounter) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.MaxInvocationsCounter = c
+	}
+}
+
+// WithLogger sets the Logger the Verifier writes diagnostic logs to, at DEBUG level, for each step of the
+// verification of the capability chain along with the ID and chain depth of the capability being checked.
+// Defaults to no diagnostic logs.
+func WithLogger(l Logger) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.Logger = l
+	}
+}
+
+// WithAttestationVerifier adds the AttestationVerifier of the attestation type to the Verifier, replacing any
+// verifier previously added for the type. It registers the AttestationCaveat in a copy of the caveat registry, which
+// is otherwise left unchanged.
+func WithAttestationVerifier(attestationType string, v AttestationVerifier) VerificationOption {
+	return func(o *VerificationOptions) {
+		if o.AttestationVerifiers == nil {
+			o.AttestationVerifiers = AttestationVerifiers{}
+		}
+
+		o.AttestationVerifiers[attestationType] = v
+	}
+}
+
+// WithAllowedIDSchemes restricts the IDs of the capabilities in the chain to URIs of the schemes, eg. "https",
+// "urn", and "did". Schemes are compared case-insensitively. Chains with other IDs are rejected with an error
+// wrapping ErrInvalidCapabilityID. Defaults to allowing any non-empty URI.
+func WithAllowedIDSchemes(schemes ...string) VerificationOption {
+	return func(o *VerificationOptions) {
+		o.AllowedIDSchemes = append(o.AllowedIDSchemes, schemes...)
+	}
+}
+
 // NewVerifier returns a new Verifier.
 func NewVerifier(
 	zcapResolver CapabilityResolver, keyResolver KeyResolver, options ...VerificationOption) (*Verifier, error) {
-	opts := &VerificationOptions{}
+	opts := &VerificationOptions{
+		Clock:         time.Now,
+		Metrics:       NoopMetrics{},
+		ProofPurposes: DefaultProofPurposeRegistry(),
+		MaxChainDepth: defaultMaxChainDepth,
+	}
 
 	for i := range options {
 		options[i](opts)
 	}
 
-	v, err := verifier.New(keyResolver, opts.SignatureSuites...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to init document verifier: %w", err)
+	return newVerifier(zcapResolver, keyResolver, opts, nil)
+}
+
+// Clone returns a copy of the verifier configured with its options overlaid by the given options. The verifier is
+// left unchanged, eg. for per-request options like the nonce checker.
+func (v *Verifier) Clone(options ...VerificationOption) (*Verifier, error) {
+	opts := v.options.copy()
+
+	for i := range options {
+		options[i](opts)
 	}
 
-	return &Verifier{
-		zcaps:      zcapResolver,
-		keys:       keyResolver,
-		verifier:   v,
-		ldProcOpts: opts.LDProcessorOptions,
-	}, nil
+	var docVerifier *verifier.DocumentVerifier
+
+	// the signature suites are only ever appended to
+	if len(opts.SignatureSuites) == len(v.options.SignatureSuites) {
+		docVerifier = v.verifier
+	}
+
+	return newVerifier(v.zcaps, v.keys, opts, docVerifier)
 }
 
-// Verify the proof against the invocation.
-func (v *Verifier) Verify(proof *Proof, invocation *CapabilityInvocation) error {
-	if proof.Capability == nil {
-		return errors.New(`"capability" was not found in the capability invocation proof`)
+// copy returns a copy of the options that shares no slice or map that the options mutate in place.
+func (o *VerificationOptions) copy() *VerificationOptions {
+	c := *o
+
+	c.LDProcessorOptions = append([]jsonld.ProcessorOpts(nil), o.LDProcessorOptions...)
+	c.SignatureSuites = append([]verifier.SignatureSuite(nil), o.SignatureSuites...)
+	c.AllowedIDSchemes = append([]string(nil), o.AllowedIDSchemes...)
+
+	if o.AttestationVerifiers != nil {
+		c.AttestationVerifiers = make(AttestationVerifiers, len(o.AttestationVerifiers))
+
+		for attestationType, v := range o.AttestationVerifiers {
+			c.AttestationVerifiers[attestationType] = v
+		}
 	}
 
-	// 1. get the capability in the security v2 context
-	// **We have already resolved and parsed the full capability**
+	return &c
+}
 
-	// 2. verify the capability delegation chain
-	err := v.verifyCapabilityChain(proof.Capability, proof.CapabilityAction, invocation)
+// newVerifier returns a new Verifier configured with the options. The document verifier is created from the
+// signature suites unless given.
+func newVerifier(zcapResolver CapabilityResolver, keyResolver KeyResolver, options *VerificationOptions,
+	docVerifier *verifier.DocumentVerifier) (*Verifier, error) {
+	opts := options.copy()
+
+	if opts.InvokerResolver != nil {
+		purposes := NewProofPurposeRegistry(&CapabilityInvocationPurpose{IsInvoker: opts.InvokerResolver})
+
+		for name, purpose := range opts.ProofPurposes {
+			if name != ProofPurposeCapabilityInvocation {
+				purposes[name] = purpose
+			}
+		}
+
+		opts.ProofPurposes = purposes
+	}
+
+	if opts.MaxInvocationsCounter != nil {
+		opts.Caveats = opts.Caveats.with(CaveatTypeMaxInvocations, func() Caveat {
+			return &MaxInvocationsCaveat{counter: opts.MaxInvocationsCounter}
+		})
+	}
+
+	if opts.AttestationVerifiers != nil {
+		opts.Caveats = opts.Caveats.with(CaveatTypeAttestation, newAttestationCaveat(opts.AttestationVerifiers))
+	}
+
+	if docVerifier == nil {
+		v, err := verifier.New(keyResolver, opts.SignatureSuites...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init document verifier: %w", err)
+		}
+
+		docVerifier = v
+	}
+
+	zv := &Verifier{
+		zcaps:       zcapResolver,
+		keys:        keyResolver,
+		verifier:    docVerifier,
+		ldProcOpts:  opts.LDProcessorOptions,
+		clock:       opts.Clock,
+		caveats:     opts.Caveats,
+		maxAge:      opts.MaxProofAge,
+		controllers: opts.ControllerResolver,
+		metrics:     opts.Metrics,
+		revocations: opts.RevocationChecker,
+		delegations: opts.DelegationProofs,
+		purposes:    opts.ProofPurposes,
+		nonces:      opts.NonceChecker,
+		auditor:     opts.Auditor,
+		diagnostics: opts.Logger,
+
+		maxConcurrency:          opts.MaxConcurrency,
+		maxChainDepth:           opts.MaxChainDepth,
+		allowUnknownCaveatTypes: opts.AllowUnknownCaveatTypes,
+		caseInsensitiveActions:  opts.CaseInsensitiveActions,
+		allowedIDSchemes:        opts.AllowedIDSchemes,
+		policy:                  opts.PolicyEngine,
+		targetTypes:             opts.TargetTypes,
+		options:                 *options,
+	}
+
+	if zv.delegations == nil {
+		zv.delegations = &ldDelegationProofVerifier{v: zv}
+	}
+
+	return zv, nil
+}
+
+// LinkResult is the result of verifying a single capability in a capability chain.
+type LinkResult struct {
+	// CapabilityID is the ID of the capability.
+	CapabilityID string
+	// Depth is the position of the capability in the chain, starting at 0 with the root capability.
+	Depth int
+	// Err is the first error found verifying the capability, or nil if it was verified successfully.
+	Err error
+}
+
+// Verify the proof against the invocation.
+func (v *Verifier) Verify(ctx context.Context, proof *Proof, invocation *CapabilityInvocation) error {
+	_, err := v.verify(ctx, proof, invocation, true)
+
+	return err
+}
+
+// VerifyChain verifies the proof against the invocation like Verify does, but does not stop at the first error.
+// It returns the result of verifying each capability in the chain, ordered from the root capability to the
+// capability being invoked, along with the first error encountered. The results are nil if the chain itself
+// could not be determined.
+func (v *Verifier) VerifyChain(
+	ctx context.Context, proof *Proof, invocation *CapabilityInvocation) ([]LinkResult, error) {
+	return v.verify(ctx, proof, invocation, false)
+}
+
+// VerifyCapabilityChain verifies the delegation chain of the capability for the intended action, resolving the
+// capabilities in the chain with the resolver. The built-in caveats are supported.
+// Unlike Verifier.Verify, it neither verifies the invoker of the capability nor any of the proofs in the chain.
+func VerifyCapabilityChain(ctx context.Context, resolver CapabilityResolver, capability *Capability,
+	intendedAction string, invocation *CapabilityInvocation) error {
+	v := &Verifier{
+		zcaps:         resolver,
+		clock:         time.Now,
+		caveats:       DefaultCaveatRegistry(),
+		maxChainDepth: defaultMaxChainDepth,
+	}
+
+	w, err := v.newChainWalk(withInvocationTrace(ctx, invocation), capability, invocation, true)
 	if err != nil {
 		return fmt.Errorf("invalid capability chain: %w", err)
 	}
 
-	// 3. verify the invoker...
-	// authorized invoker must match the verification method itself OR
-	// the controller of the verification method
-	isInvoker, err := isInvoker(proof.Capability, invocation.VerificationMethod)
+	w.verifyCapabilityChain(intendedAction)
+
+	return w.err
+}
+
+func (v *Verifier) verify(
+	ctx context.Context, proof *Proof, invocation *CapabilityInvocation, failFast bool) ([]LinkResult, error) {
+	start := time.Now()
+
+	ctx = withInvocationTrace(ctx, invocation)
+
+	links, reason, err := v.verifyInvocation(ctx, proof, invocation, failFast)
+
+	v.metrics.RecordVerifyDuration(time.Since(start))
+
+	if links != nil {
+		v.metrics.RecordChainDepth(len(links) - 1)
+	}
+
 	if err != nil {
-		return fmt.Errorf("isInvoke: %w", err)
+		v.metrics.RecordVerifyError(reason)
+	}
+
+	v.audit(ctx, proof, invocation, err)
+
+	return links, err
+}
+
+// verifyInvocation returns the results of the chain walk along with the reason for the first error, if any.
+func (v *Verifier) verifyInvocation(ctx context.Context,
+	proof *Proof, invocation *CapabilityInvocation, failFast bool) ([]LinkResult, string, error) {
+	if v.nonces != nil && proof.Nonce != "" {
+		err := v.nonces.CheckAndStore(proof.Nonce)
+		if err != nil {
+			return nil, reasonNonce, fmt.Errorf("failed to check nonce: %w", err)
+		}
 	}
 
-	if !isInvoker {
-		return errors.New("the authorized invoker does not match the verification method or its controller")
+	if proof.Capability == nil {
+		return nil, reasonMissingCapability,
+			errors.New(`"capability" was not found in the capability invocation proof`)
 	}
 
-	// Begin ControllerProofPurpose
+	purpose, err := proofPurpose(proof, invocation)
+	if err != nil {
+		return nil, reasonProofPurpose, err
+	}
 
-	// TODO the code here validates the proof's "created" time against an expected time:
+	// validate the proof's "created" time against the maximum allowed age:
 	//  nolint:lll // don't want to break the link in two
 	//  https://github.com/digitalbazaar/jsonld-signatures/blob/8d91bcb351702dde4863fab660d7ca1e5e90b2a2/lib/purposes/ProofPurpose.js#L49-L57.
-	//  Note: the higher layers in bedrock-edv-storage don't actually set `proof.created`, so `created` is always NaN.
-	//  Do we really need to verify the proof's date at this layer though? Isn't that the responsibility of a higher
-	//  layer, ie the one that parses and verifies the http signature?
+	err = v.verifyProofAge(proof)
+	if err != nil {
+		return nil, reasonProofAge, err
+	}
 
-	// TODO verify authorization of verificationMethod.ID by controller for proof purpose `capabilityInvocation`.
-	//  Controller are probably DIDs. They have a "capabilityInvocation" property (just like DIDs) that has
-	//  verificationMethod IDs.
+	// 1. get the capability in the security v2 context
+	// **We have already resolved and parsed the full capability**
 
-	err = v.verifyProof(proof.Capability)
+	// 2. verify the capability delegation chain
+	w, err := v.newChainWalk(ctx, proof.Capability, invocation, failFast)
 	if err != nil {
-		return fmt.Errorf("failed to verify proof: %w", err)
+		return nil, reasonCapabilityChain, fmt.Errorf("invalid capability chain: %w", err)
 	}
 
-	return nil
-}
+	w.verifyCapabilityChain(proof.CapabilityAction)
 
-// nolint:funlen,gocyclo // TODO decompose verifyCapabilityChain into smaller units
-func (v *Verifier) verifyCapabilityChain(
-	capability *Capability, intendedAction string, invocation *CapabilityInvocation) error {
-	// 1.1. Ensure `capabilityAction`, if given, is allowed; if the capability
-	// restricts the actions via `allowedAction` then it must be in the set.
-	if len(capability.AllowedAction) > 0 && intendedAction != "" &&
-		!stringsContain(capability.AllowedAction, intendedAction) {
-		return fmt.Errorf(
-			`capability action "%s" is not allowed by the capability; allowed actions are: %+v`,
-			intendedAction, capability.AllowedAction)
+	leaf := len(w.links) - 1
+
+	// 3. verify the proof purpose; for capabilityInvocation, the invoker...
+	// authorized invoker must match the verification method itself OR
+	// the controller of the verification method
+	w.check(leaf, reasonInvoker, func() error {
+		w.debug("checking invoker of invoked capability", leaf, "verificationMethod", proof.VerificationMethod)
+
+		return v.purposes.verify(purpose, proof, invocation)
+	})
+
+	// Begin ControllerProofPurpose
+
+	// verify authorization of verificationMethod.ID by controller for proof purpose `capabilityInvocation`.
+	w.check(leaf, reasonController, func() error {
+		err := v.verifyController(invocation.VerificationMethod)
+		if err != nil {
+			return fmt.Errorf("failed to verify controller: %w", err)
+		}
+
+		return nil
+	})
+
+	w.check(leaf, reasonProof, func() error {
+		var err error
+
+		switch {
+		case leaf == 0:
+			err = v.verifyProof(proof.Capability)
+		case w.parent != nil:
+			err = v.delegations.Verify(w.parent, proof.Capability)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to verify proof: %w", err)
+		}
+
+		return nil
+	})
+
+	// the policy only evaluates invocations verified successfully
+	if w.err == nil {
+		w.check(leaf, reasonPolicy, func() error {
+			return v.evaluatePolicy(ctx, proof.Capability, invocation)
+		})
 	}
 
-	if invocation.ExpectedAction != intendedAction {
-		return fmt.Errorf(
-			`capability action "%s" does not match the expected capability action of "%s"`,
-			intendedAction, invocation.ExpectedAction)
+	// only invocations verified successfully are counted against the MaxInvocationsCaveats of the chain
+	if w.err == nil {
+		w.countInvocations()
 	}
 
+	return w.links, w.reason, w.err
+}
+
+// chainWalk records the results of verifying each capability in a capability chain.
+type chainWalk struct {
+	ctx        context.Context
+	v          *Verifier
+	capability *Capability
+	invocation *CapabilityInvocation
+	links      []LinkResult
+	chain      []interface{}
+	// embedded are the capabilities embedded in the chain, by depth.
+	embedded map[int]*Capability
+	failFast bool
+	err      error
+	reason   string
+	// parent is the resolved parent of the capability being invoked, if any.
+	parent *Capability
+	// capabilities are the resolved capabilities of the chain, by depth.
+	capabilities []*Capability
+	// resolver resolves the capabilities of the chain, from those resolved in batch if the Verifier's resolver is a
+	// BatchCapabilityResolver.
+	resolver CapabilityResolver
+}
+
+func (v *Verifier) newChainWalk(ctx context.Context,
+	capability *Capability, invocation *CapabilityInvocation, failFast bool) (*chainWalk, error) {
 	// 3. Validate the capability delegation chain.
 	err := capability.validateCapabilityChain()
 	if err != nil {
-		return fmt.Errorf("invalid capability chain: %w", err)
+		return nil, fmt.Errorf("invalid capability chain: %w", err)
 	}
 
 	// 2. Get the capability delegation chain for the capability.
-	capabilityChain, err := capability.capabilityChain()
+	chain, err := capability.capabilityChain()
 	if err != nil {
-		return fmt.Errorf("failed to fetch capabilityChain: %w", err)
+		return nil, fmt.Errorf("failed to fetch capabilityChain: %w", err)
 	}
 
-	// 4. Verify root capability (note: it must *always* be dereferenced since
-	// it does not need to have a delegation proof to vouch for its authenticity
-	// ... dereferencing it prevents adversaries from submitting an invalid
-	// root capability that is accepted):
-	isRoot := len(capabilityChain) == 0
+	if v.maxChainDepth > 0 && len(chain) > v.maxChainDepth {
+		return nil, fmt.Errorf("%w: capability %s has a chain of %d capabilities; the maximum is %d",
+			ErrChainTooDeep, capability.ID, len(chain), v.maxChainDepth)
+	}
 
-	var rootURI string
+	links := make([]LinkResult, 0, len(chain)+1)
 
-	if isRoot {
-		rootURI = capability.ID
-	} else {
-		untyped := capabilityChain[0]
-		capabilityChain = capabilityChain[1:]
+	embedded := make(map[int]*Capability)
 
-		var ok bool
+	for i := range chain {
+		uri, ok := chain[i].(string)
+
+		if m, isMap := chain[i].(map[string]interface{}); isMap {
+			embedded[i], err = embeddedCapability(m)
+			if err != nil {
+				return nil, err
+			}
+
+			uri, ok = embedded[i].ID, true
+		}
 
-		rootURI, ok = untyped.(string)
 		if !ok {
-			return fmt.Errorf("invalid rootURI format: %v", untyped)
+			return nil, fmt.Errorf("invalid capability URI format: %v", chain[i])
 		}
-	}
 
-	root, err := v.zcaps.Resolve(rootURI)
-	if err != nil {
-		return fmt.Errorf("failed to resolve root capability URI %s: %w", rootURI, err)
+		links = append(links, LinkResult{CapabilityID: uri, Depth: i})
 	}
 
-	// 4.1. Check the expected target, if one was specified.
-	// TODO revisit the datatypes assumed of the invocationTarget.ID in this algo:
-	//  https://github.com/digitalbazaar/ocapld.js/blob/8a54398162837b1cf52c82978bc8127e52d02974/lib/utils.js#L115
-	if invocation.ExpectedTarget != "" && invocation.ExpectedTarget != root.InvocationTarget.ID {
-		return fmt.Errorf(
-			`expected target does not match root capability target: expected="%s" target="%s"`,
-			invocation.ExpectedTarget, root.InvocationTarget.ID)
-	}
+	// a capability without a chain is its own root
+	links = append(links, LinkResult{CapabilityID: capability.ID, Depth: len(chain)})
 
-	// 4.2. Ensure that the caveats are met on the root capability.
-	// TODO verify caveats
+	for i := range links {
+		err = v.validateCapabilityID(links[i].CapabilityID)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	// TODO verify expiry on root capability
+	return &chainWalk{
+		ctx:          ctx,
+		v:            v,
+		capability:   capability,
+		invocation:   invocation,
+		links:        links,
+		chain:        chain,
+		embedded:     embedded,
+		failFast:     failFast,
+		resolver:     v.zcaps,
+		capabilities: make([]*Capability, len(links)),
+	}, nil
+}
 
-	// 4.3. Ensure root capability is expected and has no invocation target.
-	if invocation.ExpectedRootCapability != "" && invocation.ExpectedRootCapability != root.ID {
-		return fmt.Errorf(
-			"expected root capability does not match actual root capability: expected=(%s) actual=(%s)",
-			invocation.ExpectedRootCapability, root.ID)
+// validateCapabilityID ensures the capability ID is a URI of one of the allowed schemes, if any.
+func (v *Verifier) validateCapabilityID(id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: empty ID", ErrInvalidCapabilityID)
 	}
 
-	// TODO weird error condition
-	if invocation.ExpectedRootCapability == "" && root.InvocationTarget.ID != root.ID {
-		return errors.New("the root capability must not specify a different invocation target")
+	u, err := url.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: %q is not a URI: %s", ErrInvalidCapabilityID, id, err)
 	}
 
-	if isRoot {
+	if len(v.allowedIDSchemes) == 0 {
 		return nil
 	}
 
-	// TODO add support. First figure out why capabilityChain is an array.
-	if len(capabilityChain) > 0 {
-		return errors.New("multiple capabilityChains not supported yet")
+	for _, scheme := range v.allowedIDSchemes {
+		if u.Scheme != "" && strings.EqualFold(u.Scheme, scheme) {
+			return nil
+		}
 	}
 
-	return nil
+	return fmt.Errorf("%w: the scheme of %q is not one of the allowed schemes: %+v",
+		ErrInvalidCapabilityID, id, v.allowedIDSchemes)
 }
 
-func (v *Verifier) verifyProof(capability *Capability) error {
-	bits, err := json.Marshal(capability)
-	if err != nil {
-		return fmt.Errorf("failed to marshal capability: %w", err)
+// check runs 'verify' and records its error against the capability at 'depth'. Once an error is recorded,
+// subsequent checks are skipped if the walk stops at the first error.
+func (w *chainWalk) check(depth int, reason string, verify func() error) {
+	if w.failFast && w.err != nil {
+		return
 	}
 
-	err = v.verifier.Verify(bits, v.ldProcOpts...)
-	if err != nil {
-		return fmt.Errorf("document verifier error: %w", err)
+	err := verify()
+	if err == nil {
+		return
 	}
 
-	return nil
-}
+	w.debug("capability verification failed", depth, "reason", reason, "error", err.Error())
 
-func stringsContain(strs []string, s string) bool {
-	for i := range strs {
-		if s == strs[i] {
-			return true
-		}
+	if w.links[depth].Err == nil {
+		w.links[depth].Err = err
 	}
 
-	return false
+	if w.err == nil {
+		w.err = err
+		w.reason = reason
+	}
 }
 
-func isInvoker(capability *Capability, verificationMethod *VerificationMethod) (bool, error) {
-	invokers, err := capability.invokers()
-	if err != nil {
-		return false, fmt.Errorf("failed to fetch invokers: %w", err)
+// debug logs the step of the walk for the capability at 'depth' to the diagnostic logger, if any.
+func (w *chainWalk) debug(msg string, depth int, fields ...interface{}) {
+	if w.v.diagnostics == nil {
+		return
 	}
 
-	controller := verificationMethod.Controller
+	w.v.diagnostics.Debug(loggerModule, msg,
+		append([]interface{}{"capabilityID", w.links[depth].CapabilityID, "chainDepth", depth}, fields...)...)
+}
 
-	if len(invokers) > 0 {
-		return stringsContain(invokers, verificationMethod.ID) ||
-			(controller != "" && stringsContain(invokers, controller)), nil
-	}
+// checkChain is like check but marks the error as a capability chain error, wrapping it in a
+// ChainVerificationError for the capability at 'depth' with the reason.
+func (w *chainWalk) checkChain(depth int, reason string, verify func() error) {
+	w.check(depth, reasonCapabilityChain, func() error {
+		err := verify()
+		if err != nil {
+			return &ChainVerificationError{
+				CapabilityID: w.links[depth].CapabilityID,
+				ChainDepth:   depth,
+				Reason:       reason,
+				Cause:        err,
+			}
+		}
 
-	return false, nil
+		return nil
+	})
+}
+
+func (w *chainWalk) verifyCapabilityChain(intendedAction string) {
+	leaf := len(w.links) - 1
+
+	w.prefetch()
+
+	w.checkChain(leaf, ChainReasonInvokedCapability, func() error {
+		w.debug("checking action of invoked capability", leaf, "action", intendedAction)
+
+		return w.v.verifyInvokedCapability(w.capability, intendedAction, w.invocation)
+	})
+
+	// 4. Verify root capability (note: it must *always* be dereferenced since
+	// it does not need to have a delegation proof to vouch for its authenticity
+	// ... dereferencing it prevents adversaries from submitting an invalid
+	// root capability that is accepted):
+	var root *Capability
+
+	w.checkChain(0, ChainReasonRootCapability, func() error {
+		w.debug("resolving root capability", 0)
+
+		var err error
+
+		root, _, err = w.v.resolveRootCapability(w.ctx, w.resolver, w.capability, w.chain, w.invocation)
+		if err != nil {
+			return err
+		}
+
+		w.capabilities[0] = root
+
+		err = w.v.verifyRootCapability(root, w.invocation)
+		if err != nil {
+			return err
+		}
+
+		err = validateAllowedAction(root, intendedAction, w.v.caseInsensitiveActions)
+		if err != nil {
+			return err
+		}
+
+		return w.v.targetTypes.validate(root.InvocationTarget)
+	})
+
+	if leaf == 0 {
+		return
+	}
+
+	w.capabilities[leaf] = w.capability
+
+	// 5. Verify each delegated capability in the chain, ending with the capability being invoked.
+	w.verifyDelegationChain(root, intendedAction)
+}
+
+// verifyDelegationChain resolves and verifies the intermediate capabilities in the chain and ensures every delegated
+// capability (ending with the capability being invoked) was delegated by an authorized delegator of its parent,
+// starting with the root capability. The intended action must be allowed by every intermediate capability. A nil
+// parent is one that could not be resolved; its error has already been recorded.
+func (w *chainWalk) verifyDelegationChain(root *Capability, intendedAction string) {
+	leaf := len(w.links) - 1
+	parentID, parent := w.links[0].CapabilityID, root
+
+	for depth := 1; depth < leaf; depth++ {
+		uri := w.links[depth].CapabilityID
+
+		var link *Capability
+
+		w.checkChain(depth, ChainReasonDelegatedCapability, func() error {
+			w.debug("resolving delegated capability", depth)
+
+			var err error
+
+			link, err = w.resolve(depth)
+			if err != nil {
+				return err
+			}
+
+			w.capabilities[depth] = link
+
+			err = w.v.verifyDelegatedCapability(parentID, parent, link, w.invocation)
+			if err == nil {
+				err = validateAllowedAction(link, intendedAction, w.v.caseInsensitiveActions)
+			}
+
+			if err != nil {
+				return fmt.Errorf("invalid delegated capability %s: %w", uri, err)
+			}
+
+			return nil
+		})
+
+		parentID, parent = uri, link
+	}
+
+	w.parent = parent
+
+	w.checkChain(leaf, ChainReasonDelegation, func() error {
+		if parent == nil {
+			return nil
+		}
+
+		w.debug("checking delegator of invoked capability", leaf, "parentCapability", parent.ID)
+
+		return verifyDelegation(parent, w.capability)
+	})
+}
+
+// countInvocations counts the invocation against the MaxInvocationsCaveats of the capabilities in the chain, if the
+// Verifier supports them.
+func (w *chainWalk) countInvocations() {
+	newCaveat, ok := w.v.caveats[CaveatTypeMaxInvocations]
+	if !ok {
+		return
+	}
+
+	leaf := len(w.links) - 1
+
+	for depth, capability := range w.capabilities {
+		if capability == nil {
+			continue
+		}
+
+		reason := ChainReasonDelegatedCapability
+
+		switch depth {
+		case 0:
+			reason = ChainReasonRootCapability
+		case leaf:
+			reason = ChainReasonInvokedCapability
+		}
+
+		w.checkChain(depth, reason, func() error {
+			err := countInvocation(capability, newCaveat)
+			if err != nil {
+				return fmt.Errorf("failed to verify caveats: %w", err)
+			}
+
+			return nil
+		})
+	}
+}
+
+// prefetch resolves the root and intermediate capabilities of the chain at once if the Verifier's resolver is a
+// BatchCapabilityResolver and there are several of them. Their errors are reported when they are resolved.
+func (w *chainWalk) prefetch() {
+	batch, ok := w.v.zcaps.(BatchCapabilityResolver)
+	if !ok {
+		return
+	}
+
+	ids := []string{w.links[0].CapabilityID}
+
+	for depth := 1; depth < len(w.links)-1; depth++ {
+		if _, isEmbedded := w.embedded[depth]; !isEmbedded {
+			ids = append(ids, w.links[depth].CapabilityID)
+		}
+	}
+
+	if len(ids) < 2 {
+		return
+	}
+
+	zcaps, errs := batch.ResolveMulti(w.ctx, ids)
+	if len(zcaps) != len(ids) || len(errs) != len(ids) {
+		logger.Warnf("ignoring batch resolution of %d capabilities returning %d results and %d errors",
+			len(ids), len(zcaps), len(errs))
+
+		return
+	}
+
+	resolved := &batchResolution{resolver: batch, results: make(map[string]batchResult, len(ids))}
+
+	for i, id := range ids {
+		resolved.results[id] = batchResult{zcap: zcaps[i], err: errs[i]}
+	}
+
+	w.resolver = resolved
+}
+
+type batchResult struct {
+	zcap *Capability
+	err  error
+}
+
+// batchResolution resolves the capabilities resolved in a batch, and the others with the resolver.
+type batchResolution struct {
+	resolver CapabilityResolver
+	results  map[string]batchResult
+}
+
+func (b *batchResolution) Resolve(ctx context.Context, uri string) (*Capability, error) {
+	result, ok := b.results[uri]
+	if !ok {
+		return b.resolver.Resolve(ctx, uri)
+	}
+
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	if result.zcap == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCapabilityNotFound, uri)
+	}
+
+	return result.zcap, nil
+}
+
+// resolve the capability at the depth of the chain, unless it is embedded.
+func (w *chainWalk) resolve(depth int) (*Capability, error) {
+	uri := w.links[depth].CapabilityID
+
+	if link, ok := w.embedded[depth]; ok {
+		return link, nil
+	}
+
+	link, err := w.resolver.Resolve(w.ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve capability URI %s: %w", uri, err)
+	}
+
+	return link, nil
+}
+
+func (v *Verifier) verifyInvokedCapability(
+	capability *Capability, intendedAction string, invocation *CapabilityInvocation) error {
+	err := validateInvokedAction(capability, intendedAction, invocation.ExpectedAction, v.caseInsensitiveActions)
+	if err != nil {
+		return err
+	}
+
+	err = v.verifyNotRevoked(capability)
+	if err != nil {
+		return err
+	}
+
+	err = v.verifyNotExpired(capability)
+	if err != nil {
+		return err
+	}
+
+	err = verifyAudience(capability, invocation)
+	if err != nil {
+		return err
+	}
+
+	err = v.caveats.verify(capability, invocation, v.clock, v.allowUnknownCaveatTypes)
+	if err != nil {
+		return fmt.Errorf("failed to verify caveats: %w", err)
+	}
+
+	return nil
+}
+
+// validateInvokedAction ensures the intended action is allowed by the capability and matches the expected action.
+// Actions are compared case-insensitively if 'foldCase' is true.
+func validateInvokedAction(capability *Capability, intendedAction, expectedAction string, foldCase bool) error {
+	equal := func(a, b string) bool { return a == b }
+
+	if foldCase {
+		equal = strings.EqualFold
+	}
+
+	err := validateAllowedAction(capability, intendedAction, foldCase)
+	if err != nil {
+		return err
+	}
+
+	if !equal(expectedAction, intendedAction) {
+		return fmt.Errorf(
+			`%w: capability action "%s" does not match the expected capability action of "%s"`,
+			ErrActionNotAllowed, intendedAction, expectedAction)
+	}
+
+	return nil
+}
+
+// resolveRootCapability resolves the root capability of the capability with the given capability chain with the
+// resolver and checks its invocation target is the expected one. It returns the root capability along with the rest of the chain.
+// A capability with an empty chain is its own root. An embedded root capability is still dereferenced by its ID
+// and must be equal to the resolved one, since it has no delegation proof to vouch for its authenticity.
+func (v *Verifier) resolveRootCapability(ctx context.Context, resolver CapabilityResolver, capability *Capability,
+	chain []interface{}, invocation *CapabilityInvocation) (*Capability, []interface{}, error) {
+	rootURI, rest := capability.ID, []interface{}{}
+
+	var embedded map[string]interface{}
+
+	if len(chain) > 0 {
+		uri, ok := chain[0].(string)
+
+		if m, isMap := chain[0].(map[string]interface{}); isMap && parentID(m) != "" {
+			embedded, uri, ok = m, parentID(m), true
+		}
+
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid capability URI format: %v", chain[0])
+		}
+
+		rootURI, rest = uri, chain[1:]
+	}
+
+	root, err := resolver.Resolve(ctx, rootURI)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve root capability URI %s: %w", rootURI, err)
+	}
+
+	if embedded != nil && !embeds(embedded, root) {
+		return nil, nil, fmt.Errorf("embedded root capability %s does not match the resolved root capability", rootURI)
+	}
+
+	// 4.1. Check the expected target, if one was specified.
+	// TODO revisit the datatypes assumed of the invocationTarget.ID in this algo:
+	//  https://github.com/digitalbazaar/ocapld.js/blob/8a54398162837b1cf52c82978bc8127e52d02974/lib/utils.js#L115
+	if invocation.ExpectedTarget != "" && invocation.ExpectedTarget != root.InvocationTarget.ID {
+		return nil, nil, fmt.Errorf(
+			`%w: expected target does not match root capability target: expected="%s" target="%s"`,
+			ErrTargetMismatch, invocation.ExpectedTarget, root.InvocationTarget.ID)
+	}
+
+	if invocation.ExpectedTargetType != "" && invocation.ExpectedTargetType != root.InvocationTarget.Type {
+		return nil, nil, fmt.Errorf(
+			`%w: expected target type does not match root capability target type: expected="%s" type="%s"`,
+			ErrTargetTypeMismatch, invocation.ExpectedTargetType, root.InvocationTarget.Type)
+	}
+
+	return root, rest, nil
+}
+
+// embeds returns true if the embedded capability has the same JSON representation as the capability.
+func embeds(embedded map[string]interface{}, capability *Capability) bool {
+	raw, err := json.Marshal(capability)
+	if err != nil {
+		return false
+	}
+
+	var expected map[string]interface{}
+
+	err = json.Unmarshal(raw, &expected)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(embedded, expected)
+}
+
+// validateAllowedAction ensures the intended action, if given, is allowed by the capability. Actions are compared
+// case-insensitively if 'foldCase' is true.
+func validateAllowedAction(capability *Capability, intendedAction string, foldCase bool) error {
+	contains := stringsContain
+
+	if foldCase {
+		contains = stringsContainFold
+	}
+
+	// 1.1. Ensure `capabilityAction`, if given, is allowed; if the capability
+	// restricts the actions via `allowedAction` then it must be in the set.
+	// The wildcard only grants all actions on root capabilities: delegated capabilities cannot widen the actions
+	// allowed by their chain with it.
+	if len(capability.AllowedAction) > 0 && intendedAction != "" &&
+		!(capability.IsRoot() && stringsContain(capability.AllowedAction, AllowedActionWildcard)) &&
+		!contains(capability.AllowedAction, intendedAction) {
+		return fmt.Errorf(
+			`%w: capability action "%s" is not allowed by the capability; allowed actions are: %+v`,
+			ErrActionNotAllowed, intendedAction, capability.AllowedAction)
+	}
+
+	return nil
+}
+
+func (v *Verifier) verifyRootCapability(root *Capability, invocation *CapabilityInvocation) error {
+	err := v.verifyNotRevoked(root)
+	if err != nil {
+		return err
+	}
+
+	// 4.2. Ensure that the caveats are met on the root capability.
+	err = v.caveats.verify(root, invocation, v.clock, v.allowUnknownCaveatTypes)
+	if err != nil {
+		return fmt.Errorf("failed to verify caveats: %w", err)
+	}
+
+	err = verifyAdditionalTargets(root, invocation)
+	if err != nil {
+		return err
+	}
+
+	err = v.verifyNotExpired(root)
+	if err != nil {
+		return err
+	}
+
+	err = verifyAudience(root, invocation)
+	if err != nil {
+		return err
+	}
+
+	// 4.3. Ensure root capability is expected and has no invocation target.
+	if invocation.ExpectedRootCapability != "" && invocation.ExpectedRootCapability != root.ID {
+		return fmt.Errorf(
+			"%w: expected root capability does not match actual root capability: expected=(%s) actual=(%s)",
+			ErrRootCapabilityMismatch, invocation.ExpectedRootCapability, root.ID)
+	}
+
+	// Without an expected root capability, the root capability is trusted only if it targets itself: its ID is the
+	// one of the resource it grants access to.
+	if invocation.ExpectedRootCapability == "" && root.InvocationTarget.ID != root.ID {
+		return fmt.Errorf(
+			"%w: without an expected root capability, the ID of the root capability must equal its invocation "+
+				"target ID: id=(%s) invocationTarget=(%s)", ErrSelfReferenceRequired, root.ID, root.InvocationTarget.ID)
+	}
+
+	return nil
+}
+
+// verifyAdditionalTargets ensures the root capability covers the additional targets of the invocation: they are its
+// invocation target or are granted by its AdditionalTargetsCaveat. It is verified even with caveat registries
+// that skip the caveat.
+func verifyAdditionalTargets(root *Capability, invocation *CapabilityInvocation) error {
+	if len(invocation.AdditionalTargets) == 0 {
+		return nil
+	}
+
+	caveat := &AdditionalTargetsCaveat{}
+
+	if raw, ok := root.GetCaveat(CaveatTypeAdditionalTargets); ok {
+		err := json.Unmarshal(raw, caveat)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal caveat of type %s: %w", CaveatTypeAdditionalTargets, err)
+		}
+	}
+
+	caveat.setCapability(root)
+
+	return caveat.Verify(invocation)
+}
+
+func (v *Verifier) verifyDelegatedCapability(
+	parentID string, parent, capability *Capability, invocation *CapabilityInvocation) error {
+	if capability.Parent != parentID {
+		return fmt.Errorf(
+			"parent capability does not match the previous capability in the chain: expected=(%s) actual=(%s)",
+			parentID, capability.Parent)
+	}
+
+	err := v.verifyNotRevoked(capability)
+	if err != nil {
+		return err
+	}
+
+	err = v.verifyNotExpired(capability)
+	if err != nil {
+		return err
+	}
+
+	err = verifyAudience(capability, invocation)
+	if err != nil {
+		return err
+	}
+
+	err = v.caveats.verify(capability, invocation, v.clock, v.allowUnknownCaveatTypes)
+	if err != nil {
+		return fmt.Errorf("failed to verify caveats: %w", err)
+	}
+
+	if parent != nil {
+		err = verifyDelegation(parent, capability)
+		if err != nil {
+			return err
+		}
+	}
+
+	// chains verified without a delegation proof verifier are not checked for the authenticity of their delegations
+	if v.delegations == nil || parent == nil {
+		return nil
+	}
+
+	err = v.delegations.Verify(parent, capability)
+	if err != nil {
+		return fmt.Errorf("failed to verify delegation proof: %w", err)
+	}
+
+	return nil
+}
+
+// verifyDelegation ensures 'capability' was delegated by an authorized delegator of 'parent'.
+func verifyDelegation(parent, capability *Capability) error {
+	signer, err := capability.delegationVerificationMethod()
+	if err != nil {
+		return fmt.Errorf("failed to fetch delegation verification method: %w", err)
+	}
+
+	if !isDelegator(parent, signer) {
+		return fmt.Errorf(
+			"capability %s was delegated by %s who is not an authorized delegator of parent capability %s",
+			capability.ID, signer, parent.ID)
+	}
+
+	return nil
+}
+
+// isDelegator reports whether the verification method is an authorized delegator of the capability. As with
+// IsInvoker, a DID URL and the DID without its fragment refer to the same delegator; the verification method is
+// taken to be controlled by the DID it is a URL of.
+func isDelegator(capability *Capability, verificationMethodID string) bool {
+	controller := normalizeDIDURL(verificationMethodID)
+
+	for _, delegator := range capability.delegators() {
+		if uriEqual(delegator, verificationMethodID) || sameDID(delegator, verificationMethodID, controller) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (v *Verifier) verifyController(verificationMethod *VerificationMethod) error {
+	if v.controllers == nil {
+		return nil
+	}
+
+	if verificationMethod == nil {
+		return errors.New("verification method is required")
+	}
+
+	controller, err := v.controllers.Resolve(verificationMethod.Controller)
+	if err != nil {
+		return fmt.Errorf("failed to resolve controller %s: %w", verificationMethod.Controller, err)
+	}
+
+	if !stringsContain(controller.CapabilityInvocation, verificationMethod.ID) {
+		return fmt.Errorf(
+			"verification method %s is not authorized by controller %s for capabilityInvocation",
+			verificationMethod.ID, verificationMethod.Controller)
+	}
+
+	return nil
+}
+
+// proofPurpose returns the purpose of the proof, ensuring it is the expected proof purpose of the invocation.
+func proofPurpose(proof *Proof, invocation *CapabilityInvocation) (string, error) {
+	if proof.Purpose != "" && invocation.Purpose != "" && proof.Purpose != invocation.Purpose {
+		return "", fmt.Errorf(`proof purpose "%s" does not match the expected proof purpose "%s"`,
+			proof.Purpose, invocation.Purpose)
+	}
+
+	for _, purpose := range []string{proof.Purpose, invocation.Purpose} {
+		if purpose != "" {
+			return purpose, nil
+		}
+	}
+
+	return ProofPurposeCapabilityInvocation, nil
+}
+
+func (v *Verifier) verifyProofAge(proof *Proof) error {
+	if v.maxAge <= 0 || proof.Created.IsZero() {
+		return nil
+	}
+
+	if v.clock().Sub(proof.Created) > v.maxAge {
+		return fmt.Errorf("proof created at %s is older than the maximum allowed age of %s",
+			proof.Created.Format(time.RFC3339), v.maxAge)
+	}
+
+	return nil
+}
+
+func (v *Verifier) verifyNotRevoked(capability *Capability) error {
+	if v.revocations == nil {
+		return nil
+	}
+
+	revoked, err := v.revocations.IsRevoked(capability.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check revocation of capability %s: %w", capability.ID, err)
+	}
+
+	if revoked {
+		return fmt.Errorf("capability %s has been revoked", capability.ID)
+	}
+
+	return nil
+}
+
+// verifyAudience ensures the invocation's expected audience is one the capability is restricted to, if any.
+func verifyAudience(capability *Capability, invocation *CapabilityInvocation) error {
+	if len(capability.Audience) == 0 || stringsContain(capability.Audience, invocation.ExpectedAudience) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		`expected audience "%s" is not an audience of capability %s; audiences are: %+v`,
+		invocation.ExpectedAudience, capability.ID, capability.Audience)
+}
+
+func (v *Verifier) verifyNotExpired(capability *Capability) error {
+	expired, err := capability.expired(v.clock())
+	if err != nil {
+		return fmt.Errorf("failed to verify expiry: %w", err)
+	}
+
+	if expired {
+		return fmt.Errorf("%w: capability %s expired at %s", ErrCapabilityExpired, capability.ID, capability.ExpiresAt)
+	}
+
+	return nil
+}
+
+func (v *Verifier) verifyProof(capability *Capability) error {
+	bits, err := json.Marshal(capability)
+	if err != nil {
+		return fmt.Errorf("failed to marshal capability: %w", err)
+	}
+
+	err = v.verifier.Verify(bits, v.ldProcOpts...)
+	if err != nil {
+		return fmt.Errorf("document verifier error: %w", err)
+	}
+
+	return nil
+}
+
+func stringsContain(strs []string, s string) bool {
+	for i := range strs {
+		if s == strs[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func stringsContainFold(strs []string, s string) bool {
+	for i := range strs {
+		if strings.EqualFold(s, strs[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsInvoker reports whether the verification method, or its controller, is an authorized invoker of the capability.
+// A DID URL and the DID without its fragment refer to the same invoker if the verification method is controlled by
+// that DID.
+func IsInvoker(capability *Capability, verificationMethod *VerificationMethod) (bool, error) {
+	if verificationMethod == nil {
+		return false, errors.New("verification method is required")
+	}
+
+	invokers, err := capability.invokers()
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch invokers: %w", err)
+	}
+
+	controller := verificationMethod.Controller
+
+	for _, invoker := range invokers {
+		if uriEqual(invoker, verificationMethod.ID) || (controller != "" && uriEqual(invoker, controller)) ||
+			sameDID(invoker, verificationMethod.ID, controller) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// sameDID reports whether the invoker and the verification method ID refer to the same DID, one of them with a
+// fragment and the other without, and the verification method is controlled by that DID. URLs with different
+// fragments refer to different verification methods, so they never refer to the same DID here.
+func sameDID(invoker, verificationMethodID, controller string) bool {
+	did := normalizeDIDURL(verificationMethodID)
+
+	if !uriEqual(did, normalizeDIDURL(invoker)) || !uriEqual(did, controller) {
+		return false
+	}
+
+	return uriEqual(did, invoker) || uriEqual(did, verificationMethodID)
+}
+
+// normalizeDIDURL strips the fragment of the DID URL, returning the DID. Other URIs are returned as is.
+func normalizeDIDURL(u string) string {
+	if !strings.HasPrefix(u, "did:") {
+		return u
+	}
+
+	if i := strings.IndexByte(u, '#'); i >= 0 {
+		return u[:i]
+	}
+
+	return u
+}
+
+// VerificationMethodEqual reports whether the verification methods have the same ID. The IDs are compared after
+// percent-decoding them and lower-casing their scheme and host, so different forms of the same URI are equal.
+func VerificationMethodEqual(a, b *VerificationMethod) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return uriEqual(a.ID, b.ID)
+}
+
+func uriEqual(a, b string) bool {
+	return a == b || normalizeURI(a) == normalizeURI(b)
+}
+
+// normalizeURI percent-decodes the URI and lower-cases its scheme and host. Invalid URIs are returned as is.
+func normalizeURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" {
+		return uri
+	}
+
+	b := &strings.Builder{}
+	b.WriteString(strings.ToLower(u.Scheme) + ":")
+
+	if u.Opaque != "" {
+		opaque, err := url.PathUnescape(u.Opaque)
+		if err != nil {
+			return uri
+		}
+
+		b.WriteString(opaque)
+	} else {
+		if u.Host != "" || u.User != nil {
+			b.WriteString("//")
+		}
+
+		if u.User != nil {
+			b.WriteString(u.User.String() + "@")
+		}
+
+		b.WriteString(strings.ToLower(u.Host) + u.Path)
+	}
+
+	if u.RawQuery != "" {
+		query, err := url.QueryUnescape(u.RawQuery)
+		if err != nil {
+			return uri
+		}
+
+		b.WriteString("?" + query)
+	}
+
+	if u.Fragment != "" {
+		b.WriteString("#" + u.Fragment)
+	}
+
+	return b.String()
 }
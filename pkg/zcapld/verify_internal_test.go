@@ -0,0 +1,175 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld // nolint:testpackage // references internal implementation details
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateInvokedAction(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowed       []string
+		parent        string
+		intended      string
+		expected      string
+		foldCase      bool
+		expectedError string
+	}{
+		{name: "allowed action", allowed: []string{"read", "write"}, intended: "write", expected: "write"},
+		{name: "no allowed actions", intended: "delete", expected: "delete"},
+		{name: "wildcard", allowed: []string{AllowedActionWildcard}, intended: "delete", expected: "delete"},
+		{
+			name:          "wildcard on a delegated capability",
+			allowed:       []string{AllowedActionWildcard},
+			parent:        "urn:zcap:root",
+			intended:      "delete",
+			expected:      "delete",
+			expectedError: `capability action "delete" is not allowed by the capability; allowed actions are: [*]`,
+		},
+		{name: "no intended action", allowed: []string{"read"}},
+		{name: "case-insensitive", allowed: []string{"Read"}, intended: "READ", expected: "read", foldCase: true},
+		{
+			name:          "case-sensitive allowed action",
+			allowed:       []string{"Read"},
+			intended:      "read",
+			expected:      "read",
+			expectedError: `capability action "read" is not allowed by the capability; allowed actions are: [Read]`,
+		},
+		{
+			name:          "case-sensitive expected action",
+			intended:      "READ",
+			expected:      "read",
+			expectedError: `capability action "READ" does not match the expected capability action of "read"`,
+		},
+		{
+			name:          "action not allowed",
+			allowed:       []string{"read"},
+			intended:      "write",
+			expected:      "write",
+			expectedError: `capability action "write" is not allowed by the capability; allowed actions are: [read]`,
+		},
+		{
+			name:          "intended action differs from the expected action",
+			allowed:       []string{"read", "write"},
+			intended:      "read",
+			expected:      "write",
+			expectedError: `capability action "read" does not match the expected capability action of "write"`,
+		},
+		{
+			name:          "no intended action but an expected action",
+			allowed:       []string{"read"},
+			expected:      "read",
+			expectedError: `capability action "" does not match the expected capability action of "read"`,
+		},
+	}
+
+	for i := range tests {
+		test := tests[i]
+
+		t.Run(test.name, func(t *testing.T) {
+			err := validateInvokedAction(
+				&Capability{AllowedAction: test.allowed, Parent: test.parent}, test.intended, test.expected, test.foldCase)
+			if test.expectedError == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.True(t, errors.Is(err, ErrActionNotAllowed))
+			require.EqualError(t, err, ErrActionNotAllowed.Error()+": "+test.expectedError)
+		})
+	}
+}
+
+func TestResolveRootCapability(t *testing.T) {
+	root := &Capability{ID: "urn:zcap:root", InvocationTarget: InvocationTarget{ID: "urn:target"}}
+	v := &Verifier{zcaps: SimpleCapabilityResolver{root.ID: root}}
+
+	t.Run("success: delegated capability", func(t *testing.T) {
+		capability := &Capability{ID: "urn:zcap:2", Parent: "urn:zcap:1"}
+
+		result, rest, err := v.resolveRootCapability(context.Background(), v.zcaps, capability,
+			[]interface{}{root.ID, "urn:zcap:1"}, &CapabilityInvocation{ExpectedTarget: "urn:target"})
+		require.NoError(t, err)
+		require.Equal(t, root, result)
+		require.Equal(t, []interface{}{"urn:zcap:1"}, rest)
+	})
+
+	t.Run("success: root capability is its own root", func(t *testing.T) {
+		result, rest, err := v.resolveRootCapability(context.Background(), v.zcaps, root, nil, &CapabilityInvocation{})
+		require.NoError(t, err)
+		require.Equal(t, root, result)
+		require.Empty(t, rest)
+	})
+
+	t.Run("success: embedded root capability", func(t *testing.T) {
+		raw, err := json.Marshal(root)
+		require.NoError(t, err)
+
+		embedded := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(raw, &embedded))
+
+		result, rest, err := v.resolveRootCapability(context.Background(), v.zcaps, &Capability{ID: "urn:zcap:1"},
+			[]interface{}{embedded}, &CapabilityInvocation{})
+		require.NoError(t, err)
+		require.Equal(t, root, result)
+		require.Empty(t, rest)
+	})
+
+	t.Run("error: embedded root capability does not match the resolved one", func(t *testing.T) {
+		_, _, err := v.resolveRootCapability(context.Background(), v.zcaps, &Capability{ID: "urn:zcap:1"},
+			[]interface{}{map[string]interface{}{
+				"id": root.ID, "invocationTarget": map[string]interface{}{"id": "urn:other"},
+			}}, &CapabilityInvocation{})
+		require.EqualError(t, err,
+			"embedded root capability urn:zcap:root does not match the resolved root capability")
+	})
+
+	t.Run("error: invalid root capability URI", func(t *testing.T) {
+		_, _, err := v.resolveRootCapability(context.Background(), v.zcaps, &Capability{ID: "urn:zcap:1"},
+			[]interface{}{map[string]interface{}{}}, &CapabilityInvocation{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid capability URI format")
+	})
+
+	t.Run("error: root capability not found", func(t *testing.T) {
+		_, _, err := v.resolveRootCapability(context.Background(), v.zcaps, &Capability{ID: "urn:zcap:1"},
+			[]interface{}{"urn:zcap:other"}, &CapabilityInvocation{})
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrCapabilityNotFound))
+		require.Contains(t, err.Error(), "failed to resolve root capability URI urn:zcap:other")
+	})
+
+	t.Run("error: resolver fails", func(t *testing.T) {
+		failing := &Verifier{zcaps: resolverFunc(func(context.Context, string) (*Capability, error) {
+			return nil, errors.New("test")
+		})}
+
+		_, _, err := failing.resolveRootCapability(context.Background(), failing.zcaps, root, nil, &CapabilityInvocation{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to resolve root capability URI urn:zcap:root: test")
+	})
+
+	t.Run("error: unexpected invocation target", func(t *testing.T) {
+		_, _, err := v.resolveRootCapability(context.Background(), v.zcaps, root, nil,
+			&CapabilityInvocation{ExpectedTarget: "urn:other"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expected target does not match root capability target")
+	})
+}
+
+type resolverFunc func(ctx context.Context, uri string) (*Capability, error)
+
+func (f resolverFunc) Resolve(ctx context.Context, uri string) (*Capability, error) {
+	return f(ctx, uri)
+}
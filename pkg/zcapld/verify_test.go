@@ -7,8 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package zcapld_test
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
@@ -70,6 +72,7 @@ func TestVerifier_Verify(t *testing.T) {
 			},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -109,6 +112,7 @@ func TestVerifier_Verify(t *testing.T) {
 				},
 			)
 			err := verifier.Verify(
+				context.Background(),
 				&zcapld.Proof{
 					Capability:         capability,
 					CapabilityAction:   "read",
@@ -132,6 +136,7 @@ func TestVerifier_Verify(t *testing.T) {
 			},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -155,6 +160,7 @@ func TestVerifier_Verify(t *testing.T) {
 			},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -178,6 +184,7 @@ func TestVerifier_Verify(t *testing.T) {
 			},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -203,6 +210,7 @@ func TestVerifier_Verify(t *testing.T) {
 			},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -215,7 +223,7 @@ func TestVerifier_Verify(t *testing.T) {
 	})
 
 	t.Run("error: fails if capability is not provided", func(t *testing.T) {
-		err := verifier(t, nil, nil).Verify(&zcapld.Proof{}, nil)
+		err := verifier(t, nil, nil).Verify(context.Background(), &zcapld.Proof{}, nil)
 		require.EqualError(t, err, `"capability" was not found in the capability invocation proof`)
 	})
 
@@ -228,6 +236,7 @@ func TestVerifier_Verify(t *testing.T) {
 		)
 		require.Equal(t, []string{"read", "write"}, capability.AllowedAction)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "unauthorized",
@@ -239,6 +248,200 @@ func TestVerifier_Verify(t *testing.T) {
 		require.Contains(t, err.Error(), `capability action "unauthorized" is not allowed by the capability`)
 	})
 
+	t.Run("success: wildcard root capability allows any action", func(t *testing.T) {
+		rootSigner := testSigner(t, kms.ED25519)
+		rootID := fmt.Sprintf("urn:zcap:%s", uuid.New().String())
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withID(rootID), withInvoker(keyID(rootSigner)), withVerMethod(keyID(rootSigner)),
+			withInvocationTarget(rootID), withAllowedActions(zcapld.AllowedActionWildcard))
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         root,
+				CapabilityAction:   "delete",
+				VerificationMethod: root.Invoker,
+			},
+			invocation(root.Invoker, expectAction("delete")),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("success: actions are compared case-insensitively", func(t *testing.T) {
+		rootSigner := testSigner(t, kms.ED25519)
+		rootID := fmt.Sprintf("urn:zcap:%s", uuid.New().String())
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withID(rootID), withInvoker(keyID(rootSigner)), withVerMethod(keyID(rootSigner)),
+			withInvocationTarget(rootID), withAllowedActions("READ"))
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithCaseInsensitiveActions(),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         root,
+				CapabilityAction:   "read",
+				VerificationMethod: root.Invoker,
+			},
+			invocation(root.Invoker, expectAction("Read")),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: wildcard root capability does not lift restrictions of delegated capabilities", func(t *testing.T) {
+		rootSigner := testSigner(t, kms.ED25519)
+		rootID := fmt.Sprintf("urn:zcap:%s", uuid.New().String())
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withID(rootID), withVerMethod(keyID(rootSigner)),
+			withInvocationTarget(rootID), withAllowedActions(zcapld.AllowedActionWildcard))
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(rootSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}), withAllowedActions("read"))
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "write",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectAction("write")),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `capability action "write" is not allowed by the capability`)
+	})
+
+	t.Run("error: wildcard delegated capability does not lift restrictions of the root capability", func(t *testing.T) {
+		rootSigner := testSigner(t, kms.ED25519)
+		rootID := fmt.Sprintf("urn:zcap:%s", uuid.New().String())
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withID(rootID), withController(keyID(rootSigner)), withVerMethod(keyID(rootSigner)),
+			withInvocationTarget(rootID), withAllowedActions("read"))
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(rootSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}), withAllowedActions(zcapld.AllowedActionWildcard))
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "write",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectAction("write")),
+		)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrActionNotAllowed))
+		require.Contains(t, err.Error(), `capability action "write" is not allowed by the capability`)
+	})
+
+	t.Run("error: delegated capability does not lift restrictions of the root capability", func(t *testing.T) {
+		rootSigner := testSigner(t, kms.ED25519)
+		rootID := fmt.Sprintf("urn:zcap:%s", uuid.New().String())
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withID(rootID), withController(keyID(rootSigner)), withVerMethod(keyID(rootSigner)),
+			withInvocationTarget(rootID), withAllowedActions("read"))
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(rootSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}), withAllowedActions("read", "write"))
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "write",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectAction("write")),
+		)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrActionNotAllowed))
+		require.Contains(t, err.Error(), `capability action "write" is not allowed by the capability`)
+	})
+
+	t.Run("success: expected audience is an audience of the capability", func(t *testing.T) {
+		rootSigner := testSigner(t, kms.ED25519)
+		rootID := fmt.Sprintf("urn:zcap:%s", uuid.New().String())
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withID(rootID), withController(keyID(rootSigner)), withVerMethod(keyID(rootSigner)),
+			withInvocationTarget(rootID), withAudience("https://a.example.com", "https://b.example.com"))
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(rootSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}), withAudience("https://b.example.com"))
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectAudience("https://b.example.com")),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: expected audience is not an audience of the invoked capability", func(t *testing.T) {
+		rootSigner := testSigner(t, kms.ED25519)
+		rootID := fmt.Sprintf("urn:zcap:%s", uuid.New().String())
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withID(rootID), withVerMethod(keyID(rootSigner)), withInvocationTarget(rootID))
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(rootSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}), withAudience("https://a.example.com"))
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectAudience("https://b.example.com")),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `expected audience "https://b.example.com" is not an audience of capability`)
+	})
+
+	t.Run("error: expected audience is not an audience of the root capability", func(t *testing.T) {
+		rootSigner := testSigner(t, kms.ED25519)
+		rootID := fmt.Sprintf("urn:zcap:%s", uuid.New().String())
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withID(rootID), withVerMethod(keyID(rootSigner)), withInvocationTarget(rootID),
+			withAudience("https://a.example.com"))
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(rootSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}))
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectAudience("https://b.example.com")),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), fmt.Sprintf(
+			`expected audience "https://b.example.com" is not an audience of capability %s`, root.ID))
+	})
+
 	t.Run("error: fails if the intended action differs from the expected action", func(t *testing.T) {
 		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
 		capability := capability(t, rootSigner, ed25519signature2018.SignatureType)
@@ -248,6 +451,7 @@ func TestVerifier_Verify(t *testing.T) {
 			zcapld.SimpleKeyResolver{},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "write",
@@ -259,7 +463,7 @@ func TestVerifier_Verify(t *testing.T) {
 		require.Contains(t, err.Error(), `capability action "write" does not match the expected capability action of "read"`)
 	})
 
-	t.Run("error: unsupported capability embedded in capabilityChain", func(t *testing.T) {
+	t.Run("error: capability embedded in capabilityChain without an id", func(t *testing.T) {
 		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
 		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
 			withParent(root.ID), withCapabilityChain([]interface{}{
@@ -270,6 +474,7 @@ func TestVerifier_Verify(t *testing.T) {
 			}))
 		verifier := verifier(t, zcapld.SimpleCapabilityResolver{}, zcapld.SimpleKeyResolver{})
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -278,7 +483,7 @@ func TestVerifier_Verify(t *testing.T) {
 			invocation(capability.Invoker),
 		)
 		require.Error(t, err)
-		require.Contains(t, err.Error(), "embedded capabilities in capabilityChain not supported yet")
+		require.Contains(t, err.Error(), "invalid capability chain entry format")
 	})
 
 	t.Run("error: cycle in the capabilityChain", func(t *testing.T) {
@@ -299,6 +504,7 @@ func TestVerifier_Verify(t *testing.T) {
 			zcapld.SimpleKeyResolver{},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -307,9 +513,29 @@ func TestVerifier_Verify(t *testing.T) {
 			invocation(capability.Invoker),
 		)
 		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrChainCycle))
 		require.Contains(t, err.Error(), "the capability chain contains a cycle")
 	})
 
+	t.Run("error: capability chain includes the capability itself", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		id := "urn:zcap:" + uuid.New().String()
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withID(id), withParent(id), withCapabilityChain([]interface{}{root.ID, id}))
+		resolver := zcapld.SimpleCapabilityResolver{root.ID: root, id: capability}
+		err := verifier(t, resolver, zcapld.SimpleKeyResolver{}).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.True(t, errors.Is(err, zcapld.ErrChainCycle), "unexpected error: %v", err)
+		require.Contains(t, err.Error(), "capability "+id+" appears more than once")
+	})
+
 	t.Run("error: missing capabilityChain", func(t *testing.T) {
 		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
 		capability := capability(t, rootSigner, ed25519signature2018.SignatureType, withParent(root.ID))
@@ -318,6 +544,7 @@ func TestVerifier_Verify(t *testing.T) {
 			zcapld.SimpleKeyResolver{},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -341,6 +568,7 @@ func TestVerifier_Verify(t *testing.T) {
 			zcapld.SimpleKeyResolver{},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -361,6 +589,7 @@ func TestVerifier_Verify(t *testing.T) {
 			zcapld.SimpleKeyResolver{},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -372,7 +601,7 @@ func TestVerifier_Verify(t *testing.T) {
 		require.Contains(t, err.Error(), "no delegatable proofs found in capability")
 	})
 
-	t.Run("error: capability without invoker", func(t *testing.T) {
+	t.Run("error: capability without ID nor invoker", func(t *testing.T) {
 		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
 		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
 			withParent(root.ID), withID(""), withInvoker(""), withController(""),
@@ -382,6 +611,7 @@ func TestVerifier_Verify(t *testing.T) {
 			zcapld.SimpleKeyResolver{},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -389,21 +619,22 @@ func TestVerifier_Verify(t *testing.T) {
 			},
 			invocation(capability.Invoker, expectRootCapability(root.ID)),
 		)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "invoker not found for capability")
+		require.True(t, errors.Is(err, zcapld.ErrInvalidCapabilityID))
+		require.Contains(t, err.Error(), "invalid capability ID: empty ID")
 	})
 
 	t.Run("error: delegated but non-invocable capability", func(t *testing.T) {
 		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
 		delegator := keyID(testSigner(t, kms.ED25519))
 		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
-			withDelegator(delegator), withInvoker(""), withParent(root.ID),
+			withDelegator(delegator), withInvoker(""), withParent(root.ID), withVerMethod(keyID(rootSigner)),
 			withCapabilityChain([]interface{}{root.ID}))
 		verifier := verifier(t,
 			zcapld.SimpleCapabilityResolver{root.ID: root},
 			zcapld.SimpleKeyResolver{},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -420,6 +651,7 @@ func TestVerifier_Verify(t *testing.T) {
 		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
 			withParent(root.ID), withCapabilityChain([]interface{}{root.ID}))
 		err := verifier(t, zcapld.SimpleCapabilityResolver{}, zcapld.SimpleKeyResolver{}).Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -443,6 +675,7 @@ func TestVerifier_Verify(t *testing.T) {
 			zcapld.SimpleKeyResolver{},
 		)
 		err := verifier.Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -464,53 +697,892 @@ func TestVerifier_Verify(t *testing.T) {
 			zcapld.SimpleCapabilityResolver{root.ID: root},
 			zcapld.SimpleKeyResolver{},
 		)
-		err := verifier.Verify(
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation,
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expected root capability does not match actual root capability")
+	})
+
+	t.Run("success: expected target type matches the root capability's target type", func(t *testing.T) {
+		root, rootSigner := selfSignedSelfInvokingRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         root,
+				CapabilityAction:   "read",
+				VerificationMethod: root.Invoker,
+			},
+			invocation(root.Invoker, expectRootCapability(root.ID), expectTargetType("urn:edv:document")),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: no expected root capability on invocation yet root capability's invocation target is not itself", func(t *testing.T) { // nolint:lll // readability
+		rootSigner := testSigner(t, kms.ED25519)
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withID("urn:zcap:123"), withInvocationTarget("http://edv.com/foo/document/123"),
+			withVerMethod(keyID(rootSigner)), withCapabilityChain([]interface{}{"http://edv.com/foo/document/123"}))
+		require.NotEqual(t, root.ID, root.InvocationTarget.ID)
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withParent(root.ID), withCapabilityChain([]interface{}{root.ID}))
+		invocation := invocation(capability.Invoker, expectTarget(root.InvocationTarget.ID))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{},
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation,
+		)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrSelfReferenceRequired))
+		require.Contains(t, err.Error(),
+			"without an expected root capability, the ID of the root capability must equal its invocation target ID")
+	})
+
+	t.Run("success: no expected root capability on invocation and root capability's invocation target is itself", func(t *testing.T) { // nolint:lll // readability
+		rootSigner := testSigner(t, kms.ED25519)
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withID("urn:zcap:123"), withInvocationTarget("urn:zcap:123"), withInvoker(keyID(rootSigner)),
+			withVerMethod(keyID(rootSigner)), withCapabilityChain([]interface{}{"http://edv.com/foo/document/123"}))
+		require.Equal(t, root.ID, root.InvocationTarget.ID)
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}))
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectTarget(root.ID)),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: intermediate capability does not match the root capability", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withParent(root.ID), withCapabilityChain([]interface{}{root.ID + "123", root.ID}))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{
+				root.ID:         root,
+				root.ID + "123": root,
+			}, zcapld.SimpleKeyResolver{})
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parent capability does not match the previous capability in the chain")
+	})
+
+	t.Run("success: delegation chain of depth 2", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 1)
+		parent := chain[len(chain)-1]
+		invoker := keyID(testSigner(t, kms.ED25519))
+		capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+			withInvoker(invoker), withParent(parent.zcap.ID), withVerMethod(keyID(parent.signer)),
+			withCapabilityChain([]interface{}{root.ID, parent.zcap.ID}))
+		err := verifier(t, chainResolver(root, chain), chainKeyResolver(t, rootSigner, chain)).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("success: delegation chain of depth 3", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 2)
+		parent := chain[len(chain)-1]
+		invoker := keyID(testSigner(t, kms.ED25519))
+		capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+			withInvoker(invoker), withParent(parent.zcap.ID), withVerMethod(keyID(parent.signer)),
+			withCapabilityChain([]interface{}{root.ID, chain[0].zcap.ID, parent.zcap.ID}))
+		err := verifier(t, chainResolver(root, chain), chainKeyResolver(t, rootSigner, chain)).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: capability delegated by an unauthorized party", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 1)
+		parent := chain[len(chain)-1]
+		intruder := testSigner(t, kms.ED25519)
+		capability := capability(t, intruder, ed25519signature2018.SignatureType,
+			withInvoker(keyID(intruder)), withParent(parent.zcap.ID), withVerMethod(keyID(intruder)),
+			withCapabilityChain([]interface{}{root.ID, parent.zcap.ID}))
+		err := verifier(t, chainResolver(root, chain), chainKeyResolver(t, rootSigner, chain)).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "who is not an authorized delegator of parent capability "+parent.zcap.ID)
+	})
+
+	t.Run("error: intermediate capability delegated by an unauthorized party", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 1)
+		intruder := testSigner(t, kms.ED25519)
+		intermediate := capability(t, intruder, ed25519signature2018.SignatureType,
+			withDelegator(keyID(intruder)), withParent(chain[0].zcap.ID), withVerMethod(keyID(intruder)),
+			withCapabilityChain([]interface{}{root.ID, chain[0].zcap.ID}))
+		chain = append(chain, &delegation{zcap: intermediate, signer: intruder})
+		capability := capability(t, intruder, ed25519signature2018.SignatureType,
+			withInvoker(keyID(intruder)), withParent(intermediate.ID), withVerMethod(keyID(intruder)),
+			withCapabilityChain([]interface{}{root.ID, chain[0].zcap.ID, intermediate.ID}))
+		err := verifier(t, chainResolver(root, chain), chainKeyResolver(t, rootSigner, chain)).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "who is not an authorized delegator of parent capability "+chain[0].zcap.ID)
+	})
+
+	t.Run("error: capability delegated from the root capability by an unauthorized party", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		intruder := testSigner(t, kms.ED25519)
+		capability := capability(t, intruder, ed25519signature2018.SignatureType,
+			withInvoker(keyID(intruder)), withParent(root.ID), withVerMethod(keyID(intruder)),
+			withCapabilityChain([]interface{}{root.ID}))
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{
+				keyID(rootSigner): keyValue(t, rootSigner),
+				keyID(intruder):   keyValue(t, intruder),
+			},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "who is not an authorized delegator of parent capability "+root.ID)
+	})
+
+	t.Run("success: root capability delegated by a verification method of its controller", func(t *testing.T) {
+		rootSigner := testSigner(t, kms.ED25519)
+		verificationMethod := keyID(rootSigner) + "#" + strings.TrimPrefix(keyID(rootSigner), "did:key:")
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withController(keyID(rootSigner)), withVerMethod(verificationMethod),
+			withCapabilityChain([]interface{}{fmt.Sprintf("https://foo.com/edvs/documents/%s", uuid.New().String())}))
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(root.ID), withVerMethod(verificationMethod),
+			withCapabilityChain([]interface{}{root.ID}))
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{verificationMethod: keyValue(t, rootSigner)},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: cannot resolve intermediate capability", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 1)
+		parent := chain[len(chain)-1]
+		capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+			withParent(parent.zcap.ID), withVerMethod(keyID(parent.signer)),
+			withCapabilityChain([]interface{}{root.ID, parent.zcap.ID}))
+		err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			chainKeyResolver(t, rootSigner, chain),
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to resolve capability URI "+parent.zcap.ID)
+	})
+
+	t.Run("error: invalid signature on intermediate capability", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 1)
+		parent := chain[len(chain)-1]
+		capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+			withParent(parent.zcap.ID), withVerMethod(keyID(parent.signer)),
+			withCapabilityChain([]interface{}{root.ID, parent.zcap.ID}))
+		err := verifier(t,
+			chainResolver(root, chain),
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, testSigner(t, kms.ED25519))},
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to verify delegation proof")
+	})
+
+	t.Run("success: capabilities not yet expired", func(t *testing.T) {
+		now := time.Now()
+		rootSigner := testSigner(t, kms.ED25519)
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withController(keyID(rootSigner)), withVerMethod(keyID(rootSigner)), withExpiresAt(now.Add(time.Hour)),
+			withCapabilityChain([]interface{}{fmt.Sprintf("https://foo.com/edvs/documents/%s", uuid.New().String())}))
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withExpiresAt(now.Add(time.Hour)), withCapabilityChain([]interface{}{root.ID}))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{
+				keyID(rootSigner): keyValue(t, rootSigner),
+			},
+			zcapld.WithClock(func() time.Time { return now.Add(time.Minute) }),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: delegated capability has expired", func(t *testing.T) {
+		now := time.Now()
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withExpiresAt(now), withCapabilityChain([]interface{}{root.ID}))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{},
+			zcapld.WithClock(func() time.Time { return now.Add(time.Hour) }),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(),
+			fmt.Sprintf("capability %s expired at %s", capability.ID, capability.ExpiresAt))
+	})
+
+	t.Run("error: root capability has expired", func(t *testing.T) {
+		now := time.Now()
+		rootSigner := testSigner(t, kms.ED25519)
+		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withVerMethod(keyID(rootSigner)), withExpiresAt(now),
+			withCapabilityChain([]interface{}{fmt.Sprintf("https://foo.com/edvs/documents/%s", uuid.New().String())}))
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withParent(root.ID), withVerMethod(keyID(rootSigner)), withCapabilityChain([]interface{}{root.ID}))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{},
+			zcapld.WithClock(func() time.Time { return now.Add(time.Hour) }),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), fmt.Sprintf("capability %s expired at %s", root.ID, root.ExpiresAt))
+	})
+
+	t.Run("error: invalid expiry format", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withParent(root.ID), withVerMethod(keyID(rootSigner)), withCapabilityChain([]interface{}{root.ID}))
+		capability.ExpiresAt = "tomorrow"
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{},
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid expiry format on capability")
+	})
+
+	t.Run("success: proof is within the maximum allowed age", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{
+				keyID(rootSigner): keyValue(t, rootSigner),
+			},
+			zcapld.WithMaxProofAge(time.Minute),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+				Created:            time.Now(),
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: proof is older than the maximum allowed age", func(t *testing.T) {
+		now := time.Now()
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withParent(root.ID), withVerMethod(keyID(rootSigner)), withCapabilityChain([]interface{}{root.ID}))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{},
+			zcapld.SimpleKeyResolver{},
+			zcapld.WithMaxProofAge(time.Minute),
+			zcapld.WithClock(func() time.Time { return now.Add(time.Hour) }),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+				Created:            now,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is older than the maximum allowed age of 1m0s")
+	})
+
+	t.Run("success: verification method is authorized by its controller", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		invoker := keyID(testSigner(t, kms.ED25519))
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(invoker), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{
+				keyID(rootSigner): keyValue(t, rootSigner),
+			},
+			zcapld.WithControllerResolver(zcapld.SimpleControllerResolver{
+				invoker: {ID: invoker, CapabilityInvocation: []string{invoker}},
+			}),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("error: verification method is not authorized by its controller", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		invoker := keyID(testSigner(t, kms.ED25519))
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(invoker), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{},
+			zcapld.WithControllerResolver(zcapld.SimpleControllerResolver{
+				invoker: {ID: invoker, CapabilityInvocation: []string{"did:example:other"}},
+			}),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not authorized by controller")
+	})
+
+	t.Run("error: cannot resolve controller", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		invoker := keyID(testSigner(t, kms.ED25519))
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(invoker), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}))
+		verifier := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{},
+			zcapld.WithControllerResolver(zcapld.SimpleControllerResolver{}),
+		)
+		err := verifier.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to resolve controller")
+	})
+	t.Run("error: context is passed to the capability resolver", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := verifier(t,
+			&contextResolver{resolver: zcapld.SimpleCapabilityResolver{root.ID: root}},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).Verify(
+			ctx,
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func TestIsInvoker(t *testing.T) {
+	testCases := []struct {
+		name       string
+		capability *zcapld.Capability
+		vm         *zcapld.VerificationMethod
+		expected   bool
+	}{
+		{
+			name:       "exact verification method ID match",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:123#key1"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:123#key1", Controller: "did:example:123"},
+			expected:   true,
+		},
+		{
+			name:       "controller match",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:123"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:123#key1", Controller: "did:example:123"},
+			expected:   true,
+		},
+		{
+			name:       "percent-encoded verification method ID match",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:123%3Aabc#key1"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:123:abc#key1", Controller: "did:example:123:abc"},
+			expected:   true,
+		},
+		{
+			name:       "controller match with a different scheme and host case",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "HTTPS://Example.com/keys"},
+			vm:         &zcapld.VerificationMethod{ID: "https://example.com/keys#1", Controller: "https://example.com/keys"},
+			expected:   true,
+		},
+		{
+			name:       "no match",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:456"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:123#key1", Controller: "did:example:123"},
+			expected:   false,
+		},
+		{
+			name:       "no invokers set defaults to the capability ID",
+			capability: &zcapld.Capability{ID: "did:example:123"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:123#key1", Controller: "did:example:123"},
+			expected:   true,
+		},
+		{
+			name:       "delegator without invoker cannot be invoked",
+			capability: &zcapld.Capability{ID: "did:example:123", Delegator: "did:example:123"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:123#key1", Controller: "did:example:123"},
+			expected:   false,
+		},
+		{
+			name:       "empty verification method ID",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:123#key1"},
+			vm:         &zcapld.VerificationMethod{Controller: "did:example:456"},
+			expected:   false,
+		},
+		{
+			name:       "empty controller",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:123#key1"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:123#key1"},
+			expected:   true,
+		},
+		{
+			name:       "empty controller does not match",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:123"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:123#key1"},
+			expected:   false,
+		},
+		{
+			name:       "verification method without a fragment matches an invoker of the same DID",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:abc#key-1"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:abc", Controller: "did:example:abc"},
+			expected:   true,
+		},
+		{
+			name:       "invoker without a fragment matches a verification method of the same DID",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:abc"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:abc#key-1", Controller: "did:example:abc"},
+			expected:   true,
+		},
+		{
+			name:       "verification method without a fragment controlled by another DID does not match",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:abc#key-1"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:abc", Controller: "did:example:456"},
+			expected:   false,
+		},
+		{
+			name:       "verification method without a fragment or a controller does not match",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:abc#key-1"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:abc"},
+			expected:   false,
+		},
+		{
+			name:       "different fragments do not match",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:abc#key-1"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:abc#key-2", Controller: "did:example:abc"},
+			expected:   false,
+		},
+		{
+			name:       "fragments of other URIs are not stripped",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "https://example.com/keys#1"},
+			vm:         &zcapld.VerificationMethod{ID: "https://example.com/keys", Controller: "https://example.com/keys"},
+			expected:   false,
+		},
+		{
+			name: "invoker takes precedence over controller",
+			capability: &zcapld.Capability{
+				ID:         "urn:zcap:1",
+				Invoker:    "did:example:123",
+				Controller: "did:example:456",
+			},
+			vm:       &zcapld.VerificationMethod{ID: "did:example:456#key1", Controller: "did:example:456"},
+			expected: false,
+		},
+		{
+			name:       "controller is the invoker if no invoker is set",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Controller: "did:example:456"},
+			vm:         &zcapld.VerificationMethod{ID: "did:example:456#key1", Controller: "did:example:456"},
+			expected:   true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := zcapld.IsInvoker(tc.capability, tc.vm)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+
+	t.Run("error: no invoker, controller, or ID", func(t *testing.T) {
+		_, err := zcapld.IsInvoker(&zcapld.Capability{}, &zcapld.VerificationMethod{ID: "did:example:123#key1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invoker not found for capability")
+	})
+
+	t.Run("error: nil verification method", func(t *testing.T) {
+		_, err := zcapld.IsInvoker(&zcapld.Capability{ID: "urn:zcap:1"}, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verification method is required")
+	})
+}
+
+func TestVerifier_VerifyChain(t *testing.T) {
+	t.Run("success: returns a result for each capability in the chain", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 2)
+		parent := chain[len(chain)-1]
+		invoker := keyID(testSigner(t, kms.ED25519))
+		capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+			withInvoker(invoker), withParent(parent.zcap.ID), withVerMethod(keyID(parent.signer)),
+			withCapabilityChain([]interface{}{root.ID, chain[0].zcap.ID, parent.zcap.ID}))
+		results, err := verifier(t, chainResolver(root, chain), chainKeyResolver(t, rootSigner, chain)).VerifyChain(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.NoError(t, err)
+		require.Equal(t, []zcapld.LinkResult{
+			{CapabilityID: root.ID, Depth: 0},
+			{CapabilityID: chain[0].zcap.ID, Depth: 1},
+			{CapabilityID: parent.zcap.ID, Depth: 2},
+			{CapabilityID: capability.ID, Depth: 3},
+		}, results)
+	})
+
+	t.Run("success: single delegation", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}))
+		results, err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).VerifyChain(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.NoError(t, err)
+		require.Equal(t, []zcapld.LinkResult{
+			{CapabilityID: root.ID, Depth: 0},
+			{CapabilityID: capability.ID, Depth: 1},
+		}, results)
+	})
+
+	t.Run("error: reports every link that failed", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 2)
+		parent := chain[len(chain)-1]
+		intruder := testSigner(t, kms.ED25519)
+		capability := capability(t, intruder, ed25519signature2018.SignatureType,
+			withInvoker(keyID(intruder)), withParent(parent.zcap.ID), withVerMethod(keyID(intruder)),
+			withCapabilityChain([]interface{}{root.ID, chain[0].zcap.ID, parent.zcap.ID}))
+		resolver := chainResolver(root, chain)
+		delete(resolver, chain[0].zcap.ID)
+		results, err := verifier(t, resolver, chainKeyResolver(t, rootSigner, chain)).VerifyChain(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to resolve capability URI "+chain[0].zcap.ID)
+		require.Len(t, results, 4)
+		require.NoError(t, results[0].Err)
+		require.Error(t, results[1].Err)
+		require.Equal(t, err, results[1].Err)
+		require.NoError(t, results[2].Err)
+		require.Error(t, results[3].Err)
+		require.Contains(t, results[3].Err.Error(),
+			"who is not an authorized delegator of parent capability "+parent.zcap.ID)
+	})
+
+	t.Run("error: no verification method with a controller resolver", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t,
+			rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}))
+		inv := invocation(capability.Invoker, expectRootCapability(root.ID))
+		inv.VerificationMethod = nil
+		results, err := verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			zcapld.WithControllerResolver(zcapld.SimpleControllerResolver{}),
+		).VerifyChain(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
 				VerificationMethod: capability.Invoker,
 			},
-			invocation,
+			inv,
 		)
 		require.Error(t, err)
-		require.Contains(t, err.Error(), "expected root capability does not match actual root capability")
+		require.Len(t, results, 2)
+		require.Error(t, results[1].Err)
+		require.Contains(t, err.Error(), "verification method is required")
 	})
 
-	t.Run("error: no expected root capability on invocation yet root capability's invocation target is not itself", func(t *testing.T) { // nolint:lll // readability
-		rootSigner := testSigner(t, kms.ED25519)
-		root := capability(t, rootSigner, ed25519signature2018.SignatureType,
-			withID("urn:zcap:123"), withInvocationTarget("http://edv.com/foo/document/123"),
-			withVerMethod(keyID(rootSigner)), withCapabilityChain([]interface{}{"http://edv.com/foo/document/123"}))
-		require.NotEqual(t, root.ID, root.InvocationTarget.ID)
+	t.Run("error: invalid capability chain", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
 		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
-			withParent(root.ID), withCapabilityChain([]interface{}{root.ID}))
-		invocation := invocation(capability.Invoker, expectTarget(root.InvocationTarget.ID))
-		verifier := verifier(t,
+			withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID, root.ID}))
+		results, err := verifier(t,
 			zcapld.SimpleCapabilityResolver{root.ID: root},
-			zcapld.SimpleKeyResolver{},
-		)
-		err := verifier.Verify(
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		).VerifyChain(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
 				VerificationMethod: capability.Invoker,
 			},
-			invocation,
+			invocation(capability.Invoker),
 		)
 		require.Error(t, err)
-		require.Contains(t, err.Error(), "the root capability must not specify a different invocation target")
+		require.Contains(t, err.Error(), "the capability chain contains a cycle")
+		require.Nil(t, results)
+	})
+}
+
+func TestVerifyCapabilityChain(t *testing.T) {
+	t.Run("success: delegation chain of depth 3", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 2)
+		parent := chain[len(chain)-1]
+		capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(parent.zcap.ID),
+			withVerMethod(keyID(parent.signer)),
+			withCapabilityChain([]interface{}{root.ID, chain[0].zcap.ID, parent.zcap.ID}))
+		err := zcapld.VerifyCapabilityChain(context.Background(), chainResolver(root, chain), capability, "read",
+			invocation(capability.Invoker, expectRootCapability(root.ID)))
+		require.NoError(t, err)
+	})
+
+	t.Run("error: capability action is not authorized", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withInvoker(keyID(rootSigner)), withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID}))
+		err := zcapld.VerifyCapabilityChain(context.Background(), zcapld.SimpleCapabilityResolver{root.ID: root}, capability, "unauthorized",
+			invocation(capability.Invoker, expectAction("unauthorized"), expectRootCapability(root.ID)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `capability action "unauthorized" is not allowed by the capability`)
+	})
+
+	t.Run("error: capability delegated by an unauthorized party", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 1)
+		parent := chain[len(chain)-1]
+		intruder := testSigner(t, kms.ED25519)
+		capability := capability(t, intruder, ed25519signature2018.SignatureType,
+			withInvoker(keyID(intruder)), withParent(parent.zcap.ID), withVerMethod(keyID(intruder)),
+			withCapabilityChain([]interface{}{root.ID, parent.zcap.ID}))
+		err := zcapld.VerifyCapabilityChain(context.Background(), chainResolver(root, chain), capability, "read",
+			invocation(capability.Invoker, expectRootCapability(root.ID)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "who is not an authorized delegator of parent capability "+parent.zcap.ID)
 	})
 
-	t.Run("error: no support for multiple capabilityChains", func(t *testing.T) {
+	t.Run("error: invalid capability chain", func(t *testing.T) {
 		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
 		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
-			withParent(root.ID), withCapabilityChain([]interface{}{root.ID + "123", root.ID}))
-		verifier := verifier(t,
-			zcapld.SimpleCapabilityResolver{
-				root.ID:         root,
-				root.ID + "123": root,
-			}, zcapld.SimpleKeyResolver{})
-		err := verifier.Verify(
+			withParent(root.ID), withVerMethod(keyID(rootSigner)),
+			withCapabilityChain([]interface{}{root.ID, root.ID}))
+		err := zcapld.VerifyCapabilityChain(context.Background(), zcapld.SimpleCapabilityResolver{root.ID: root}, capability, "read",
+			invocation(capability.Invoker))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "the capability chain contains a cycle")
+	})
+}
+
+func TestWithDelegationProofVerifier(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	chain := delegationChain(t, root, rootSigner, 2)
+	parent := chain[len(chain)-1]
+	capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+		withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(parent.zcap.ID), withVerMethod(keyID(parent.signer)),
+		withCapabilityChain([]interface{}{root.ID, chain[0].zcap.ID, parent.zcap.ID}))
+
+	verify := func(d zcapld.DelegationProofVerifier) error {
+		return verifier(t, chainResolver(root, chain), zcapld.SimpleKeyResolver{},
+			zcapld.WithDelegationProofVerifier(d),
+		).Verify(
+			context.Background(),
 			&zcapld.Proof{
 				Capability:         capability,
 				CapabilityAction:   "read",
@@ -518,23 +1590,121 @@ func TestVerifier_Verify(t *testing.T) {
 			},
 			invocation(capability.Invoker, expectRootCapability(root.ID)),
 		)
+	}
+
+	t.Run("success: verifies every delegated capability", func(t *testing.T) {
+		d := &mockDelegationProofVerifier{}
+		require.NoError(t, verify(d))
+		require.Equal(t, []string{
+			root.ID + ">" + chain[0].zcap.ID,
+			chain[0].zcap.ID + ">" + parent.zcap.ID,
+			parent.zcap.ID + ">" + capability.ID,
+		}, d.verified)
+	})
+
+	t.Run("error: forged delegation proof", func(t *testing.T) {
+		err := verify(&mockDelegationProofVerifier{reject: chain[0].zcap.ID})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to verify delegation proof")
+	})
+
+	t.Run("error: forged delegation proof on the invoked capability", func(t *testing.T) {
+		err := verify(&mockDelegationProofVerifier{reject: capability.ID})
 		require.Error(t, err)
-		require.Contains(t, err.Error(), "multiple capabilityChains not supported yet")
+		require.Contains(t, err.Error(), "failed to verify proof")
 	})
 }
 
-func verifier(t *testing.T, r zcapld.CapabilityResolver, k zcapld.KeyResolver) *zcapld.Verifier {
+type mockDelegationProofVerifier struct {
+	verified []string
+	reject   string
+}
+
+func (m *mockDelegationProofVerifier) Verify(parent, child *zcapld.Capability) error {
+	if child.ID == m.reject {
+		return errors.New("forged")
+	}
+
+	m.verified = append(m.verified, parent.ID+">"+child.ID)
+
+	return nil
+}
+
+type contextResolver struct {
+	resolver zcapld.CapabilityResolver
+}
+
+func (c *contextResolver) Resolve(ctx context.Context, uri string) (*zcapld.Capability, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return c.resolver.Resolve(ctx, uri)
+}
+
+type delegation struct {
+	zcap   *zcapld.Capability
+	signer signature.Signer
+}
+
+// delegationChain returns 'depth' capabilities, each delegated to a new delegator by the previous one's delegator.
+func delegationChain(t *testing.T, root *zcapld.Capability, rootSigner signature.Signer, depth int) []*delegation {
+	t.Helper()
+
+	chain := make([]*delegation, 0, depth)
+	ids := []interface{}{root.ID}
+	parent := &delegation{zcap: root, signer: rootSigner}
+
+	for i := 0; i < depth; i++ {
+		delegatee := testSigner(t, kms.ED25519)
+		zcap := capability(t, parent.signer, ed25519signature2018.SignatureType,
+			withDelegator(keyID(delegatee)), withParent(parent.zcap.ID), withVerMethod(keyID(parent.signer)),
+			withCapabilityChain(append([]interface{}{}, ids...)))
+		parent = &delegation{zcap: zcap, signer: delegatee}
+		chain = append(chain, parent)
+		ids = append(ids, zcap.ID)
+	}
+
+	return chain
+}
+
+func chainResolver(root *zcapld.Capability, chain []*delegation) zcapld.SimpleCapabilityResolver {
+	r := zcapld.SimpleCapabilityResolver{root.ID: root}
+
+	for i := range chain {
+		r[chain[i].zcap.ID] = chain[i].zcap
+	}
+
+	return r
+}
+
+func chainKeyResolver(t *testing.T, rootSigner signature.Signer, chain []*delegation) zcapld.SimpleKeyResolver {
+	t.Helper()
+
+	r := zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)}
+
+	for i := range chain {
+		r[keyID(chain[i].signer)] = keyValue(t, chain[i].signer)
+	}
+
+	return r
+}
+
+func verifier(t testing.TB, r zcapld.CapabilityResolver, k zcapld.KeyResolver,
+	options ...zcapld.VerificationOption) *zcapld.Verifier {
 	t.Helper()
 
 	v, err := zcapld.NewVerifier(r, k,
-		zcapld.WithSignatureSuites(suites()...),
-		zcapld.WithLDDocumentLoaders(testLDDocumentLoader))
+		append([]zcapld.VerificationOption{
+			zcapld.WithSignatureSuites(suites()...),
+			zcapld.WithLDDocumentLoaders(testLDDocumentLoader),
+		}, options...)...)
 	require.NoError(t, err)
 
 	return v
 }
 
-func selfSignedSelfInvokingRootCapability(t *testing.T,
+func selfSignedSelfInvokingRootCapability(t testing.TB,
 	keyType kms.KeyType, signatureSuite string) (*zcapld.Capability, signature.Signer) {
 	capID := fmt.Sprintf("did:key:%s", uuid.New().String())
 	sig := testSigner(t, keyType)
@@ -553,6 +1723,7 @@ func selfSignedRootCapability(t *testing.T,
 
 	return capability(t,
 		sig, signatureSuite,
+		withController(keyID(sig)),
 		withVerMethod(keyID(sig)),
 		withCapabilityChain(
 			[]interface{}{fmt.Sprintf("https://foo.com/edvs/documents/%s", uuid.New().String())},
@@ -570,6 +1741,10 @@ type zcapOptions struct {
 	proofPurpose       string
 	delegator          string
 	invocationTarget   string
+	expiresAt          string
+	caveats            []json.RawMessage
+	allowedActions     []string
+	audience           []string
 }
 
 type zcapOption func(*zcapOptions)
@@ -628,11 +1803,36 @@ func withInvocationTarget(t string) zcapOption {
 	}
 }
 
-func capability(t *testing.T, sig verifiable.Signer, sigSuite string, options ...zcapOption) *zcapld.Capability {
+func withAllowedActions(a ...string) zcapOption {
+	return func(o *zcapOptions) {
+		o.allowedActions = a
+	}
+}
+
+func withAudience(a ...string) zcapOption {
+	return func(o *zcapOptions) {
+		o.audience = a
+	}
+}
+
+func withExpiresAt(e time.Time) zcapOption {
+	return func(o *zcapOptions) {
+		o.expiresAt = e.UTC().Format(time.RFC3339)
+	}
+}
+
+func withCaveats(c ...json.RawMessage) zcapOption {
+	return func(o *zcapOptions) {
+		o.caveats = c
+	}
+}
+
+func capability(t testing.TB, sig verifiable.Signer, sigSuite string, options ...zcapOption) *zcapld.Capability {
 	opts := &zcapOptions{
 		id:               fmt.Sprintf("urn:zcap:%s", uuid.New().String()),
 		proofPurpose:     zcapld.ProofPurpose,
 		invocationTarget: "https://foo.com/edvs/z19rnXA8d4TPLPHoSFwnQk256/documents/z19pj5XguLxKdXjxj38o7mDj3",
+		allowedActions:   []string{"read", "write"},
 	}
 
 	for i := range options {
@@ -657,11 +1857,14 @@ func capability(t *testing.T, sig verifiable.Signer, sigSuite string, options ..
 		Parent:        opts.parent,
 		Controller:    opts.controller,
 		Delegator:     opts.delegator,
-		AllowedAction: []string{"read", "write"},
+		AllowedAction: opts.allowedActions,
+		Audience:      opts.audience,
 		InvocationTarget: zcapld.InvocationTarget{
 			ID:   opts.invocationTarget,
 			Type: "urn:edv:document",
 		},
+		ExpiresAt: opts.expiresAt,
+		Caveats:   opts.caveats,
 	}
 
 	signZcap(t, zcap, ldProofSuite, sigSuite, opts)
@@ -670,9 +1873,11 @@ func capability(t *testing.T, sig verifiable.Signer, sigSuite string, options ..
 }
 
 type invocationOptions struct {
-	expectedTarget  string
-	expectedAction  string
-	expectedRootCap string
+	expectedTarget     string
+	expectedTargetType string
+	expectedAction     string
+	expectedRootCap    string
+	expectedAud        string
 }
 
 type invocationOption func(*invocationOptions)
@@ -683,6 +1888,12 @@ func expectTarget(t string) invocationOption {
 	}
 }
 
+func expectTargetType(t string) invocationOption {
+	return func(o *invocationOptions) {
+		o.expectedTargetType = t
+	}
+}
+
 func expectAction(a string) invocationOption {
 	return func(o *invocationOptions) {
 		o.expectedAction = a
@@ -695,6 +1906,12 @@ func expectRootCapability(c string) invocationOption {
 	}
 }
 
+func expectAudience(a string) invocationOption {
+	return func(o *invocationOptions) {
+		o.expectedAud = a
+	}
+}
+
 func invocation(verificationMethod string, options ...invocationOption) *zcapld.CapabilityInvocation {
 	opts := &invocationOptions{expectedAction: "read"}
 
@@ -704,8 +1921,10 @@ func invocation(verificationMethod string, options ...invocationOption) *zcapld.
 
 	return &zcapld.CapabilityInvocation{
 		ExpectedTarget:         opts.expectedTarget,
+		ExpectedTargetType:     opts.expectedTargetType,
 		ExpectedAction:         opts.expectedAction,
 		ExpectedRootCapability: opts.expectedRootCap,
+		ExpectedAudience:       opts.expectedAud,
 		VerificationMethod: &zcapld.VerificationMethod{
 			ID:         verificationMethod,
 			Controller: verificationMethod,
@@ -720,7 +1939,7 @@ func suites() []ariesver.SignatureSuite {
 	}
 }
 
-func testSigner(t *testing.T, kt kms.KeyType) signature.Signer {
+func testSigner(t testing.TB, kt kms.KeyType) signature.Signer {
 	t.Helper()
 
 	k, err := localkms.New(
@@ -738,7 +1957,7 @@ func testSigner(t *testing.T, kt kms.KeyType) signature.Signer {
 	return s
 }
 
-func nonce(t *testing.T) []byte {
+func nonce(t testing.TB) []byte {
 	n := make([]byte, 256)
 
 	_, err := rand.Reader.Read(n)
@@ -747,7 +1966,7 @@ func nonce(t *testing.T) []byte {
 	return n
 }
 
-func signZcap(t *testing.T,
+func signZcap(t testing.TB,
 	zcap *zcapld.Capability, signerSuite signer.SignatureSuite, suiteType string, options *zcapOptions) {
 	t.Helper()
 
@@ -774,7 +1993,7 @@ func signZcap(t *testing.T,
 	zcap.Proof = parseProof(t, signedDoc)
 }
 
-func marshal(t *testing.T, v interface{}) []byte {
+func marshal(t testing.TB, v interface{}) []byte {
 	t.Helper()
 
 	bits, err := json.Marshal(v)
@@ -783,7 +2002,7 @@ func marshal(t *testing.T, v interface{}) []byte {
 	return bits
 }
 
-func parseProof(t *testing.T, signedZcap []byte) []verifiable.Proof {
+func parseProof(t testing.TB, signedZcap []byte) []verifiable.Proof {
 	rawProof := &struct {
 		Proof json.RawMessage `json:"proof,omitempty"`
 	}{}
@@ -820,7 +2039,7 @@ func keyID(sigSigner signature.Signer) string {
 	return fmt.Sprintf("did:key:%s", thumb)
 }
 
-func keyValue(t *testing.T, sigSigner signature.Signer) *ariesver.PublicKey {
+func keyValue(t testing.TB, sigSigner signature.Signer) *ariesver.PublicKey {
 	t.Helper()
 
 	jwk, err := jose.JWKFromPublicKey(sigSigner.PublicKey())
@@ -845,7 +2064,7 @@ func createTestJSONLDDocumentLoader() *ld.CachingDocumentLoader {
 			filename: "w3id.org.security.v1.json",
 		},
 		{
-			vocab:    "https://w3id.org/security/v2",
+			vocab:    zcapld.SecurityContextV2,
 			filename: "w3id.org.security.v2.json",
 		},
 	}
@@ -876,3 +2095,310 @@ func addJSONLDCachedContext(loader *ld.CachingDocumentLoader, contextURL, contex
 
 	loader.AddDocument(contextURL, reader)
 }
+
+func TestWithMaxChainDepth(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	chain := delegationChain(t, root, rootSigner, 10)
+
+	// verify invokes a capability delegated from the capability at the given depth of the chain
+	verify := func(depth int, options ...zcapld.VerificationOption) error {
+		parent := chain[depth-2]
+		ids := []interface{}{root.ID}
+
+		for i := 0; i < depth-1; i++ {
+			ids = append(ids, chain[i].zcap.ID)
+		}
+
+		invoker := keyID(testSigner(t, kms.ED25519))
+		capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+			withInvoker(invoker), withParent(parent.zcap.ID), withVerMethod(keyID(parent.signer)),
+			withCapabilityChain(ids))
+
+		return verifier(t, chainResolver(root, chain), chainKeyResolver(t, rootSigner, chain), options...).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("success: chain at the maximum depth", func(t *testing.T) {
+		require.NoError(t, verify(10, zcapld.WithMaxChainDepth(10)))
+	})
+
+	t.Run("success: chain at the default maximum depth", func(t *testing.T) {
+		require.NoError(t, verify(10))
+	})
+
+	t.Run("success: no maximum depth", func(t *testing.T) {
+		require.NoError(t, verify(11, zcapld.WithMaxChainDepth(0)))
+	})
+
+	t.Run("error: chain deeper than the maximum depth", func(t *testing.T) {
+		err := verify(11, zcapld.WithMaxChainDepth(10))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrChainTooDeep))
+		require.Contains(t, err.Error(), "has a chain of 11 capabilities; the maximum is 10")
+	})
+
+	t.Run("error: chain deeper than the default maximum depth", func(t *testing.T) {
+		err := verify(11)
+		require.True(t, errors.Is(err, zcapld.ErrChainTooDeep))
+	})
+}
+
+func TestWithAllowedIDSchemes(t *testing.T) {
+	root, rootSigner := selfSignedSelfInvokingRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	verify := func(capability *zcapld.Capability, options ...zcapld.VerificationOption) error {
+		return verifier(t,
+			zcapld.SimpleCapabilityResolver{root.ID: root, capability.ID: capability},
+			zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+			options...,
+		).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("success: scheme is allowed", func(t *testing.T) {
+		require.NoError(t, verify(root, zcapld.WithAllowedIDSchemes("urn", "DID")))
+	})
+
+	t.Run("success: any scheme is allowed by default", func(t *testing.T) {
+		require.NoError(t, verify(root))
+	})
+
+	t.Run("error: scheme is not allowed", func(t *testing.T) {
+		err := verify(root, zcapld.WithAllowedIDSchemes("https", "urn"))
+		require.True(t, errors.Is(err, zcapld.ErrInvalidCapabilityID))
+		require.Contains(t, err.Error(),
+			fmt.Sprintf(`the scheme of "%s" is not one of the allowed schemes: [https urn]`, root.ID))
+	})
+
+	t.Run("error: scheme of a capability in the chain is not allowed", func(t *testing.T) {
+		capability := capability(t, rootSigner, ed25519signature2018.SignatureType,
+			withParent("ftp://example.com/zcap"), withCapabilityChain([]interface{}{"ftp://example.com/zcap"}))
+
+		err := verify(capability, zcapld.WithAllowedIDSchemes("urn", "did"))
+		require.True(t, errors.Is(err, zcapld.ErrInvalidCapabilityID))
+		require.Contains(t, err.Error(), `the scheme of "ftp://example.com/zcap" is not one of the allowed schemes`)
+	})
+
+	t.Run("error: relative ID is not allowed", func(t *testing.T) {
+		capability := zcapld.Clone(root)
+		capability.ID = "zcaps/123"
+
+		err := verify(capability, zcapld.WithAllowedIDSchemes("urn"))
+		require.True(t, errors.Is(err, zcapld.ErrInvalidCapabilityID))
+	})
+
+	t.Run("error: ID is not a URI", func(t *testing.T) {
+		capability := zcapld.Clone(root)
+		capability.ID = "https://example.com/%zz"
+
+		err := verify(capability)
+		require.True(t, errors.Is(err, zcapld.ErrInvalidCapabilityID))
+		require.Contains(t, err.Error(), `"https://example.com/%zz" is not a URI`)
+	})
+}
+
+func TestWithLogger(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	chain := delegationChain(t, root, rootSigner, 1)
+	parent := chain[0]
+	capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+		withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(parent.zcap.ID),
+		withVerMethod(keyID(parent.signer)), withCapabilityChain([]interface{}{root.ID, parent.zcap.ID}))
+
+	verify := func(l zcapld.Logger, action string) error {
+		return verifier(t, chainResolver(root, chain), chainKeyResolver(t, rootSigner, chain),
+			zcapld.WithLogger(l)).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   action,
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("logs each step of the chain verification", func(t *testing.T) {
+		l := &recordingLogger{}
+		require.NoError(t, verify(l, "read"))
+		require.Equal(t, []string{
+			"checking action of invoked capability " + capability.ID + " 2",
+			"resolving root capability " + root.ID + " 0",
+			"resolving delegated capability " + parent.zcap.ID + " 1",
+			"checking delegator of invoked capability " + capability.ID + " 2",
+			"checking invoker of invoked capability " + capability.ID + " 2",
+		}, l.messages)
+	})
+
+	t.Run("logs the failed step", func(t *testing.T) {
+		l := &recordingLogger{}
+		require.Error(t, verify(l, "write"))
+		require.Contains(t, l.messages, "capability verification failed "+capability.ID+" 2")
+	})
+}
+
+// recordingLogger records the messages logged at DEBUG level, along with the capability ID and chain depth.
+type recordingLogger struct {
+	messages []string
+}
+
+func (r *recordingLogger) Debug(module, msg string, fields ...interface{}) {
+	values := make(map[interface{}]interface{})
+
+	for i := 0; i+1 < len(fields); i += 2 {
+		values[fields[i]] = fields[i+1]
+	}
+
+	r.messages = append(r.messages, fmt.Sprintf("%s %v %v", msg, values["capabilityID"], values["chainDepth"]))
+}
+
+func (r *recordingLogger) Info(string, string, ...interface{}) {}
+
+func (r *recordingLogger) Warn(string, string, ...interface{}) {}
+
+func (r *recordingLogger) Error(string, string, ...interface{}) {}
+
+func TestEmbeddedCapabilityChain(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	chain := delegationChain(t, root, rootSigner, 2)
+	parent := chain[1]
+
+	// verify invokes a capability delegated from the last capability of the chain, with the given capabilityChain
+	verify := func(r zcapld.CapabilityResolver, capabilityChain ...interface{}) error {
+		capability := capability(t, parent.signer, ed25519signature2018.SignatureType,
+			withInvoker(keyID(testSigner(t, kms.ED25519))), withParent(parent.zcap.ID),
+			withVerMethod(keyID(parent.signer)), withCapabilityChain(capabilityChain))
+
+		return verifier(t, r, chainKeyResolver(t, rootSigner, chain)).Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         capability,
+				CapabilityAction:   "read",
+				VerificationMethod: capability.Invoker,
+			},
+			invocation(capability.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("success: chain of URIs", func(t *testing.T) {
+		require.NoError(t, verify(chainResolver(root, chain), root.ID, chain[0].zcap.ID, chain[1].zcap.ID))
+	})
+
+	t.Run("success: chain of embedded capabilities", func(t *testing.T) {
+		r := zcapld.SimpleCapabilityResolver{root.ID: root}
+
+		require.NoError(t, verify(r, embedded(t, root), embedded(t, chain[0].zcap), embedded(t, chain[1].zcap)))
+	})
+
+	t.Run("success: chain of URIs and embedded capabilities", func(t *testing.T) {
+		r := zcapld.SimpleCapabilityResolver{root.ID: root, chain[1].zcap.ID: chain[1].zcap}
+
+		require.NoError(t, verify(r, root.ID, embedded(t, chain[0].zcap), chain[1].zcap.ID))
+	})
+
+	t.Run("error: embedded root capability is not resolved", func(t *testing.T) {
+		err := verify(zcapld.SimpleCapabilityResolver{},
+			embedded(t, root), embedded(t, chain[0].zcap), embedded(t, chain[1].zcap))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+	})
+
+	t.Run("error: embedded root capability differs from the resolved one", func(t *testing.T) {
+		forged := embedded(t, root)
+		forged["invoker"] = keyID(testSigner(t, kms.ED25519))
+
+		err := verify(chainResolver(root, chain), forged, chain[0].zcap.ID, chain[1].zcap.ID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match the resolved root capability")
+	})
+
+	t.Run("error: embedded capability does not match the chain", func(t *testing.T) {
+		err := verify(chainResolver(root, chain), root.ID, embedded(t, chain[1].zcap))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parent capability does not match the previous capability in the chain")
+	})
+}
+
+// embedded returns the capability in the form it is embedded in a capabilityChain.
+func embedded(t testing.TB, c *zcapld.Capability) map[string]interface{} {
+	t.Helper()
+
+	m := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(marshal(t, c), &m))
+
+	return m
+}
+
+func TestVerificationMethodEqual(t *testing.T) {
+	vm := func(id string) *zcapld.VerificationMethod {
+		return &zcapld.VerificationMethod{ID: id}
+	}
+
+	require.True(t, zcapld.VerificationMethodEqual(vm("did:example:123#key1"), vm("did:example:123#key1")))
+	require.True(t, zcapld.VerificationMethodEqual(vm("did:example:123%3Aabc#key1"), vm("did:example:123:abc#key1")))
+	require.True(t, zcapld.VerificationMethodEqual(vm("DID:example:123#key1"), vm("did:example:123#key1")))
+	require.True(t, zcapld.VerificationMethodEqual(
+		vm("HTTPS://Example.COM/keys/a%20b?x=%31#key1"), vm("https://example.com/keys/a b?x=1#key1")))
+	require.True(t, zcapld.VerificationMethodEqual(nil, nil))
+
+	require.False(t, zcapld.VerificationMethodEqual(vm("did:example:123"), vm("did:example:123#key1")))
+	require.False(t, zcapld.VerificationMethodEqual(vm("did:example:123#key1"), vm("did:example:123#KEY1")))
+	require.False(t, zcapld.VerificationMethodEqual(vm("https://example.com/Keys"), vm("https://example.com/keys")))
+	require.False(t, zcapld.VerificationMethodEqual(vm("did:example:123"), nil))
+}
+
+func TestVerifier_Clone(t *testing.T) {
+	root, rootSigner := selfSignedSelfInvokingRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	base := verifier(t,
+		zcapld.SimpleCapabilityResolver{root.ID: root},
+		zcapld.SimpleKeyResolver{keyID(rootSigner): keyValue(t, rootSigner)},
+		zcapld.WithAllowedIDSchemes("urn"),
+	)
+	verify := func(v *zcapld.Verifier) error {
+		return v.Verify(
+			context.Background(),
+			&zcapld.Proof{
+				Capability:         root,
+				CapabilityAction:   "read",
+				VerificationMethod: root.Invoker,
+			},
+			invocation(root.Invoker, expectRootCapability(root.ID)),
+		)
+	}
+
+	t.Run("success: options are overlaid on the copy", func(t *testing.T) {
+		clone, err := base.Clone(zcapld.WithAllowedIDSchemes("did"))
+		require.NoError(t, err)
+		require.NoError(t, verify(clone))
+
+		err = verify(base)
+		require.True(t, errors.Is(err, zcapld.ErrInvalidCapabilityID))
+	})
+
+	t.Run("success: copy without options", func(t *testing.T) {
+		clone, err := base.Clone()
+		require.NoError(t, err)
+
+		err = verify(clone)
+		require.True(t, errors.Is(err, zcapld.ErrInvalidCapabilityID))
+	})
+
+	t.Run("success: new signature suites", func(t *testing.T) {
+		clone, err := base.Clone(zcapld.WithSignatureSuites(suites()...), zcapld.WithAllowedIDSchemes("did"))
+		require.NoError(t, err)
+		require.NoError(t, verify(clone))
+	})
+}
@@ -7,29 +7,47 @@ SPDX-License-Identifier: Apache-2.0
 package zcapld
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld/zcapcontext"
 )
 
 const (
 	// SecurityContextV2 is the JSON-LD context used in ZCAP-LD documents.
-	SecurityContextV2 = "https://w3id.org/security/v2"
+	SecurityContextV2 = zcapcontext.SecurityV2ContextURI
 	// ProofPurpose is the proofPurpose set on proofs in ZCAP-LD documents.
 	ProofPurpose = "capabilityDelegation"
+	// AllowedActionWildcard in a root capability's allowedAction grants all actions. It should only be used in
+	// root capabilities issued to fully-trusted delegates: it is not honoured in delegated capabilities.
+	AllowedActionWildcard = "*"
 
-	proofPurposeField         = "proofPurpose"
-	proofCapabilityChainField = "capabilityChain"
+	proofPurposeField            = "proofPurpose"
+	proofCapabilityChainField    = "capabilityChain"
+	proofVerificationMethodField = "verificationMethod"
 )
 
 // CapabilityInvocation describes the parameters for invocation of a capability.
 type CapabilityInvocation struct {
 	ExpectedTarget         string
+	ExpectedTargetType     string // optional, checked against the root capability's InvocationTarget.Type
 	ExpectedAction         string
 	ExpectedRootCapability string
+	ExpectedAudience       string
+	Purpose                string              // expected proof purpose, defaults to "capabilityInvocation"
 	VerificationMethod     *VerificationMethod // loaded from the http sig's keyId
+	CallerIP               net.IP              // optional, checked against CIDRCaveats
+	TraceContext           TraceContext        // optional, passed to the resolvers of the capability chain
+	AdditionalTargets      []string            // optional, other targets invoked at once, eg. a second document
 }
 
 // VerificationMethod to use to verify an invocation.
@@ -47,7 +65,10 @@ type Capability struct {
 	Delegator        string             `json:"delegator,omitempty"`
 	Parent           string             `json:"parentCapability,omitempty"`
 	AllowedAction    []string           `json:"allowedAction,omitempty"`
+	Audience         []string           `json:"audience,omitempty"`
 	InvocationTarget InvocationTarget   `json:"invocationTarget"`
+	ExpiresAt        string             `json:"expires,omitempty"`
+	Caveats          []json.RawMessage  `json:"caveat,omitempty"`
 	Proof            []verifiable.Proof `json:"proof,omitempty"`
 }
 
@@ -57,6 +78,215 @@ type InvocationTarget struct {
 	Type string
 }
 
+// IsRoot reports whether this is a root capability, ie. its capability chain is empty.
+func (c *Capability) IsRoot() bool {
+	// the capability chain is only read from the delegation proof of capabilities with a parent
+	return c.Parent == ""
+}
+
+// Depth returns the delegation depth of this capability, ie. the length of its capability chain.
+// Root capabilities have a depth of 0.
+func (c *Capability) Depth() (int, error) {
+	err := c.validateCapabilityChain()
+	if err != nil {
+		return 0, fmt.Errorf("invalid capability chain: %w", err)
+	}
+
+	chain, err := c.capabilityChain()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get capabilityChain: %w", err)
+	}
+
+	return len(chain), nil
+}
+
+// ParentCapabilityID returns the ID of the immediate parent of this capability: the last entry of the capability
+// chain of its delegation proof. The bool reports whether the entry is a URI rather than an embedded capability.
+// Root capabilities have no parent; their ID is empty. The capability chain is not verified.
+func (c *Capability) ParentCapabilityID() (string, bool, error) {
+	if c.IsRoot() {
+		return "", false, nil
+	}
+
+	for i := range c.Proof {
+		if c.Proof[i][proofPurposeField] != ProofPurpose {
+			continue
+		}
+
+		chain, err := proofCapabilityChain(c.Proof[i])
+		if err != nil {
+			return "", false, fmt.Errorf("failed to fetch capability chain: %w", err)
+		}
+
+		if len(chain) == 0 {
+			return "", false, fmt.Errorf("empty capability chain in delegation proof of capability %s", c.ID)
+		}
+
+		switch parent := chain[len(chain)-1].(type) {
+		case string:
+			return parent, true, nil
+		case map[string]interface{}:
+			id, ok := parent["id"].(string)
+			if !ok || id == "" {
+				return "", false, fmt.Errorf("embedded parent capability has no ID: %+v", parent)
+			}
+
+			return id, false, nil
+		default:
+			return "", false, fmt.Errorf("invalid capability chain entry format: %+v", parent)
+		}
+	}
+
+	return "", false, fmt.Errorf("no delegatable proofs found in capability %s", c.ID)
+}
+
+// ResolvedChain returns the capabilities of this capability's chain ordered from the root capability to this
+// capability, which is last. URIs in the chain are resolved with the resolver and embedded capabilities are decoded.
+// Neither the capabilities nor the chain are verified.
+func (c *Capability) ResolvedChain(ctx context.Context, resolver CapabilityResolver) ([]*Capability, error) {
+	chain, err := c.capabilityChain()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capabilityChain: %w", err)
+	}
+
+	resolved := make([]*Capability, 0, len(chain)+1)
+
+	for i := range chain {
+		var link *Capability
+
+		switch v := chain[i].(type) {
+		case string:
+			link, err = resolver.Resolve(ctx, v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve capability URI %s: %w", v, err)
+			}
+		case map[string]interface{}:
+			link, err = embeddedCapability(v)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid capability chain entry format: %+v", v)
+		}
+
+		resolved = append(resolved, link)
+	}
+
+	return append(resolved, c), nil
+}
+
+// ValidateSemantics checks the capability is fit to be issued: its ID is an absolute URI, it has an invocation
+// target, its allowed actions have no duplicates, its expiry (if any) is in the future, and each of its caveats
+// has a type. The proofs are not verified.
+func (c *Capability) ValidateSemantics() error {
+	u, err := url.Parse(c.ID)
+	if err != nil || !u.IsAbs() {
+		return fmt.Errorf("capability ID is not an absolute URI: %s", c.ID)
+	}
+
+	if c.InvocationTarget.ID == "" {
+		return fmt.Errorf("capability %s has no invocation target", c.ID)
+	}
+
+	actions := make(map[string]struct{}, len(c.AllowedAction))
+
+	for _, action := range c.AllowedAction {
+		if _, ok := actions[action]; ok {
+			return fmt.Errorf(`duplicate allowed action "%s" in capability %s`, action, c.ID)
+		}
+
+		actions[action] = struct{}{}
+	}
+
+	if c.ExpiresAt != "" {
+		expired, err := c.expired(time.Now())
+		if err != nil {
+			return err
+		}
+
+		if expired {
+			return fmt.Errorf("%w: capability %s expired at %s", ErrCapabilityExpired, c.ID, c.ExpiresAt)
+		}
+	}
+
+	for i := range c.Caveats {
+		discriminator := &struct {
+			Type string `json:"type"`
+		}{}
+
+		err = json.Unmarshal(c.Caveats[i], discriminator)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal caveat on capability %s: %w", c.ID, err)
+		}
+
+		if discriminator.Type == "" {
+			return fmt.Errorf("caveat without a type in capability %s: %s", c.ID, c.Caveats[i])
+		}
+	}
+
+	return nil
+}
+
+// NormalizeActions returns a copy of the capability with its allowed actions lowercased, the canonical form of
+// actions compared case-insensitively. Its proofs do not cover the normalized actions, so the copy is not to be
+// verified in place of the original.
+func NormalizeActions(c *Capability) *Capability {
+	normalized := Clone(c)
+
+	for i := range normalized.AllowedAction {
+		normalized.AllowedAction[i] = strings.ToLower(normalized.AllowedAction[i])
+	}
+
+	return normalized
+}
+
+// expired reports whether this capability's expiry (if any) is before 'now'.
+func (c *Capability) expired(now time.Time) (bool, error) {
+	if c.ExpiresAt == "" {
+		return false, nil
+	}
+
+	expires, err := time.Parse(time.RFC3339, c.ExpiresAt)
+	if err != nil {
+		return false, fmt.Errorf("invalid expiry format on capability %s: %w", c.ID, err)
+	}
+
+	return now.After(expires), nil
+}
+
+// HasCaveat reports whether the capability has a caveat of the type. It is false for a nil capability.
+func (c *Capability) HasCaveat(caveatType string) bool {
+	_, ok := c.GetCaveat(caveatType)
+
+	return ok
+}
+
+// GetCaveat returns the JSON of the first caveat of the type on the capability, if any. Types are compared
+// exactly, and caveats that are not JSON objects are skipped.
+func (c *Capability) GetCaveat(caveatType string) (json.RawMessage, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	for i := range c.Caveats {
+		discriminator := &struct {
+			Type string `json:"type"`
+		}{}
+
+		if json.Unmarshal(c.Caveats[i], discriminator) == nil && discriminator.Type == caveatType {
+			return c.Caveats[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// Invokers returns the entities authorized to invoke this capability: its invoker, or else its controller, or else
+// its ID. A capability with a delegator but no invoker cannot be invoked; it has no invokers.
+func (c *Capability) Invokers() ([]string, error) {
+	return c.invokers()
+}
+
 // invokers are this capability's entities authorized to invoke the invocation target.
 func (c *Capability) invokers() ([]string, error) {
 	// if neither an invoker, controller, nor id is found on the capability then
@@ -86,9 +316,39 @@ func (c *Capability) invokers() ([]string, error) {
 	return []string{invoker}, nil
 }
 
-// validateCapabilityChain validates the capability chain list, ensuring, for instance, it contains only
-// IDs except possibly last entry (which can be a full embedded capability),
-// that all IDs are all absolute URLs, and that it contains no cycles.
+// delegators are this capability's entities authorized to delegate it further.
+func (c *Capability) delegators() []string {
+	// TODO revisit datatypes of delegator and controller. ocapld.js accounts for any of them to be arrays.
+	for _, delegator := range []string{c.Delegator, c.Controller, c.Invoker} {
+		if delegator != "" {
+			return []string{delegator}
+		}
+	}
+
+	return []string{c.ID}
+}
+
+// delegationVerificationMethod is the verification method of the proof delegating this capability.
+func (c *Capability) delegationVerificationMethod() (string, error) {
+	proofs, err := c.delegationProofs()
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch delegationProofs: %w", err)
+	}
+
+	if len(proofs) == 0 {
+		return "", fmt.Errorf("no delegatable proofs found in capability %s", c.ID)
+	}
+
+	verificationMethod, ok := proofs[0][proofVerificationMethodField].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid verificationMethod in delegatable proof: %+v", proofs[0])
+	}
+
+	return verificationMethod, nil
+}
+
+// validateCapabilityChain validates the capability chain list, ensuring, for instance, that every entry is either
+// an ID or a full embedded capability with an ID, and that it contains no cycles.
 // https://github.com/digitalbazaar/ocapld.js/blob/8a54398162837b1cf52c82978bc8127e52d02974/lib/utils.js#L299
 func (c *Capability) validateCapabilityChain() error {
 	chain, err := c.capabilityChain()
@@ -96,28 +356,26 @@ func (c *Capability) validateCapabilityChain() error {
 		return fmt.Errorf("failed to get capabilityChain: %w", err)
 	}
 
-	// TODO no logic for this, no test cases either
 	if len(chain) == 0 {
 		return nil
 	}
 
-	uniqueLinks := make(map[string]*struct{})
+	// the capability itself must not appear in its chain either
+	visited := map[string]bool{c.ID: true}
 
 	for i := range chain {
 		link := chain[i]
 
-		id, ok := link.(string)
-		if !ok {
-			return errors.New("embedded capabilities in capabilityChain not supported yet")
+		id := parentID(link)
+		if id == "" {
+			return fmt.Errorf("invalid capability chain entry format: %+v", link)
 		}
 
-		uniqueLinks[id] = nil
-	}
-
-	uniqueLinks[c.ID] = nil
+		if visited[id] {
+			return fmt.Errorf("%w: capability %s appears more than once", ErrChainCycle, id)
+		}
 
-	if len(uniqueLinks) != len(chain)+1 {
-		return errors.New("the capability chain contains a cycle")
+		visited[id] = true
 	}
 
 	return nil
@@ -188,8 +446,7 @@ func (c *Capability) delegationProofs() ([]verifiable.Proof, error) {
 
 		last := chain[len(chain)-1]
 
-		uri, ok := last.(string)
-		if ok && uri == c.Parent {
+		if parentID(last) == c.Parent {
 			proofs = append(proofs, p)
 
 			continue
@@ -203,6 +460,38 @@ func (c *Capability) delegationProofs() ([]verifiable.Proof, error) {
 	return proofs, nil
 }
 
+// parentID is the ID of a capability chain entry: either a URI or an embedded capability.
+func parentID(entry interface{}) string {
+	switch v := entry.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		// an embedded capability without an ID matches no parent
+		id, _ := v["id"].(string)
+
+		return id
+	default:
+		return ""
+	}
+}
+
+// embeddedCapability decodes a capability embedded in a capability chain.
+func embeddedCapability(embedded map[string]interface{}) (*Capability, error) {
+	raw, err := json.Marshal(embedded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embedded capability: %w", err)
+	}
+
+	zcap := &Capability{}
+
+	err = json.Unmarshal(raw, zcap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal embedded capability: %w", err)
+	}
+
+	return zcap, nil
+}
+
 func proofCapabilityChain(proof verifiable.Proof) ([]interface{}, error) {
 	// formal definition of `capabilityChain` is missing, see https://github.com/w3c-ccg/security-vocab/issues/28.
 	// going with examples here for now:
@@ -0,0 +1,328 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapld_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld"
+)
+
+func TestCapability_IsRoot(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	chain := delegationChain(t, root, rootSigner, 1)
+
+	require.True(t, root.IsRoot())
+	require.False(t, chain[0].zcap.IsRoot())
+}
+
+func TestCapability_Depth(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 3)
+
+		depth, err := root.Depth()
+		require.NoError(t, err)
+		require.Zero(t, depth)
+
+		for i := range chain {
+			depth, err = chain[i].zcap.Depth()
+			require.NoError(t, err)
+			require.Equal(t, i+1, depth)
+		}
+	})
+
+	t.Run("error: delegated capability without a delegation proof", func(t *testing.T) {
+		_, err := (&zcapld.Capability{ID: "urn:zcap:child", Parent: "urn:zcap:root"}).Depth()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid capability chain")
+	})
+}
+
+func TestCapability_ParentCapabilityID(t *testing.T) {
+	delegated := func(chain interface{}) *zcapld.Capability {
+		return &zcapld.Capability{
+			ID:     "urn:zcap:child",
+			Parent: "urn:zcap:parent",
+			Proof: []verifiable.Proof{{
+				"proofPurpose":    zcapld.ProofPurpose,
+				"capabilityChain": chain,
+			}},
+		}
+	}
+
+	t.Run("success: root capability has no parent", func(t *testing.T) {
+		root, _ := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+
+		id, isURI, err := root.ParentCapabilityID()
+		require.NoError(t, err)
+		require.Empty(t, id)
+		require.False(t, isURI)
+	})
+
+	t.Run("success: parent URI", func(t *testing.T) {
+		root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+		chain := delegationChain(t, root, rootSigner, 2)
+
+		id, isURI, err := chain[1].zcap.ParentCapabilityID()
+		require.NoError(t, err)
+		require.Equal(t, chain[0].zcap.ID, id)
+		require.True(t, isURI)
+	})
+
+	t.Run("success: embedded parent", func(t *testing.T) {
+		id, isURI, err := delegated([]interface{}{
+			"urn:zcap:root",
+			map[string]interface{}{"id": "urn:zcap:parent"},
+		}).ParentCapabilityID()
+		require.NoError(t, err)
+		require.Equal(t, "urn:zcap:parent", id)
+		require.False(t, isURI)
+	})
+
+	t.Run("error: malformed chain data", func(t *testing.T) {
+		tests := map[string]interface{}{
+			"invalid proof capabilityChain format": "urn:zcap:root",
+			"empty capability chain":               []interface{}{},
+			"embedded parent capability has no ID": []interface{}{map[string]interface{}{}},
+			"invalid capability chain entry":       []interface{}{1},
+		}
+
+		for expected, chain := range tests {
+			_, _, err := delegated(chain).ParentCapabilityID()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), expected)
+		}
+	})
+
+	t.Run("error: no delegation proof", func(t *testing.T) {
+		_, _, err := (&zcapld.Capability{ID: "urn:zcap:child", Parent: "urn:zcap:parent"}).ParentCapabilityID()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no delegatable proofs found in capability urn:zcap:child")
+	})
+}
+
+func TestCapability_GetCaveat(t *testing.T) {
+	first := json.RawMessage(`{"type":"zcap:ExpiresCaveat","expires":"2020-10-07T21:59:06Z"}`)
+	second := json.RawMessage(`{"type":"zcap:ExpiresCaveat","expires":"2021-10-07T21:59:06Z"}`)
+	c := &zcapld.Capability{Caveats: []json.RawMessage{json.RawMessage(`[]`), first, second}}
+
+	caveat, ok := c.GetCaveat("zcap:ExpiresCaveat")
+	require.True(t, ok)
+	require.Equal(t, first, caveat)
+	require.True(t, c.HasCaveat("zcap:ExpiresCaveat"))
+
+	caveat, ok = c.GetCaveat(zcapld.CaveatTypeMaxInvocations)
+	require.False(t, ok)
+	require.Nil(t, caveat)
+	require.False(t, c.HasCaveat(zcapld.CaveatTypeMaxInvocations))
+
+	var nilCapability *zcapld.Capability
+
+	caveat, ok = nilCapability.GetCaveat("zcap:ExpiresCaveat")
+	require.False(t, ok)
+	require.Nil(t, caveat)
+	require.False(t, nilCapability.HasCaveat("zcap:ExpiresCaveat"))
+}
+
+func TestCapability_Invokers(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		capability *zcapld.Capability
+		expected   []string
+	}{
+		{
+			name:       "invoker",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Invoker: "did:example:invoker", Controller: "did:example:c"},
+			expected:   []string{"did:example:invoker"},
+		},
+		{
+			name:       "controller",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Controller: "did:example:controller"},
+			expected:   []string{"did:example:controller"},
+		},
+		{
+			name:       "id",
+			capability: &zcapld.Capability{ID: "urn:zcap:1"},
+			expected:   []string{"urn:zcap:1"},
+		},
+		{
+			name:       "delegator without invoker",
+			capability: &zcapld.Capability{ID: "urn:zcap:1", Delegator: "did:example:delegator"},
+			expected:   []string{},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			invokers, err := tc.capability.Invokers()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, invokers)
+		})
+	}
+
+	t.Run("error: no invoker", func(t *testing.T) {
+		_, err := (&zcapld.Capability{}).Invokers()
+		require.EqualError(t, err, "invoker not found for capability")
+	})
+}
+
+func TestNormalizeActions(t *testing.T) {
+	original := &zcapld.Capability{ID: "urn:zcap:1", AllowedAction: []string{"Read", "WRITE", "delete"}}
+
+	normalized := zcapld.NormalizeActions(original)
+	require.Equal(t, []string{"read", "write", "delete"}, normalized.AllowedAction)
+	require.Equal(t, []string{"Read", "WRITE", "delete"}, original.AllowedAction)
+	require.Equal(t, original.ID, normalized.ID)
+}
+
+func TestCapability_ResolvedChain(t *testing.T) {
+	root, rootSigner := selfSignedRootCapability(t, kms.ED25519, ed25519signature2018.SignatureType)
+	chain := delegationChain(t, root, rootSigner, 2)
+
+	t.Run("success: resolves URIs", func(t *testing.T) {
+		resolved, err := chain[1].zcap.ResolvedChain(context.Background(), chainResolver(root, chain))
+		require.NoError(t, err)
+		require.Equal(t, []*zcapld.Capability{root, chain[0].zcap, chain[1].zcap}, resolved)
+	})
+
+	t.Run("success: root capability", func(t *testing.T) {
+		resolved, err := root.ResolvedChain(context.Background(), zcapld.SimpleCapabilityResolver{})
+		require.NoError(t, err)
+		require.Equal(t, []*zcapld.Capability{root}, resolved)
+	})
+
+	t.Run("success: decodes embedded capabilities", func(t *testing.T) {
+		embedded := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(marshal(t, chain[0].zcap), &embedded))
+
+		zcap := &zcapld.Capability{
+			ID:     "urn:zcap:child",
+			Parent: chain[0].zcap.ID,
+			Proof: []verifiable.Proof{{
+				"proofPurpose":    zcapld.ProofPurpose,
+				"capabilityChain": []interface{}{root.ID, embedded},
+			}},
+		}
+
+		resolved, err := zcap.ResolvedChain(context.Background(), zcapld.SimpleCapabilityResolver{root.ID: root})
+		require.NoError(t, err)
+		require.Len(t, resolved, 3)
+		require.Equal(t, root, resolved[0])
+		require.True(t, zcapld.Equal(chain[0].zcap, resolved[1]))
+		require.Equal(t, zcap, resolved[2])
+	})
+
+	t.Run("error: cannot resolve capability", func(t *testing.T) {
+		_, err := chain[1].zcap.ResolvedChain(context.Background(), zcapld.SimpleCapabilityResolver{root.ID: root})
+		require.Error(t, err)
+		require.True(t, errors.Is(err, zcapld.ErrCapabilityNotFound))
+		require.Contains(t, err.Error(), "failed to resolve capability URI "+chain[0].zcap.ID)
+	})
+
+	t.Run("error: invalid chain entry", func(t *testing.T) {
+		zcap := &zcapld.Capability{
+			ID:     "urn:zcap:child",
+			Parent: "urn:zcap:parent",
+			Proof: []verifiable.Proof{{
+				"proofPurpose":    zcapld.ProofPurpose,
+				"capabilityChain": []interface{}{1, "urn:zcap:parent"},
+			}},
+		}
+
+		_, err := zcap.ResolvedChain(context.Background(), zcapld.SimpleCapabilityResolver{})
+		require.EqualError(t, err, "invalid capability chain entry format: 1")
+	})
+}
+
+func TestCapability_ValidateSemantics(t *testing.T) {
+	valid := func() *zcapld.Capability {
+		return &zcapld.Capability{
+			ID:               "urn:zcap:1",
+			InvocationTarget: zcapld.InvocationTarget{ID: "urn:target"},
+			AllowedAction:    []string{"read", "write"},
+			ExpiresAt:        time.Now().Add(time.Hour).Format(time.RFC3339),
+			Caveats:          []json.RawMessage{json.RawMessage(`{"type":"urn:test:caveat"}`)},
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		require.NoError(t, valid().ValidateSemantics())
+		require.NoError(t, (&zcapld.Capability{
+			ID:               "https://example.com/zcaps/1",
+			InvocationTarget: zcapld.InvocationTarget{ID: "urn:target"},
+		}).ValidateSemantics())
+	})
+
+	tests := []struct {
+		name   string
+		modify func(c *zcapld.Capability)
+		err    string
+	}{
+		{
+			name:   "relative ID",
+			modify: func(c *zcapld.Capability) { c.ID = "zcaps/1" },
+			err:    "capability ID is not an absolute URI: zcaps/1",
+		},
+		{
+			name:   "missing ID",
+			modify: func(c *zcapld.Capability) { c.ID = "" },
+			err:    "capability ID is not an absolute URI: ",
+		},
+		{
+			name:   "missing invocation target",
+			modify: func(c *zcapld.Capability) { c.InvocationTarget.ID = "" },
+			err:    "capability urn:zcap:1 has no invocation target",
+		},
+		{
+			name:   "duplicate action",
+			modify: func(c *zcapld.Capability) { c.AllowedAction = []string{"read", "write", "read"} },
+			err:    `duplicate allowed action "read" in capability urn:zcap:1`,
+		},
+		{
+			name:   "expired",
+			modify: func(c *zcapld.Capability) { c.ExpiresAt = "2020-01-01T00:00:00Z" },
+			err:    "capability expired: capability urn:zcap:1 expired at 2020-01-01T00:00:00Z",
+		},
+		{
+			name:   "invalid expiry",
+			modify: func(c *zcapld.Capability) { c.ExpiresAt = "tomorrow" },
+			err:    "invalid expiry format on capability urn:zcap:1",
+		},
+		{
+			name:   "caveat without type",
+			modify: func(c *zcapld.Capability) { c.Caveats = []json.RawMessage{json.RawMessage(`{"expires":"x"}`)} },
+			err:    `caveat without a type in capability urn:zcap:1: {"expires":"x"}`,
+		},
+		{
+			name:   "invalid caveat",
+			modify: func(c *zcapld.Capability) { c.Caveats = []json.RawMessage{json.RawMessage(`[]`)} },
+			err:    "failed to unmarshal caveat on capability urn:zcap:1",
+		},
+	}
+
+	for i := range tests {
+		test := tests[i]
+
+		t.Run("error: "+test.name, func(t *testing.T) {
+			c := valid()
+			test.modify(c)
+
+			err := c.ValidateSemantics()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.err)
+		})
+	}
+}
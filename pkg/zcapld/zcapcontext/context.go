@@ -0,0 +1,68 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package zcapcontext manages the JSON-LD @context URIs of ZCAP-LD documents.
+package zcapcontext
+
+const (
+	// SecurityV2ContextURI is the URI of the security v2 JSON-LD context, in which capabilities are serialized.
+	SecurityV2ContextURI = "https://w3id.org/security/v2"
+	// ZcapLDV1ContextURI is the URI of the ZCAP-LD v1 JSON-LD context.
+	ZcapLDV1ContextURI = "https://w3id.org/zcap/v1"
+	// SecurityVocabURI is the base IRI of the terms of the security vocabulary.
+	SecurityVocabURI = "https://w3id.org/security#"
+)
+
+const contextKey = "@context"
+
+// AddContext adds the context URI to the @context of the JSON-LD document, unless it is already there.
+// A document without a @context gets the URI as its only context; otherwise the @context becomes an array of the
+// existing contexts followed by the URI.
+func AddContext(doc map[string]interface{}, uri string) {
+	if HasContext(doc, uri) {
+		return
+	}
+
+	switch contexts := doc[contextKey].(type) {
+	case nil:
+		doc[contextKey] = uri
+	case []interface{}:
+		doc[contextKey] = append(contexts, uri)
+	case []string:
+		merged := make([]interface{}, 0, len(contexts)+1)
+
+		for _, c := range contexts {
+			merged = append(merged, c)
+		}
+
+		doc[contextKey] = append(merged, uri)
+	default:
+		doc[contextKey] = []interface{}{contexts, uri}
+	}
+}
+
+// HasContext reports whether the context URI is in the @context of the JSON-LD document, either as the @context or
+// as one of the contexts of an array.
+func HasContext(doc map[string]interface{}, uri string) bool {
+	switch contexts := doc[contextKey].(type) {
+	case string:
+		return contexts == uri
+	case []interface{}:
+		for _, c := range contexts {
+			if c == uri {
+				return true
+			}
+		}
+	case []string:
+		for _, c := range contexts {
+			if c == uri {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,90 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package zcapcontext_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/edge-core/pkg/zcapld/zcapcontext"
+)
+
+func TestAddContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		context  interface{}
+		expected interface{}
+	}{
+		{
+			name:     "no context",
+			expected: zcapcontext.ZcapLDV1ContextURI,
+		},
+		{
+			name:     "same context",
+			context:  zcapcontext.ZcapLDV1ContextURI,
+			expected: zcapcontext.ZcapLDV1ContextURI,
+		},
+		{
+			name:     "other context",
+			context:  zcapcontext.SecurityV2ContextURI,
+			expected: []interface{}{zcapcontext.SecurityV2ContextURI, zcapcontext.ZcapLDV1ContextURI},
+		},
+		{
+			name:     "array of contexts",
+			context:  []interface{}{zcapcontext.SecurityV2ContextURI},
+			expected: []interface{}{zcapcontext.SecurityV2ContextURI, zcapcontext.ZcapLDV1ContextURI},
+		},
+		{
+			name:     "array of contexts with the context",
+			context:  []interface{}{zcapcontext.ZcapLDV1ContextURI, zcapcontext.SecurityV2ContextURI},
+			expected: []interface{}{zcapcontext.ZcapLDV1ContextURI, zcapcontext.SecurityV2ContextURI},
+		},
+		{
+			name:     "string array of contexts",
+			context:  []string{zcapcontext.SecurityV2ContextURI},
+			expected: []interface{}{zcapcontext.SecurityV2ContextURI, zcapcontext.ZcapLDV1ContextURI},
+		},
+		{
+			name:    "embedded context",
+			context: map[string]interface{}{"ex": "https://example.com#"},
+			expected: []interface{}{
+				map[string]interface{}{"ex": "https://example.com#"},
+				zcapcontext.ZcapLDV1ContextURI,
+			},
+		},
+	}
+
+	for i := range tests {
+		tc := tests[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			doc := map[string]interface{}{"id": "urn:zcap:123"}
+			if tc.context != nil {
+				doc["@context"] = tc.context
+			}
+
+			zcapcontext.AddContext(doc, zcapcontext.ZcapLDV1ContextURI)
+			require.Equal(t, tc.expected, doc["@context"])
+			require.True(t, zcapcontext.HasContext(doc, zcapcontext.ZcapLDV1ContextURI))
+		})
+	}
+}
+
+func TestHasContext(t *testing.T) {
+	require.True(t, zcapcontext.HasContext(
+		map[string]interface{}{"@context": zcapcontext.SecurityV2ContextURI}, zcapcontext.SecurityV2ContextURI))
+	require.True(t, zcapcontext.HasContext(
+		map[string]interface{}{"@context": []string{zcapcontext.SecurityV2ContextURI}},
+		zcapcontext.SecurityV2ContextURI))
+	require.False(t, zcapcontext.HasContext(map[string]interface{}{}, zcapcontext.SecurityV2ContextURI))
+	require.False(t, zcapcontext.HasContext(
+		map[string]interface{}{"@context": []interface{}{zcapcontext.ZcapLDV1ContextURI}},
+		zcapcontext.SecurityV2ContextURI))
+	require.False(t, zcapcontext.HasContext(
+		map[string]interface{}{"@context": map[string]interface{}{}}, zcapcontext.SecurityV2ContextURI))
+}
@@ -0,0 +1,23 @@
+#!/bin/bash
+#
+# Copyright SecureKey Technologies Inc. All Rights Reserved.
+#
+# SPDX-License-Identifier: Apache-2.0
+#
+set -e
+
+echo "Running $0"
+
+# Each benchmark runs once: this checks that the benchmarks still work and report their allocations, not their speed.
+out=$(go test ./pkg/zcapld/bench -run='^$' -bench=. -benchmem -benchtime=1x -count=1)
+echo "$out"
+
+if ! echo "$out" | grep -q '^Benchmark'; then
+  echo "error: no benchmarks were run"
+  exit 1
+fi
+
+if echo "$out" | grep '^Benchmark' | grep -qv 'allocs/op'; then
+  echo "error: benchmarks are missing -benchmem output"
+  exit 1
+fi